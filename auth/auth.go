@@ -0,0 +1,143 @@
+// This file contains the authentication of the API callers. A caller is
+// either a service holding an API key or a user holding a JWT signed by the
+// identity provider, both belong to an organisation which owns estates.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const HeaderAPIKey = "X-API-Key"
+
+var ErrUnauthenticated = errors.New("missing or invalid credentials")
+
+// Principal is the authenticated caller
+type Principal struct {
+	// Subject is the user of a JWT or the name of an API key
+	Subject        string
+	OrganisationID string
+}
+
+type principalKey struct{}
+
+func NewContext(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+func FromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// OrganisationID is the organisation of the caller, empty when the
+// authentication is disabled so every estate belongs to the same empty
+// organisation.
+func OrganisationID(ctx context.Context) string {
+	if principal, ok := FromContext(ctx); ok {
+		return principal.OrganisationID
+	}
+
+	return ""
+}
+
+type Authenticator struct {
+	// apiKeys is indexed by the SHA-256 of the keys, so the keys themselves
+	// are not kept in memory after startup
+	apiKeys   map[[sha256.Size]byte]Principal
+	jwtSecret []byte
+	jwtIssuer string
+	now       func() time.Time
+}
+
+type APIKey struct {
+	Key            string
+	Name           string
+	OrganisationID string
+}
+
+type NewAuthenticatorOptions struct {
+	APIKeys []APIKey
+	// JWTSecret is the HS256 key of the JWTs, the JWTs are rejected when empty
+	JWTSecret []byte
+	// JWTIssuer is the expected iss claim of the JWTs, not checked when empty
+	JWTIssuer string
+}
+
+func NewAuthenticator(opts NewAuthenticatorOptions) *Authenticator {
+	apiKeys := make(map[[sha256.Size]byte]Principal, len(opts.APIKeys))
+	for _, apiKey := range opts.APIKeys {
+		apiKeys[sha256.Sum256([]byte(apiKey.Key))] = Principal{
+			Subject:        apiKey.Name,
+			OrganisationID: apiKey.OrganisationID,
+		}
+	}
+
+	return &Authenticator{
+		apiKeys:   apiKeys,
+		jwtSecret: opts.JWTSecret,
+		jwtIssuer: opts.JWTIssuer,
+		now:       time.Now,
+	}
+}
+
+// Authenticate returns the caller of req, from the X-API-Key header or the
+// bearer token of the Authorization header.
+func (a *Authenticator) Authenticate(req *http.Request) (*Principal, error) {
+	if key := req.Header.Get(HeaderAPIKey); key != "" {
+		principal, ok := a.apiKeys[sha256.Sum256([]byte(key))]
+		if !ok {
+			return nil, ErrUnauthenticated
+		}
+
+		return &principal, nil
+	}
+
+	scheme, token, ok := strings.Cut(req.Header.Get(echo.HeaderAuthorization), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") && len(a.jwtSecret) > 0 {
+		claims, err := ParseToken(a.jwtSecret, a.jwtIssuer, token, a.now())
+		if err != nil {
+			return nil, ErrUnauthenticated
+		}
+
+		if claims.Subject == "" || claims.OrganisationID == "" {
+			return nil, ErrUnauthenticated
+		}
+
+		return &Principal{
+			Subject:        claims.Subject,
+			OrganisationID: claims.OrganisationID,
+		}, nil
+	}
+
+	return nil, ErrUnauthenticated
+}
+
+// Middleware rejects the requests without valid credentials and stores the
+// caller in the context of the others.
+func Middleware(a *Authenticator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			principal, err := a.Authenticate(ctx.Request())
+			if err != nil {
+				ctx.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer, ApiKey header="`+HeaderAPIKey+`"`)
+				return &echo.HTTPError{
+					Code:     http.StatusUnauthorized,
+					Message:  err.Error(),
+					Internal: err,
+				}
+			}
+
+			req := ctx.Request()
+			ctx.SetRequest(req.WithContext(NewContext(req.Context(), principal)))
+
+			return next(ctx)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSecret = []byte("test-secret")
+
+func newTestAuthenticator(now time.Time) *Authenticator {
+	authenticator := NewAuthenticator(NewAuthenticatorOptions{
+		APIKeys: []APIKey{
+			{Key: "key-a", Name: "importer", OrganisationID: "org-a"},
+		},
+		JWTSecret: testSecret,
+		JWTIssuer: "https://id.example.com",
+	})
+	authenticator.now = func() time.Time { return now }
+
+	return authenticator
+}
+
+func newBearerRequest(t *testing.T, secret []byte, claims Claims) *http.Request {
+	token, err := NewToken(secret, claims)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+
+	return req
+}
+
+func TestAuthenticate_APIKey(t *testing.T) {
+	authenticator := newTestAuthenticator(time.Now())
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(HeaderAPIKey, "key-a")
+
+	principal, err := authenticator.Authenticate(req)
+	require.NoError(t, err)
+	assert.Equal(t, &Principal{Subject: "importer", OrganisationID: "org-a"}, principal)
+
+	req.Header.Set(HeaderAPIKey, "key-b")
+	_, err = authenticator.Authenticate(req)
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+}
+
+func TestAuthenticate_JWT(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	authenticator := newTestAuthenticator(now)
+
+	claims := Claims{
+		Subject:        "user-1",
+		OrganisationID: "org-b",
+		Issuer:         "https://id.example.com",
+		ExpiresAt:      now.Add(time.Hour).Unix(),
+	}
+
+	principal, err := authenticator.Authenticate(newBearerRequest(t, testSecret, claims))
+	require.NoError(t, err)
+	assert.Equal(t, &Principal{Subject: "user-1", OrganisationID: "org-b"}, principal)
+
+	testcases := map[string]struct {
+		secret []byte
+		modify func(claims *Claims)
+	}{
+		"WrongSecret": {
+			secret: []byte("other-secret"),
+		},
+		"Expired": {
+			modify: func(claims *Claims) { claims.ExpiresAt = now.Unix() },
+		},
+		"WithoutExpiry": {
+			modify: func(claims *Claims) { claims.ExpiresAt = 0 },
+		},
+		"NotYetValid": {
+			modify: func(claims *Claims) { claims.NotBefore = now.Add(time.Minute).Unix() },
+		},
+		"WrongIssuer": {
+			modify: func(claims *Claims) { claims.Issuer = "https://evil.example.com" },
+		},
+		"WithoutOrganisation": {
+			modify: func(claims *Claims) { claims.OrganisationID = "" },
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			invalid := claims
+			if tc.modify != nil {
+				tc.modify(&invalid)
+			}
+
+			secret := testSecret
+			if tc.secret != nil {
+				secret = tc.secret
+			}
+
+			_, err := authenticator.Authenticate(newBearerRequest(t, secret, invalid))
+			assert.ErrorIs(t, err, ErrUnauthenticated)
+		})
+	}
+}
+
+func TestParseToken_RejectsOtherAlgorithms(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	token, err := NewToken(testSecret, Claims{Subject: "user-1", OrganisationID: "org-a", ExpiresAt: now.Add(time.Hour).Unix()})
+	require.NoError(t, err)
+
+	// Same payload announcing alg none and without signature
+	parts := strings.Split(token, ".")
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`)) + "." + parts[1] + "."
+
+	_, err = ParseToken(testSecret, "", none, now)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// Signed with the secret, by another HMAC
+	hs384, err := jwt.NewWithClaims(jwt.SigningMethodHS384, jwt.MapClaims{
+		"sub": "user-1",
+		"org": "org-a",
+		"exp": now.Add(time.Hour).Unix(),
+	}).SignedString(testSecret)
+	require.NoError(t, err)
+
+	_, err = ParseToken(testSecret, "", hs384, now)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = ParseToken(testSecret, "", "not-a-token", now)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestMiddleware(t *testing.T) {
+	e := echo.New()
+	e.Use(Middleware(newTestAuthenticator(time.Now())))
+	e.GET("/", func(ctx echo.Context) error {
+		return ctx.String(http.StatusOK, OrganisationID(ctx.Request().Context()))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(HeaderAPIKey, "key-a")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "org-a", rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get(echo.HeaderWWWAuthenticate))
+}
@@ -0,0 +1,94 @@
+// This file contains the cache used to avoid recomputing drone plans. The
+// values are stored as bytes so the in-memory LRU can be swapped for a cache
+// shared between instances without changing the callers.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+)
+
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte) error
+}
+
+// DronePlanKey identifies a drone plan. The estate version changes every time
+// a tree is saved, so a plan computed before the change is never read again
+// and ages out of the cache.
+func DronePlanKey(estateUUID string, estateVersion uint64, maxDistance *uint64) string {
+	if maxDistance == nil {
+		return fmt.Sprintf("drone-plan:%s:%d", estateUUID, estateVersion)
+	}
+
+	return fmt.Sprintf("drone-plan:%s:%d:%d", estateUUID, estateVersion, *maxDistance)
+}
+
+type entry struct {
+	key   string
+	value []byte
+}
+
+// LRU is a thread safe in-memory cache evicting the least recently used
+// entry once the capacity is reached.
+type LRU struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+func NewLRU(capacity int) *LRU {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &LRU{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *LRU) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	c.order.MoveToFront(element)
+	return element.Value.(*entry).value, true, nil
+}
+
+func (c *LRU) Set(ctx context.Context, key string, value []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		element.Value.(*entry).value = value
+		c.order.MoveToFront(element)
+		return nil
+	}
+
+	c.items[key] = c.order.PushFront(&entry{key: key, value: value})
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*entry).key)
+	}
+
+	return nil
+}
+
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+	lru := NewLRU(2)
+
+	assert.NoError(t, lru.Set(ctx, "a", []byte("1")))
+	assert.NoError(t, lru.Set(ctx, "b", []byte("2")))
+
+	value, ok, err := lru.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), value)
+
+	// b is now the least recently used entry
+	assert.NoError(t, lru.Set(ctx, "c", []byte("3")))
+	assert.Equal(t, 2, lru.Len())
+
+	_, ok, _ = lru.Get(ctx, "b")
+	assert.False(t, ok)
+
+	_, ok, _ = lru.Get(ctx, "a")
+	assert.True(t, ok)
+
+	assert.NoError(t, lru.Set(ctx, "a", []byte("4")))
+	value, _, _ = lru.Get(ctx, "a")
+	assert.Equal(t, []byte("4"), value)
+	assert.Equal(t, 2, lru.Len())
+}
+
+func TestDronePlanKey(t *testing.T) {
+	maxDistance := uint64(100)
+
+	assert.Equal(t, "drone-plan:uuid:1", DronePlanKey("uuid", 1, nil))
+	assert.Equal(t, "drone-plan:uuid:1:100", DronePlanKey("uuid", 1, &maxDistance))
+	assert.NotEqual(t, DronePlanKey("uuid", 1, nil), DronePlanKey("uuid", 2, nil))
+}
@@ -0,0 +1,2483 @@
+// Package client provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/oapi-codegen/oapi-codegen/v2 version v2.4.1 DO NOT EDIT.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/oapi-codegen/runtime"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+const (
+	ApiKeyAuthScopes = "ApiKeyAuth.Scopes"
+	BearerAuthScopes = "BearerAuth.Scopes"
+)
+
+// Defines values for DronePlanJobResponseStatus.
+const (
+	Cancelled DronePlanJobResponseStatus = "cancelled"
+	Failed    DronePlanJobResponseStatus = "failed"
+	Queued    DronePlanJobResponseStatus = "queued"
+	Running   DronePlanJobResponseStatus = "running"
+	Succeeded DronePlanJobResponseStatus = "succeeded"
+)
+
+// Defines values for ErrorResponseCode.
+const (
+	Conflict            ErrorResponseCode = "conflict"
+	DuplicateCoordinate ErrorResponseCode = "duplicate_coordinate"
+	EstateFull          ErrorResponseCode = "estate_full"
+	EstateNotFound      ErrorResponseCode = "estate_not_found"
+	InternalError       ErrorResponseCode = "internal_error"
+	JobNotFound         ErrorResponseCode = "job_not_found"
+	JobQueueFull        ErrorResponseCode = "job_queue_full"
+	MethodNotAllowed    ErrorResponseCode = "method_not_allowed"
+	NotFound            ErrorResponseCode = "not_found"
+	OutOfBounds         ErrorResponseCode = "out_of_bounds"
+	TreeNotFound        ErrorResponseCode = "tree_not_found"
+	TreeRetired         ErrorResponseCode = "tree_retired"
+	Unauthorized        ErrorResponseCode = "unauthorized"
+	Unavailable         ErrorResponseCode = "unavailable"
+	ValidationFailed    ErrorResponseCode = "validation_failed"
+	WebhookNotFound     ErrorResponseCode = "webhook_not_found"
+)
+
+// Defines values for TreeHistoryResponseStatus.
+const (
+	Active  TreeHistoryResponseStatus = "active"
+	Retired TreeHistoryResponseStatus = "retired"
+)
+
+// Defines values for WebhookDeliveryResponseStatus.
+const (
+	Abandoned WebhookDeliveryResponseStatus = "abandoned"
+	Delivered WebhookDeliveryResponseStatus = "delivered"
+	Pending   WebhookDeliveryResponseStatus = "pending"
+)
+
+// Defines values for WebhookEventType.
+const (
+	DronePlanComputedEvent  WebhookEventType = "drone_plan.computed"
+	EstateCreatedEvent      WebhookEventType = "estate.created"
+	EstateStatsChangedEvent WebhookEventType = "estate.stats_changed"
+	TreeAddedEvent          WebhookEventType = "tree.added"
+	TreeRetiredEvent        WebhookEventType = "tree.retired"
+)
+
+// Defines values for GetEstateIdRasterParamsMetric.
+const (
+	Density GetEstateIdRasterParamsMetric = "density"
+	Height  GetEstateIdRasterParamsMetric = "height"
+)
+
+// Defines values for GetEstateIdRasterParamsFormat.
+const (
+	Asc GetEstateIdRasterParamsFormat = "asc"
+	Png GetEstateIdRasterParamsFormat = "png"
+)
+
+// DronePlanJobResponse defines model for DronePlanJobResponse.
+type DronePlanJobResponse struct {
+	CreatedAt time.Time                  `json:"created_at"`
+	Error     *string                    `json:"error,omitempty"`
+	Id        string                     `json:"id"`
+	Progress  int                        `json:"progress"`
+	Result    *DronePlanResponse         `json:"result,omitempty"`
+	Status    DronePlanJobResponseStatus `json:"status"`
+	UpdatedAt time.Time                  `json:"updated_at"`
+}
+
+// DronePlanJobResponseStatus defines model for DronePlanJobResponse.Status.
+type DronePlanJobResponseStatus string
+
+// DronePlanResponse defines model for DronePlanResponse.
+type DronePlanResponse struct {
+	Distance int64              `json:"distance"`
+	Rest     *DroneRestResponse `json:"rest,omitempty"`
+}
+
+// DroneRestResponse defines model for DroneRestResponse.
+type DroneRestResponse struct {
+	X *int `json:"x,omitempty"`
+	Y *int `json:"y,omitempty"`
+}
+
+// ErrorResponse defines model for ErrorResponse.
+type ErrorResponse struct {
+	// Code Machine-readable error code, stable across releases.
+	Code ErrorResponseCode `json:"code"`
+
+	// Details The invalid fields of a validation_failed error.
+	Details *[]FieldErrorResponse `json:"details,omitempty"`
+	Message string                `json:"message"`
+}
+
+// ErrorResponseCode Machine-readable error code, stable across releases.
+type ErrorResponseCode string
+
+// EstateRequest defines model for EstateRequest.
+type EstateRequest struct {
+	Length int `json:"length"`
+	Width  int `json:"width"`
+}
+
+// EstateResponse defines model for EstateResponse.
+type EstateResponse struct {
+	Id string `json:"id"`
+}
+
+// EstateStatsResponse defines model for EstateStatsResponse.
+type EstateStatsResponse struct {
+	Count  int64 `json:"count"`
+	Max    int   `json:"max"`
+	Median int   `json:"median"`
+	Min    int   `json:"min"`
+}
+
+// FieldErrorResponse defines model for FieldErrorResponse.
+type FieldErrorResponse struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// PlotHistoryResponse defines model for PlotHistoryResponse.
+type PlotHistoryResponse struct {
+	Trees []TreeHistoryResponse `json:"trees"`
+}
+
+// TreeHistoryResponse defines model for TreeHistoryResponse.
+type TreeHistoryResponse struct {
+	Height    int                       `json:"height"`
+	Id        string                    `json:"id"`
+	PlantedAt time.Time                 `json:"planted_at"`
+	RetiredAt *time.Time                `json:"retired_at,omitempty"`
+	Status    TreeHistoryResponseStatus `json:"status"`
+	X         int                       `json:"x"`
+	Y         int                       `json:"y"`
+}
+
+// TreeHistoryResponseStatus defines model for TreeHistoryResponse.Status.
+type TreeHistoryResponseStatus string
+
+// TreeRequest defines model for TreeRequest.
+type TreeRequest struct {
+	Height int `json:"height"`
+	X      int `json:"x"`
+	Y      int `json:"y"`
+}
+
+// TreeResponse defines model for TreeResponse.
+type TreeResponse struct {
+	Id string `json:"id"`
+}
+
+// WebhookDeliveryListResponse defines model for WebhookDeliveryListResponse.
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+}
+
+// WebhookDeliveryResponse defines model for WebhookDeliveryResponse.
+type WebhookDeliveryResponse struct {
+	Attempts    int        `json:"attempts"`
+	CreatedAt   time.Time  `json:"created_at"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+
+	// Error Why the last attempt failed.
+	Error   *string `json:"error,omitempty"`
+	EventId string  `json:"event_id"`
+
+	// EventType The type of the event. drone_plan.computed is sent when a drone plan
+	// job completes, not when /estate/{id}/drone-plan is read.
+	EventType WebhookEventType `json:"event_type"`
+	Id        string           `json:"id"`
+
+	// NextAttemptAt When a pending delivery is attempted again.
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+
+	// ResponseStatus The HTTP status answered to the last attempt.
+	ResponseStatus *int `json:"response_status,omitempty"`
+
+	// Status A pending delivery is attempted until the webhook answers a 2xx
+	// status, then it is delivered, or until it is abandoned after the
+	// last attempt.
+	Status    WebhookDeliveryResponseStatus `json:"status"`
+	UpdatedAt time.Time                     `json:"updated_at"`
+}
+
+// WebhookDeliveryResponseStatus A pending delivery is attempted until the webhook answers a 2xx
+// status, then it is delivered, or until it is abandoned after the
+// last attempt.
+type WebhookDeliveryResponseStatus string
+
+// WebhookEvent The body POSTed to the webhooks.
+type WebhookEvent struct {
+	CreatedAt time.Time `json:"created_at"`
+
+	// Data An EstateRequest for estate.created, a TreeHistoryResponse for
+	// tree.added and tree.retired, an EstateStatsResponse for
+	// estate.stats_changed and a DronePlanResponse for drone_plan.computed.
+	Data     interface{} `json:"data"`
+	EstateId string      `json:"estate_id"`
+
+	// Id Identifies the event, the same in every retry of its delivery.
+	Id string `json:"id"`
+
+	// Type The type of the event. drone_plan.computed is sent when a drone plan
+	// job completes, not when /estate/{id}/drone-plan is read.
+	Type WebhookEventType `json:"type"`
+}
+
+// WebhookEventType The type of the event. drone_plan.computed is sent when a drone plan
+// job completes, not when /estate/{id}/drone-plan is read.
+type WebhookEventType string
+
+// WebhookListResponse defines model for WebhookListResponse.
+type WebhookListResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+// WebhookRequest defines model for WebhookRequest.
+type WebhookRequest struct {
+	// EstateId Only the events of this estate are sent, those of every estate when omitted.
+	EstateId *openapi_types.UUID `json:"estate_id,omitempty"`
+	Events   []WebhookEventType  `json:"events"`
+
+	// Url The https URL receiving the events, it must reach a public address. http is only accepted when the server allows it.
+	Url string `json:"url"`
+}
+
+// WebhookResponse defines model for WebhookResponse.
+type WebhookResponse struct {
+	CreatedAt time.Time          `json:"created_at"`
+	EstateId  *string            `json:"estate_id,omitempty"`
+	Events    []WebhookEventType `json:"events"`
+	Id        string             `json:"id"`
+
+	// Secret Signs the deliveries, only answered when the webhook is created.
+	Secret *string `json:"secret,omitempty"`
+	Url    string  `json:"url"`
+}
+
+// YieldBandResponse defines model for YieldBandResponse.
+type YieldBandResponse struct {
+	MaxHeight     int     `json:"max_height"`
+	MinHeight     int     `json:"min_height"`
+	Name          string  `json:"name"`
+	TonnesPerTree float64 `json:"tonnes_per_tree"`
+	TonnesPerYear float64 `json:"tonnes_per_year"`
+	TreeCount     int     `json:"tree_count"`
+}
+
+// YieldForecastResponse defines model for YieldForecastResponse.
+type YieldForecastResponse struct {
+	Bands         []YieldBandResponse `json:"bands"`
+	TonnesPerYear float64             `json:"tonnes_per_year"`
+	TreeCount     int                 `json:"tree_count"`
+}
+
+// EstateIDPathParam defines model for EstateIDPathParam.
+type EstateIDPathParam = openapi_types.UUID
+
+// JobIDPathParam defines model for JobIDPathParam.
+type JobIDPathParam = openapi_types.UUID
+
+// PlotXPathParam defines model for PlotXPathParam.
+type PlotXPathParam = int
+
+// PlotYPathParam defines model for PlotYPathParam.
+type PlotYPathParam = int
+
+// TreeIDPathParam defines model for TreeIDPathParam.
+type TreeIDPathParam = openapi_types.UUID
+
+// WebhookIDPathParam defines model for WebhookIDPathParam.
+type WebhookIDPathParam = openapi_types.UUID
+
+// UnauthorizedError defines model for UnauthorizedError.
+type UnauthorizedError = ErrorResponse
+
+// GetEstateIdDronePlanParams defines parameters for GetEstateIdDronePlan.
+type GetEstateIdDronePlanParams struct {
+	// MaxDistance Maximum distance for drone monitoring travel.
+	MaxDistance *int64 `form:"max_distance,omitempty" json:"max_distance,omitempty"`
+}
+
+// PostEstateIdDronePlanJobsParams defines parameters for PostEstateIdDronePlanJobs.
+type PostEstateIdDronePlanJobsParams struct {
+	// MaxDistance Maximum distance for drone monitoring travel.
+	MaxDistance *int64 `form:"max_distance,omitempty" json:"max_distance,omitempty"`
+}
+
+// GetEstateIdRasterParams defines parameters for GetEstateIdRaster.
+type GetEstateIdRasterParams struct {
+	// Metric Value aggregated in each tile of the raster.
+	Metric *GetEstateIdRasterParamsMetric `form:"metric,omitempty" json:"metric,omitempty"`
+
+	// Format Output format, a PNG heatmap or an ESRI ASCII grid.
+	Format *GetEstateIdRasterParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+
+	// Size Maximum number of tiles on the longest side of the estate.
+	Size *int `form:"size,omitempty" json:"size,omitempty"`
+}
+
+// GetEstateIdRasterParamsMetric defines parameters for GetEstateIdRaster.
+type GetEstateIdRasterParamsMetric string
+
+// GetEstateIdRasterParamsFormat defines parameters for GetEstateIdRaster.
+type GetEstateIdRasterParamsFormat string
+
+// GetWebhooksIdDeliveriesParams defines parameters for GetWebhooksIdDeliveries.
+type GetWebhooksIdDeliveriesParams struct {
+	// Limit Maximum number of deliveries answered.
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// PostEstateJSONRequestBody defines body for PostEstate for application/json ContentType.
+type PostEstateJSONRequestBody = EstateRequest
+
+// PostEstateIdTreeJSONRequestBody defines body for PostEstateIdTree for application/json ContentType.
+type PostEstateIdTreeJSONRequestBody = TreeRequest
+
+// PostWebhooksJSONRequestBody defines body for PostWebhooks for application/json ContentType.
+type PostWebhooksJSONRequestBody = WebhookRequest
+
+// RequestEditorFn  is the function signature for the RequestEditor callback function
+type RequestEditorFn func(ctx context.Context, req *http.Request) error
+
+// Doer performs HTTP requests.
+//
+// The standard http.Client implements this interface.
+type HttpRequestDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client which conforms to the OpenAPI3 specification for this service.
+type Client struct {
+	// The endpoint of the server conforming to this interface, with scheme,
+	// https://api.deepmap.com for example. This can contain a path relative
+	// to the server, such as https://api.deepmap.com/dev-test, and all the
+	// paths in the swagger spec will be appended to the server.
+	Server string
+
+	// Doer for performing requests, typically a *http.Client with any
+	// customized settings, such as certificate chains.
+	Client HttpRequestDoer
+
+	// A list of callbacks for modifying requests which are generated before sending over
+	// the network.
+	RequestEditors []RequestEditorFn
+}
+
+// ClientOption allows setting custom parameters during construction
+type ClientOption func(*Client) error
+
+// Creates a new Client, with reasonable defaults
+func NewClient(server string, opts ...ClientOption) (*Client, error) {
+	// create a client with sane default values
+	client := Client{
+		Server: server,
+	}
+	// mutate client and add all optional params
+	for _, o := range opts {
+		if err := o(&client); err != nil {
+			return nil, err
+		}
+	}
+	// ensure the server URL always has a trailing slash
+	if !strings.HasSuffix(client.Server, "/") {
+		client.Server += "/"
+	}
+	// create httpClient, if not already present
+	if client.Client == nil {
+		client.Client = &http.Client{}
+	}
+	return &client, nil
+}
+
+// WithHTTPClient allows overriding the default Doer, which is
+// automatically created using http.Client. This is useful for tests.
+func WithHTTPClient(doer HttpRequestDoer) ClientOption {
+	return func(c *Client) error {
+		c.Client = doer
+		return nil
+	}
+}
+
+// WithRequestEditorFn allows setting up a callback function, which will be
+// called right before sending the request. This can be used to mutate the request.
+func WithRequestEditorFn(fn RequestEditorFn) ClientOption {
+	return func(c *Client) error {
+		c.RequestEditors = append(c.RequestEditors, fn)
+		return nil
+	}
+}
+
+// The interface specification for the client above.
+type ClientInterface interface {
+	// PostEstateWithBody request with any body
+	PostEstateWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	PostEstate(ctx context.Context, body PostEstateJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetEstateIdDronePlan request
+	GetEstateIdDronePlan(ctx context.Context, id EstateIDPathParam, params *GetEstateIdDronePlanParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PostEstateIdDronePlanJobs request
+	PostEstateIdDronePlanJobs(ctx context.Context, id EstateIDPathParam, params *PostEstateIdDronePlanJobsParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetEstateIdPlotXYHistory request
+	GetEstateIdPlotXYHistory(ctx context.Context, id EstateIDPathParam, x PlotXPathParam, y PlotYPathParam, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetEstateIdRaster request
+	GetEstateIdRaster(ctx context.Context, id EstateIDPathParam, params *GetEstateIdRasterParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetEstateIdStats request
+	GetEstateIdStats(ctx context.Context, id EstateIDPathParam, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PostEstateIdTreeWithBody request with any body
+	PostEstateIdTreeWithBody(ctx context.Context, id EstateIDPathParam, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	PostEstateIdTree(ctx context.Context, id EstateIDPathParam, body PostEstateIdTreeJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PostEstateIdTreeTreeIdRetire request
+	PostEstateIdTreeTreeIdRetire(ctx context.Context, id EstateIDPathParam, treeId TreeIDPathParam, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetEstateIdYieldForecast request
+	GetEstateIdYieldForecast(ctx context.Context, id EstateIDPathParam, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetJobsId request
+	GetJobsId(ctx context.Context, id JobIDPathParam, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetWebhooks request
+	GetWebhooks(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PostWebhooksWithBody request with any body
+	PostWebhooksWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	PostWebhooks(ctx context.Context, body PostWebhooksJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// DeleteWebhooksId request
+	DeleteWebhooksId(ctx context.Context, id WebhookIDPathParam, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetWebhooksIdDeliveries request
+	GetWebhooksIdDeliveries(ctx context.Context, id WebhookIDPathParam, params *GetWebhooksIdDeliveriesParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+}
+
+func (c *Client) PostEstateWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostEstateRequestWithBody(c.Server, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PostEstate(ctx context.Context, body PostEstateJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostEstateRequest(c.Server, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetEstateIdDronePlan(ctx context.Context, id EstateIDPathParam, params *GetEstateIdDronePlanParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetEstateIdDronePlanRequest(c.Server, id, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PostEstateIdDronePlanJobs(ctx context.Context, id EstateIDPathParam, params *PostEstateIdDronePlanJobsParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostEstateIdDronePlanJobsRequest(c.Server, id, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetEstateIdPlotXYHistory(ctx context.Context, id EstateIDPathParam, x PlotXPathParam, y PlotYPathParam, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetEstateIdPlotXYHistoryRequest(c.Server, id, x, y)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetEstateIdRaster(ctx context.Context, id EstateIDPathParam, params *GetEstateIdRasterParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetEstateIdRasterRequest(c.Server, id, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetEstateIdStats(ctx context.Context, id EstateIDPathParam, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetEstateIdStatsRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PostEstateIdTreeWithBody(ctx context.Context, id EstateIDPathParam, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostEstateIdTreeRequestWithBody(c.Server, id, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PostEstateIdTree(ctx context.Context, id EstateIDPathParam, body PostEstateIdTreeJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostEstateIdTreeRequest(c.Server, id, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PostEstateIdTreeTreeIdRetire(ctx context.Context, id EstateIDPathParam, treeId TreeIDPathParam, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostEstateIdTreeTreeIdRetireRequest(c.Server, id, treeId)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetEstateIdYieldForecast(ctx context.Context, id EstateIDPathParam, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetEstateIdYieldForecastRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetJobsId(ctx context.Context, id JobIDPathParam, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetJobsIdRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetWebhooks(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetWebhooksRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PostWebhooksWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostWebhooksRequestWithBody(c.Server, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PostWebhooks(ctx context.Context, body PostWebhooksJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostWebhooksRequest(c.Server, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) DeleteWebhooksId(ctx context.Context, id WebhookIDPathParam, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewDeleteWebhooksIdRequest(c.Server, id)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetWebhooksIdDeliveries(ctx context.Context, id WebhookIDPathParam, params *GetWebhooksIdDeliveriesParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetWebhooksIdDeliveriesRequest(c.Server, id, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+// NewPostEstateRequest calls the generic PostEstate builder with application/json body
+func NewPostEstateRequest(server string, body PostEstateJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewPostEstateRequestWithBody(server, "application/json", bodyReader)
+}
+
+// NewPostEstateRequestWithBody generates requests for PostEstate with any type of body
+func NewPostEstateRequestWithBody(server string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/estate")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewGetEstateIdDronePlanRequest generates requests for GetEstateIdDronePlan
+func NewGetEstateIdDronePlanRequest(server string, id EstateIDPathParam, params *GetEstateIdDronePlanParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/estate/%s/drone-plan", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.MaxDistance != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "max_distance", runtime.ParamLocationQuery, *params.MaxDistance); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewPostEstateIdDronePlanJobsRequest generates requests for PostEstateIdDronePlanJobs
+func NewPostEstateIdDronePlanJobsRequest(server string, id EstateIDPathParam, params *PostEstateIdDronePlanJobsParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/estate/%s/drone-plan/jobs", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.MaxDistance != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "max_distance", runtime.ParamLocationQuery, *params.MaxDistance); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetEstateIdPlotXYHistoryRequest generates requests for GetEstateIdPlotXYHistory
+func NewGetEstateIdPlotXYHistoryRequest(server string, id EstateIDPathParam, x PlotXPathParam, y PlotYPathParam) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var pathParam1 string
+
+	pathParam1, err = runtime.StyleParamWithLocation("simple", false, "x", runtime.ParamLocationPath, x)
+	if err != nil {
+		return nil, err
+	}
+
+	var pathParam2 string
+
+	pathParam2, err = runtime.StyleParamWithLocation("simple", false, "y", runtime.ParamLocationPath, y)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/estate/%s/plot/%s/%s/history", pathParam0, pathParam1, pathParam2)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetEstateIdRasterRequest generates requests for GetEstateIdRaster
+func NewGetEstateIdRasterRequest(server string, id EstateIDPathParam, params *GetEstateIdRasterParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/estate/%s/raster", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Metric != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "metric", runtime.ParamLocationQuery, *params.Metric); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Format != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "format", runtime.ParamLocationQuery, *params.Format); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Size != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "size", runtime.ParamLocationQuery, *params.Size); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetEstateIdStatsRequest generates requests for GetEstateIdStats
+func NewGetEstateIdStatsRequest(server string, id EstateIDPathParam) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/estate/%s/stats", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewPostEstateIdTreeRequest calls the generic PostEstateIdTree builder with application/json body
+func NewPostEstateIdTreeRequest(server string, id EstateIDPathParam, body PostEstateIdTreeJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewPostEstateIdTreeRequestWithBody(server, id, "application/json", bodyReader)
+}
+
+// NewPostEstateIdTreeRequestWithBody generates requests for PostEstateIdTree with any type of body
+func NewPostEstateIdTreeRequestWithBody(server string, id EstateIDPathParam, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/estate/%s/tree", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewPostEstateIdTreeTreeIdRetireRequest generates requests for PostEstateIdTreeTreeIdRetire
+func NewPostEstateIdTreeTreeIdRetireRequest(server string, id EstateIDPathParam, treeId TreeIDPathParam) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var pathParam1 string
+
+	pathParam1, err = runtime.StyleParamWithLocation("simple", false, "tree_id", runtime.ParamLocationPath, treeId)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/estate/%s/tree/%s/retire", pathParam0, pathParam1)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetEstateIdYieldForecastRequest generates requests for GetEstateIdYieldForecast
+func NewGetEstateIdYieldForecastRequest(server string, id EstateIDPathParam) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/estate/%s/yield-forecast", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetJobsIdRequest generates requests for GetJobsId
+func NewGetJobsIdRequest(server string, id JobIDPathParam) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/jobs/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetWebhooksRequest generates requests for GetWebhooks
+func NewGetWebhooksRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/webhooks")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewPostWebhooksRequest calls the generic PostWebhooks builder with application/json body
+func NewPostWebhooksRequest(server string, body PostWebhooksJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewPostWebhooksRequestWithBody(server, "application/json", bodyReader)
+}
+
+// NewPostWebhooksRequestWithBody generates requests for PostWebhooks with any type of body
+func NewPostWebhooksRequestWithBody(server string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/webhooks")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewDeleteWebhooksIdRequest generates requests for DeleteWebhooksId
+func NewDeleteWebhooksIdRequest(server string, id WebhookIDPathParam) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/webhooks/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("DELETE", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetWebhooksIdDeliveriesRequest generates requests for GetWebhooksIdDeliveries
+func NewGetWebhooksIdDeliveriesRequest(server string, id WebhookIDPathParam, params *GetWebhooksIdDeliveriesParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/webhooks/%s/deliveries", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Limit != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "limit", runtime.ParamLocationQuery, *params.Limit); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (c *Client) applyEditors(ctx context.Context, req *http.Request, additionalEditors []RequestEditorFn) error {
+	for _, r := range c.RequestEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+	for _, r := range additionalEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClientWithResponses builds on ClientInterface to offer response payloads
+type ClientWithResponses struct {
+	ClientInterface
+}
+
+// NewClientWithResponses creates a new ClientWithResponses, which wraps
+// Client with return type handling
+func NewClientWithResponses(server string, opts ...ClientOption) (*ClientWithResponses, error) {
+	client, err := NewClient(server, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientWithResponses{client}, nil
+}
+
+// WithBaseURL overrides the baseURL.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) error {
+		newBaseURL, err := url.Parse(baseURL)
+		if err != nil {
+			return err
+		}
+		c.Server = newBaseURL.String()
+		return nil
+	}
+}
+
+// ClientWithResponsesInterface is the interface specification for the client with responses above.
+type ClientWithResponsesInterface interface {
+	// PostEstateWithBodyWithResponse request with any body
+	PostEstateWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostEstateResponse, error)
+
+	PostEstateWithResponse(ctx context.Context, body PostEstateJSONRequestBody, reqEditors ...RequestEditorFn) (*PostEstateResponse, error)
+
+	// GetEstateIdDronePlanWithResponse request
+	GetEstateIdDronePlanWithResponse(ctx context.Context, id EstateIDPathParam, params *GetEstateIdDronePlanParams, reqEditors ...RequestEditorFn) (*GetEstateIdDronePlanResponse, error)
+
+	// PostEstateIdDronePlanJobsWithResponse request
+	PostEstateIdDronePlanJobsWithResponse(ctx context.Context, id EstateIDPathParam, params *PostEstateIdDronePlanJobsParams, reqEditors ...RequestEditorFn) (*PostEstateIdDronePlanJobsResponse, error)
+
+	// GetEstateIdPlotXYHistoryWithResponse request
+	GetEstateIdPlotXYHistoryWithResponse(ctx context.Context, id EstateIDPathParam, x PlotXPathParam, y PlotYPathParam, reqEditors ...RequestEditorFn) (*GetEstateIdPlotXYHistoryResponse, error)
+
+	// GetEstateIdRasterWithResponse request
+	GetEstateIdRasterWithResponse(ctx context.Context, id EstateIDPathParam, params *GetEstateIdRasterParams, reqEditors ...RequestEditorFn) (*GetEstateIdRasterResponse, error)
+
+	// GetEstateIdStatsWithResponse request
+	GetEstateIdStatsWithResponse(ctx context.Context, id EstateIDPathParam, reqEditors ...RequestEditorFn) (*GetEstateIdStatsResponse, error)
+
+	// PostEstateIdTreeWithBodyWithResponse request with any body
+	PostEstateIdTreeWithBodyWithResponse(ctx context.Context, id EstateIDPathParam, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostEstateIdTreeResponse, error)
+
+	PostEstateIdTreeWithResponse(ctx context.Context, id EstateIDPathParam, body PostEstateIdTreeJSONRequestBody, reqEditors ...RequestEditorFn) (*PostEstateIdTreeResponse, error)
+
+	// PostEstateIdTreeTreeIdRetireWithResponse request
+	PostEstateIdTreeTreeIdRetireWithResponse(ctx context.Context, id EstateIDPathParam, treeId TreeIDPathParam, reqEditors ...RequestEditorFn) (*PostEstateIdTreeTreeIdRetireResponse, error)
+
+	// GetEstateIdYieldForecastWithResponse request
+	GetEstateIdYieldForecastWithResponse(ctx context.Context, id EstateIDPathParam, reqEditors ...RequestEditorFn) (*GetEstateIdYieldForecastResponse, error)
+
+	// GetJobsIdWithResponse request
+	GetJobsIdWithResponse(ctx context.Context, id JobIDPathParam, reqEditors ...RequestEditorFn) (*GetJobsIdResponse, error)
+
+	// GetWebhooksWithResponse request
+	GetWebhooksWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetWebhooksResponse, error)
+
+	// PostWebhooksWithBodyWithResponse request with any body
+	PostWebhooksWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostWebhooksResponse, error)
+
+	PostWebhooksWithResponse(ctx context.Context, body PostWebhooksJSONRequestBody, reqEditors ...RequestEditorFn) (*PostWebhooksResponse, error)
+
+	// DeleteWebhooksIdWithResponse request
+	DeleteWebhooksIdWithResponse(ctx context.Context, id WebhookIDPathParam, reqEditors ...RequestEditorFn) (*DeleteWebhooksIdResponse, error)
+
+	// GetWebhooksIdDeliveriesWithResponse request
+	GetWebhooksIdDeliveriesWithResponse(ctx context.Context, id WebhookIDPathParam, params *GetWebhooksIdDeliveriesParams, reqEditors ...RequestEditorFn) (*GetWebhooksIdDeliveriesResponse, error)
+}
+
+type PostEstateResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON201      *EstateResponse
+	JSON400      *ErrorResponse
+	JSON401      *UnauthorizedError
+}
+
+// Status returns HTTPResponse.Status
+func (r PostEstateResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PostEstateResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetEstateIdDronePlanResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *DronePlanResponse
+	JSON401      *UnauthorizedError
+	JSON404      *ErrorResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r GetEstateIdDronePlanResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetEstateIdDronePlanResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PostEstateIdDronePlanJobsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON202      *DronePlanJobResponse
+	JSON401      *UnauthorizedError
+	JSON404      *ErrorResponse
+	JSON503      *ErrorResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r PostEstateIdDronePlanJobsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PostEstateIdDronePlanJobsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetEstateIdPlotXYHistoryResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *PlotHistoryResponse
+	JSON400      *ErrorResponse
+	JSON401      *UnauthorizedError
+	JSON404      *ErrorResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r GetEstateIdPlotXYHistoryResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetEstateIdPlotXYHistoryResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetEstateIdRasterResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON400      *ErrorResponse
+	JSON401      *UnauthorizedError
+	JSON404      *ErrorResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r GetEstateIdRasterResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetEstateIdRasterResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetEstateIdStatsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *EstateStatsResponse
+	JSON401      *UnauthorizedError
+	JSON404      *ErrorResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r GetEstateIdStatsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetEstateIdStatsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PostEstateIdTreeResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON201      *TreeResponse
+	JSON400      *ErrorResponse
+	JSON401      *UnauthorizedError
+	JSON404      *ErrorResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r PostEstateIdTreeResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PostEstateIdTreeResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PostEstateIdTreeTreeIdRetireResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *TreeResponse
+	JSON400      *ErrorResponse
+	JSON401      *UnauthorizedError
+	JSON404      *ErrorResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r PostEstateIdTreeTreeIdRetireResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PostEstateIdTreeTreeIdRetireResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetEstateIdYieldForecastResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *YieldForecastResponse
+	JSON401      *UnauthorizedError
+	JSON404      *ErrorResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r GetEstateIdYieldForecastResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetEstateIdYieldForecastResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetJobsIdResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *DronePlanJobResponse
+	JSON401      *UnauthorizedError
+	JSON404      *ErrorResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r GetJobsIdResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetJobsIdResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetWebhooksResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *WebhookListResponse
+	JSON401      *UnauthorizedError
+}
+
+// Status returns HTTPResponse.Status
+func (r GetWebhooksResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetWebhooksResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PostWebhooksResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON201      *WebhookResponse
+	JSON400      *ErrorResponse
+	JSON401      *UnauthorizedError
+	JSON404      *ErrorResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r PostWebhooksResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PostWebhooksResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type DeleteWebhooksIdResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON401      *UnauthorizedError
+	JSON404      *ErrorResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r DeleteWebhooksIdResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r DeleteWebhooksIdResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetWebhooksIdDeliveriesResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *WebhookDeliveryListResponse
+	JSON400      *ErrorResponse
+	JSON401      *UnauthorizedError
+	JSON404      *ErrorResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r GetWebhooksIdDeliveriesResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetWebhooksIdDeliveriesResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+// PostEstateWithBodyWithResponse request with arbitrary body returning *PostEstateResponse
+func (c *ClientWithResponses) PostEstateWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostEstateResponse, error) {
+	rsp, err := c.PostEstateWithBody(ctx, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostEstateResponse(rsp)
+}
+
+func (c *ClientWithResponses) PostEstateWithResponse(ctx context.Context, body PostEstateJSONRequestBody, reqEditors ...RequestEditorFn) (*PostEstateResponse, error) {
+	rsp, err := c.PostEstate(ctx, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostEstateResponse(rsp)
+}
+
+// GetEstateIdDronePlanWithResponse request returning *GetEstateIdDronePlanResponse
+func (c *ClientWithResponses) GetEstateIdDronePlanWithResponse(ctx context.Context, id EstateIDPathParam, params *GetEstateIdDronePlanParams, reqEditors ...RequestEditorFn) (*GetEstateIdDronePlanResponse, error) {
+	rsp, err := c.GetEstateIdDronePlan(ctx, id, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetEstateIdDronePlanResponse(rsp)
+}
+
+// PostEstateIdDronePlanJobsWithResponse request returning *PostEstateIdDronePlanJobsResponse
+func (c *ClientWithResponses) PostEstateIdDronePlanJobsWithResponse(ctx context.Context, id EstateIDPathParam, params *PostEstateIdDronePlanJobsParams, reqEditors ...RequestEditorFn) (*PostEstateIdDronePlanJobsResponse, error) {
+	rsp, err := c.PostEstateIdDronePlanJobs(ctx, id, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostEstateIdDronePlanJobsResponse(rsp)
+}
+
+// GetEstateIdPlotXYHistoryWithResponse request returning *GetEstateIdPlotXYHistoryResponse
+func (c *ClientWithResponses) GetEstateIdPlotXYHistoryWithResponse(ctx context.Context, id EstateIDPathParam, x PlotXPathParam, y PlotYPathParam, reqEditors ...RequestEditorFn) (*GetEstateIdPlotXYHistoryResponse, error) {
+	rsp, err := c.GetEstateIdPlotXYHistory(ctx, id, x, y, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetEstateIdPlotXYHistoryResponse(rsp)
+}
+
+// GetEstateIdRasterWithResponse request returning *GetEstateIdRasterResponse
+func (c *ClientWithResponses) GetEstateIdRasterWithResponse(ctx context.Context, id EstateIDPathParam, params *GetEstateIdRasterParams, reqEditors ...RequestEditorFn) (*GetEstateIdRasterResponse, error) {
+	rsp, err := c.GetEstateIdRaster(ctx, id, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetEstateIdRasterResponse(rsp)
+}
+
+// GetEstateIdStatsWithResponse request returning *GetEstateIdStatsResponse
+func (c *ClientWithResponses) GetEstateIdStatsWithResponse(ctx context.Context, id EstateIDPathParam, reqEditors ...RequestEditorFn) (*GetEstateIdStatsResponse, error) {
+	rsp, err := c.GetEstateIdStats(ctx, id, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetEstateIdStatsResponse(rsp)
+}
+
+// PostEstateIdTreeWithBodyWithResponse request with arbitrary body returning *PostEstateIdTreeResponse
+func (c *ClientWithResponses) PostEstateIdTreeWithBodyWithResponse(ctx context.Context, id EstateIDPathParam, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostEstateIdTreeResponse, error) {
+	rsp, err := c.PostEstateIdTreeWithBody(ctx, id, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostEstateIdTreeResponse(rsp)
+}
+
+func (c *ClientWithResponses) PostEstateIdTreeWithResponse(ctx context.Context, id EstateIDPathParam, body PostEstateIdTreeJSONRequestBody, reqEditors ...RequestEditorFn) (*PostEstateIdTreeResponse, error) {
+	rsp, err := c.PostEstateIdTree(ctx, id, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostEstateIdTreeResponse(rsp)
+}
+
+// PostEstateIdTreeTreeIdRetireWithResponse request returning *PostEstateIdTreeTreeIdRetireResponse
+func (c *ClientWithResponses) PostEstateIdTreeTreeIdRetireWithResponse(ctx context.Context, id EstateIDPathParam, treeId TreeIDPathParam, reqEditors ...RequestEditorFn) (*PostEstateIdTreeTreeIdRetireResponse, error) {
+	rsp, err := c.PostEstateIdTreeTreeIdRetire(ctx, id, treeId, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostEstateIdTreeTreeIdRetireResponse(rsp)
+}
+
+// GetEstateIdYieldForecastWithResponse request returning *GetEstateIdYieldForecastResponse
+func (c *ClientWithResponses) GetEstateIdYieldForecastWithResponse(ctx context.Context, id EstateIDPathParam, reqEditors ...RequestEditorFn) (*GetEstateIdYieldForecastResponse, error) {
+	rsp, err := c.GetEstateIdYieldForecast(ctx, id, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetEstateIdYieldForecastResponse(rsp)
+}
+
+// GetJobsIdWithResponse request returning *GetJobsIdResponse
+func (c *ClientWithResponses) GetJobsIdWithResponse(ctx context.Context, id JobIDPathParam, reqEditors ...RequestEditorFn) (*GetJobsIdResponse, error) {
+	rsp, err := c.GetJobsId(ctx, id, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetJobsIdResponse(rsp)
+}
+
+// GetWebhooksWithResponse request returning *GetWebhooksResponse
+func (c *ClientWithResponses) GetWebhooksWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetWebhooksResponse, error) {
+	rsp, err := c.GetWebhooks(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetWebhooksResponse(rsp)
+}
+
+// PostWebhooksWithBodyWithResponse request with arbitrary body returning *PostWebhooksResponse
+func (c *ClientWithResponses) PostWebhooksWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostWebhooksResponse, error) {
+	rsp, err := c.PostWebhooksWithBody(ctx, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostWebhooksResponse(rsp)
+}
+
+func (c *ClientWithResponses) PostWebhooksWithResponse(ctx context.Context, body PostWebhooksJSONRequestBody, reqEditors ...RequestEditorFn) (*PostWebhooksResponse, error) {
+	rsp, err := c.PostWebhooks(ctx, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostWebhooksResponse(rsp)
+}
+
+// DeleteWebhooksIdWithResponse request returning *DeleteWebhooksIdResponse
+func (c *ClientWithResponses) DeleteWebhooksIdWithResponse(ctx context.Context, id WebhookIDPathParam, reqEditors ...RequestEditorFn) (*DeleteWebhooksIdResponse, error) {
+	rsp, err := c.DeleteWebhooksId(ctx, id, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDeleteWebhooksIdResponse(rsp)
+}
+
+// GetWebhooksIdDeliveriesWithResponse request returning *GetWebhooksIdDeliveriesResponse
+func (c *ClientWithResponses) GetWebhooksIdDeliveriesWithResponse(ctx context.Context, id WebhookIDPathParam, params *GetWebhooksIdDeliveriesParams, reqEditors ...RequestEditorFn) (*GetWebhooksIdDeliveriesResponse, error) {
+	rsp, err := c.GetWebhooksIdDeliveries(ctx, id, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetWebhooksIdDeliveriesResponse(rsp)
+}
+
+// ParsePostEstateResponse parses an HTTP response from a PostEstateWithResponse call
+func ParsePostEstateResponse(rsp *http.Response) (*PostEstateResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PostEstateResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
+		var dest EstateResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON201 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest UnauthorizedError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetEstateIdDronePlanResponse parses an HTTP response from a GetEstateIdDronePlanWithResponse call
+func ParseGetEstateIdDronePlanResponse(rsp *http.Response) (*GetEstateIdDronePlanResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetEstateIdDronePlanResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest DronePlanResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest UnauthorizedError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParsePostEstateIdDronePlanJobsResponse parses an HTTP response from a PostEstateIdDronePlanJobsWithResponse call
+func ParsePostEstateIdDronePlanJobsResponse(rsp *http.Response) (*PostEstateIdDronePlanJobsResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PostEstateIdDronePlanJobsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 202:
+		var dest DronePlanJobResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON202 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest UnauthorizedError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 503:
+		var dest ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON503 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetEstateIdPlotXYHistoryResponse parses an HTTP response from a GetEstateIdPlotXYHistoryWithResponse call
+func ParseGetEstateIdPlotXYHistoryResponse(rsp *http.Response) (*GetEstateIdPlotXYHistoryResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetEstateIdPlotXYHistoryResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest PlotHistoryResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest UnauthorizedError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetEstateIdRasterResponse parses an HTTP response from a GetEstateIdRasterWithResponse call
+func ParseGetEstateIdRasterResponse(rsp *http.Response) (*GetEstateIdRasterResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetEstateIdRasterResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest UnauthorizedError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetEstateIdStatsResponse parses an HTTP response from a GetEstateIdStatsWithResponse call
+func ParseGetEstateIdStatsResponse(rsp *http.Response) (*GetEstateIdStatsResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetEstateIdStatsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest EstateStatsResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest UnauthorizedError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParsePostEstateIdTreeResponse parses an HTTP response from a PostEstateIdTreeWithResponse call
+func ParsePostEstateIdTreeResponse(rsp *http.Response) (*PostEstateIdTreeResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PostEstateIdTreeResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
+		var dest TreeResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON201 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest UnauthorizedError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParsePostEstateIdTreeTreeIdRetireResponse parses an HTTP response from a PostEstateIdTreeTreeIdRetireWithResponse call
+func ParsePostEstateIdTreeTreeIdRetireResponse(rsp *http.Response) (*PostEstateIdTreeTreeIdRetireResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PostEstateIdTreeTreeIdRetireResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest TreeResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest UnauthorizedError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetEstateIdYieldForecastResponse parses an HTTP response from a GetEstateIdYieldForecastWithResponse call
+func ParseGetEstateIdYieldForecastResponse(rsp *http.Response) (*GetEstateIdYieldForecastResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetEstateIdYieldForecastResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest YieldForecastResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest UnauthorizedError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetJobsIdResponse parses an HTTP response from a GetJobsIdWithResponse call
+func ParseGetJobsIdResponse(rsp *http.Response) (*GetJobsIdResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetJobsIdResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest DronePlanJobResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest UnauthorizedError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetWebhooksResponse parses an HTTP response from a GetWebhooksWithResponse call
+func ParseGetWebhooksResponse(rsp *http.Response) (*GetWebhooksResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetWebhooksResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest WebhookListResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest UnauthorizedError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParsePostWebhooksResponse parses an HTTP response from a PostWebhooksWithResponse call
+func ParsePostWebhooksResponse(rsp *http.Response) (*PostWebhooksResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PostWebhooksResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
+		var dest WebhookResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON201 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest UnauthorizedError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseDeleteWebhooksIdResponse parses an HTTP response from a DeleteWebhooksIdWithResponse call
+func ParseDeleteWebhooksIdResponse(rsp *http.Response) (*DeleteWebhooksIdResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &DeleteWebhooksIdResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest UnauthorizedError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetWebhooksIdDeliveriesResponse parses an HTTP response from a GetWebhooksIdDeliveriesWithResponse call
+func ParseGetWebhooksIdDeliveriesResponse(rsp *http.Response) (*GetWebhooksIdDeliveriesResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetWebhooksIdDeliveriesResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest WebhookDeliveryListResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest UnauthorizedError
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest ErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	}
+
+	return response, nil
+}
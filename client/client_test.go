@@ -0,0 +1,230 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/auth"
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/handler"
+	"github.com/SawitProRecruitment/UserService/logging"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const apiKey = "key-a"
+
+// newAPI serves the API from memory, authenticated with apiKey and
+// validated against api.yml like the server
+func newAPI(t *testing.T) *httptest.Server {
+	server := handler.NewServer(handler.NewServerOptions{
+		Repository: repository.NewMemoryRepository(),
+		Logger:     logging.Discard(),
+	})
+	t.Cleanup(func() { server.Close(context.Background()) })
+
+	validator, err := handler.RequestValidator()
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.HTTPErrorHandler = server.ErrorHandler
+	e.Use(auth.Middleware(auth.NewAuthenticator(auth.NewAuthenticatorOptions{
+		APIKeys: []auth.APIKey{{Key: apiKey, Name: "test", OrganisationID: "estate-co-a"}},
+	})))
+	e.Use(validator)
+	generated.RegisterHandlers(e, server)
+
+	api := httptest.NewServer(e)
+	t.Cleanup(api.Close)
+	return api
+}
+
+// noRetry keeps the tests of the errors fast
+var noRetry = WithRetry(RetryPolicy{MaxAttempts: 1})
+
+func TestEstateClient(t *testing.T) {
+	ctx := context.Background()
+	c, err := New(newAPI(t).URL, WithAPIKey(apiKey))
+	require.NoError(t, err)
+
+	estate, err := c.CreateEstate(ctx, EstateRequest{Width: 1, Length: 5})
+	require.NoError(t, err)
+
+	var treeIDs []string
+	for _, tree := range []TreeRequest{{X: 2, Y: 1, Height: 10}, {X: 3, Y: 1, Height: 20}, {X: 4, Y: 1, Height: 10}} {
+		created, err := c.AddTree(ctx, estate.Id, tree)
+		require.NoError(t, err)
+		treeIDs = append(treeIDs, created.Id)
+	}
+
+	stats, err := c.Stats(ctx, estate.Id)
+	require.NoError(t, err)
+	assert.Equal(t, EstateStatsResponse{Count: 3, Min: 10, Max: 20, Median: 10}, *stats)
+
+	plan, err := c.DronePlan(ctx, estate.Id, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(82), plan.Distance)
+
+	// The job computes the same plan
+	job, err := c.StartDronePlanJob(ctx, estate.Id, nil)
+	require.NoError(t, err)
+	job, err = c.WaitForJob(ctx, job.Id, 10*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, Succeeded, job.Status)
+	assert.Equal(t, plan, job.Result)
+
+	forecast, err := c.YieldForecast(ctx, estate.Id)
+	require.NoError(t, err)
+	assert.Equal(t, 3, forecast.TreeCount)
+
+	format := Asc
+	raster, err := c.Raster(ctx, estate.Id, &GetEstateIdRasterParams{Format: &format})
+	require.NoError(t, err)
+	assert.Contains(t, string(raster), "ncols")
+
+	_, err = c.RetireTree(ctx, estate.Id, treeIDs[0])
+	require.NoError(t, err)
+	history, err := c.PlotHistory(ctx, estate.Id, 2, 1)
+	require.NoError(t, err)
+	if assert.Len(t, history.Trees, 1) {
+		assert.Equal(t, Retired, history.Trees[0].Status)
+	}
+}
+
+func TestEstateClient_Webhooks(t *testing.T) {
+	ctx := context.Background()
+	c, err := New(newAPI(t).URL, WithAPIKey(apiKey))
+	require.NoError(t, err)
+
+	estate, err := c.CreateEstate(ctx, EstateRequest{Width: 1, Length: 5})
+	require.NoError(t, err)
+
+	estateID := uuid.MustParse(estate.Id)
+	webhook, err := c.CreateWebhook(ctx, WebhookRequest{
+		Url:      "https://example.com/hooks",
+		Events:   []WebhookEventType{TreeAddedEvent},
+		EstateId: &estateID,
+	})
+	require.NoError(t, err)
+	assert.NotNil(t, webhook.Secret)
+
+	webhooks, err := c.Webhooks(ctx)
+	require.NoError(t, err)
+	if assert.Len(t, webhooks.Webhooks, 1) {
+		assert.Nil(t, webhooks.Webhooks[0].Secret)
+	}
+
+	// The dispatcher does not run, the delivery stays pending
+	_, err = c.AddTree(ctx, estate.Id, TreeRequest{X: 1, Y: 1, Height: 10})
+	require.NoError(t, err)
+	deliveries, err := c.WebhookDeliveries(ctx, webhook.Id, nil)
+	require.NoError(t, err)
+	if assert.Len(t, deliveries.Deliveries, 1) {
+		assert.Equal(t, TreeAddedEvent, deliveries.Deliveries[0].EventType)
+		assert.Equal(t, Pending, deliveries.Deliveries[0].Status)
+	}
+
+	require.NoError(t, c.DeleteWebhook(ctx, webhook.Id))
+	err = c.DeleteWebhook(ctx, webhook.Id)
+	assert.True(t, IsCode(err, WebhookNotFound))
+}
+
+func TestEstateClient_Errors(t *testing.T) {
+	ctx := context.Background()
+	api := newAPI(t)
+	c, err := New(api.URL, WithAPIKey(apiKey), noRetry)
+	require.NoError(t, err)
+
+	_, err = c.Stats(ctx, "00000000-0000-0000-0000-000000000000")
+	assert.True(t, IsCode(err, EstateNotFound))
+	var apiErr *Error
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	}
+
+	_, err = c.CreateEstate(ctx, EstateRequest{Width: 0, Length: 5})
+	assert.True(t, IsCode(err, ValidationFailed))
+	assert.ErrorContains(t, err, "width")
+
+	_, err = c.Stats(ctx, "not-a-uuid")
+	assert.ErrorContains(t, err, `invalid estate id "not-a-uuid"`)
+
+	// Without the API key
+	anonymous, err := New(api.URL, noRetry)
+	require.NoError(t, err)
+	_, err = anonymous.CreateEstate(ctx, EstateRequest{Width: 1, Length: 1})
+	assert.True(t, IsCode(err, Unauthorized))
+
+	// The answers of a proxy are not JSON
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "<html>bad gateway</html>", http.StatusBadGateway)
+	}))
+	defer proxy.Close()
+	c, err = New(proxy.URL, noRetry)
+	require.NoError(t, err)
+	_, err = c.CreateEstate(ctx, EstateRequest{Width: 1, Length: 1})
+	assert.True(t, IsCode(err, Unavailable))
+	assert.EqualError(t, err, "unavailable: 502 Bad Gateway")
+}
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	policy := WithRetry(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond})
+
+	var calls atomic.Int32
+	status := http.StatusServiceUnavailable
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			// Retried after MaxBackoff rather than a minute
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(status)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"estate"}`))
+	}))
+	defer api.Close()
+
+	c, err := New(api.URL, policy)
+	require.NoError(t, err)
+
+	// The body of the POST is sent again
+	estate, err := c.CreateEstate(ctx, EstateRequest{Width: 1, Length: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "estate", estate.Id)
+	assert.Equal(t, int32(3), calls.Load())
+
+	// A POST may have been processed when the gateway fails
+	calls.Store(0)
+	status = http.StatusBadGateway
+	_, err = c.CreateEstate(ctx, EstateRequest{Width: 1, Length: 1})
+	assert.True(t, IsCode(err, Unavailable))
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Up to MaxAttempts
+	calls.Store(-10)
+	status = http.StatusServiceUnavailable
+	_, err = c.Stats(ctx, "00000000-0000-0000-0000-000000000000")
+	assert.True(t, IsCode(err, Unavailable))
+	assert.Equal(t, int32(-7), calls.Load())
+
+	// Nor after the caller gave up
+	calls.Store(-10)
+	c, err = New(api.URL, WithRetry(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Minute, MaxBackoff: time.Minute}))
+	require.NoError(t, err)
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = c.Stats(timeout, "00000000-0000-0000-0000-000000000000")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, int32(-9), calls.Load())
+}
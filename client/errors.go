@@ -0,0 +1,71 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Error is an error answered by the API, its ErrorResponse holds the code
+// to branch on, such as EstateNotFound
+type Error struct {
+	StatusCode int
+	ErrorResponse
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s", e.Code, e.Message)
+	if e.Details != nil {
+		for _, detail := range *e.Details {
+			fmt.Fprintf(&b, ", %s %s", detail.Field, detail.Message)
+		}
+	}
+
+	return b.String()
+}
+
+// IsCode is true when err is an error of the API with the code
+func IsCode(err error, code ErrorResponseCode) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
+
+// newError decodes the ErrorResponse of resp, the answers of a proxy in
+// front of the API are not JSON, they keep their status as message
+func newError(resp *http.Response) *Error {
+	apiErr := &Error{StatusCode: resp.StatusCode}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err := json.Unmarshal(body, &apiErr.ErrorResponse); err != nil || apiErr.Code == "" {
+		apiErr.ErrorResponse = ErrorResponse{
+			Code:    statusCode(resp.StatusCode),
+			Message: resp.Status,
+		}
+	}
+
+	return apiErr
+}
+
+// statusCode is the code the API answers with status
+func statusCode(status int) ErrorResponseCode {
+	switch status {
+	case http.StatusBadRequest:
+		return ValidationFailed
+	case http.StatusUnauthorized:
+		return Unauthorized
+	case http.StatusNotFound:
+		return NotFound
+	case http.StatusMethodNotAllowed:
+		return MethodNotAllowed
+	case http.StatusConflict:
+		return Conflict
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout, http.StatusTooManyRequests:
+		return Unavailable
+	default:
+		return InternalError
+	}
+}
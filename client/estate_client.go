@@ -0,0 +1,274 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EstateClient is the typed client of the estate API. Every method answers
+// the decoded response, or an *Error holding the ErrorResponse of the API.
+// The requests are retried with DefaultRetryPolicy unless WithRetry sets
+// another policy.
+type EstateClient struct {
+	api *Client
+}
+
+// New is a client of the API served at server, such as
+// http://localhost:1323. WithAPIKey or WithBearerToken authenticate it.
+func New(server string, opts ...ClientOption) (*EstateClient, error) {
+	api, err := NewClient(server, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := api.Client.(*retryDoer); !ok {
+		api.Client = NewRetryDoer(api.Client, DefaultRetryPolicy)
+	}
+
+	return &EstateClient{api: api}, nil
+}
+
+// WithAPIKey sends the API key of an organisation with every request
+func WithAPIKey(key string) ClientOption {
+	return WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("X-API-Key", key)
+		return nil
+	})
+}
+
+// WithBearerToken sends the JWT of a user with every request
+func WithBearerToken(token string) ClientOption {
+	return WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+func (c *EstateClient) CreateEstate(ctx context.Context, req EstateRequest) (*EstateResponse, error) {
+	resp, err := c.api.PostEstate(ctx, req)
+	return decode[EstateResponse](resp, err, http.StatusCreated)
+}
+
+func (c *EstateClient) AddTree(ctx context.Context, estateID string, req TreeRequest) (*TreeResponse, error) {
+	id, err := parseID("estate", estateID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.api.PostEstateIdTree(ctx, id, req)
+	return decode[TreeResponse](resp, err, http.StatusCreated)
+}
+
+func (c *EstateClient) RetireTree(ctx context.Context, estateID string, treeID string) (*TreeResponse, error) {
+	id, err := parseID("estate", estateID)
+	if err != nil {
+		return nil, err
+	}
+	treeUUID, err := parseID("tree", treeID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.api.PostEstateIdTreeTreeIdRetire(ctx, id, treeUUID)
+	return decode[TreeResponse](resp, err, http.StatusOK)
+}
+
+// PlotHistory lists every tree ever planted in the plot x, y, oldest first
+func (c *EstateClient) PlotHistory(ctx context.Context, estateID string, x int, y int) (*PlotHistoryResponse, error) {
+	id, err := parseID("estate", estateID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.api.GetEstateIdPlotXYHistory(ctx, id, x, y)
+	return decode[PlotHistoryResponse](resp, err, http.StatusOK)
+}
+
+func (c *EstateClient) Stats(ctx context.Context, estateID string) (*EstateStatsResponse, error) {
+	id, err := parseID("estate", estateID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.api.GetEstateIdStats(ctx, id)
+	return decode[EstateStatsResponse](resp, err, http.StatusOK)
+}
+
+// DronePlan computes the drone plan while the request waits, params may be
+// nil. StartDronePlanJob is better suited to the large estates.
+func (c *EstateClient) DronePlan(ctx context.Context, estateID string, params *GetEstateIdDronePlanParams) (*DronePlanResponse, error) {
+	id, err := parseID("estate", estateID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.api.GetEstateIdDronePlan(ctx, id, params)
+	return decode[DronePlanResponse](resp, err, http.StatusOK)
+}
+
+func (c *EstateClient) YieldForecast(ctx context.Context, estateID string) (*YieldForecastResponse, error) {
+	id, err := parseID("estate", estateID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.api.GetEstateIdYieldForecast(ctx, id)
+	return decode[YieldForecastResponse](resp, err, http.StatusOK)
+}
+
+// Raster is the PNG or the ESRI ASCII grid of the estate, as chosen by
+// params.Format
+func (c *EstateClient) Raster(ctx context.Context, estateID string, params *GetEstateIdRasterParams) ([]byte, error) {
+	id, err := parseID("estate", estateID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.api.GetEstateIdRaster(ctx, id, params)
+	if err := check(resp, err, http.StatusOK); err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
+
+// StartDronePlanJob queues the computation of the drone plan, WaitForJob
+// waits for its result
+func (c *EstateClient) StartDronePlanJob(ctx context.Context, estateID string, params *PostEstateIdDronePlanJobsParams) (*DronePlanJobResponse, error) {
+	id, err := parseID("estate", estateID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.api.PostEstateIdDronePlanJobs(ctx, id, params)
+	return decode[DronePlanJobResponse](resp, err, http.StatusAccepted)
+}
+
+func (c *EstateClient) Job(ctx context.Context, jobID string) (*DronePlanJobResponse, error) {
+	id, err := parseID("job", jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.api.GetJobsId(ctx, id)
+	return decode[DronePlanJobResponse](resp, err, http.StatusOK)
+}
+
+// ErrJobFailed is returned by WaitForJob for the jobs which failed or were
+// cancelled, the job holds the reason
+var ErrJobFailed = errors.New("job did not succeed")
+
+// WaitForJob polls the job every interval, one second when zero, until it
+// succeeds, fails or ctx is done
+func (c *EstateClient) WaitForJob(ctx context.Context, jobID string, interval time.Duration) (*DronePlanJobResponse, error) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	for {
+		job, err := c.Job(ctx, jobID)
+		if err != nil {
+			return nil, err
+		}
+
+		switch job.Status {
+		case Succeeded:
+			return job, nil
+		case Failed, Cancelled:
+			reason := string(job.Status)
+			if job.Error != nil {
+				reason = *job.Error
+			}
+			return job, fmt.Errorf("%w: %s", ErrJobFailed, reason)
+		}
+
+		if err := sleep(ctx, interval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// CreateWebhook subscribes req.Url to the events of the organisation, or of
+// the estate req.EstateId. The secret signing the deliveries is only answered
+// here.
+func (c *EstateClient) CreateWebhook(ctx context.Context, req WebhookRequest) (*WebhookResponse, error) {
+	resp, err := c.api.PostWebhooks(ctx, req)
+	return decode[WebhookResponse](resp, err, http.StatusCreated)
+}
+
+func (c *EstateClient) Webhooks(ctx context.Context) (*WebhookListResponse, error) {
+	resp, err := c.api.GetWebhooks(ctx)
+	return decode[WebhookListResponse](resp, err, http.StatusOK)
+}
+
+func (c *EstateClient) DeleteWebhook(ctx context.Context, webhookID string) error {
+	id, err := parseID("webhook", webhookID)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.api.DeleteWebhooksId(ctx, id)
+	if err := check(resp, err, http.StatusNoContent); err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// WebhookDeliveries lists the latest deliveries of the webhook, params may be
+// nil
+func (c *EstateClient) WebhookDeliveries(ctx context.Context, webhookID string, params *GetWebhooksIdDeliveriesParams) (*WebhookDeliveryListResponse, error) {
+	id, err := parseID("webhook", webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.api.GetWebhooksIdDeliveries(ctx, id, params)
+	return decode[WebhookDeliveryListResponse](resp, err, http.StatusOK)
+}
+
+// decode checks the status of resp and decodes its body
+func decode[T any](resp *http.Response, err error, status int) (*T, error) {
+	if err := check(resp, err, status); err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var out T
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("decoding the response: %w", err)
+	}
+
+	return &out, nil
+}
+
+// check turns the answers other than status into an *Error
+func check(resp *http.Response, err error, status int) error {
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != status {
+		defer resp.Body.Close()
+		return newError(resp)
+	}
+
+	return nil
+}
+
+func parseID(name string, id string) (uuid.UUID, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid %s id %q: %w", name, id, err)
+	}
+
+	return parsed, nil
+}
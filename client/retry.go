@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy retries the requests failing for a reason which may not last:
+// the API unreachable, overloaded or restarting.
+//
+// A GET is retried on a network error and on a 429, 502, 503 or 504 answer.
+// A POST is only retried on a 429 or 503 answer, the API then did not process
+// it, a POST failing on the network may have been processed and is not sent
+// twice.
+type RetryPolicy struct {
+	// MaxAttempts counts the first attempt, 1 disables the retries
+	MaxAttempts int
+	// MinBackoff is the wait before the first retry, doubled at every retry
+	// up to MaxBackoff, with a jitter. The Retry-After header of the API
+	// overrides it, up to MaxBackoff too.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is the policy of New
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	MinBackoff:  100 * time.Millisecond,
+	MaxBackoff:  5 * time.Second,
+}
+
+// WithRetry retries the requests of the client with policy, it wraps the
+// HTTP client of the options before it
+func WithRetry(policy RetryPolicy) ClientOption {
+	return func(c *Client) error {
+		c.Client = NewRetryDoer(c.Client, policy)
+		return nil
+	}
+}
+
+// NewRetryDoer sends the requests with doer, http.DefaultClient when nil,
+// retrying them according to policy
+func NewRetryDoer(doer HttpRequestDoer, policy RetryPolicy) HttpRequestDoer {
+	if doer == nil {
+		doer = http.DefaultClient
+	}
+	if retry, ok := doer.(*retryDoer); ok {
+		doer = retry.doer
+	}
+
+	return &retryDoer{doer: doer, policy: policy}
+}
+
+type retryDoer struct {
+	doer   HttpRequestDoer
+	policy RetryPolicy
+}
+
+func (d *retryDoer) Do(req *http.Request) (*http.Response, error) {
+	backoff := d.policy.MinBackoff
+	for attempt := 1; ; attempt++ {
+		resp, err := d.doer.Do(req)
+		if attempt >= d.policy.MaxAttempts || !retryable(req, resp, err) || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+
+		wait := jitter(backoff)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				wait = retryAfter
+			}
+
+			// The connection is reused once the body is read
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+			resp.Body.Close()
+		}
+		if wait > d.policy.MaxBackoff {
+			wait = d.policy.MaxBackoff
+		}
+
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		backoff *= 2
+		if backoff > d.policy.MaxBackoff {
+			backoff = d.policy.MaxBackoff
+		}
+	}
+}
+
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		// The caller gave up, the error is not the API's
+		if req.Context().Err() != nil {
+			return false
+		}
+
+		return req.Method == http.MethodGet || req.Method == http.MethodHead
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return req.Method == http.MethodGet || req.Method == http.MethodHead
+	default:
+		return false
+	}
+}
+
+// jitter is a random duration between half of backoff and backoff, so the
+// clients failing together do not retry together
+func jitter(backoff time.Duration) time.Duration {
+	if backoff <= 0 {
+		return 0
+	}
+
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// parseRetryAfter reads the seconds of a Retry-After header, the API does not
+// send HTTP dates
+func parseRetryAfter(value string) (time.Duration, bool) {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -1,35 +1,355 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 
+	"github.com/SawitProRecruitment/UserService/auth"
+	"github.com/SawitProRecruitment/UserService/cache"
+	"github.com/SawitProRecruitment/UserService/config"
 	"github.com/SawitProRecruitment/UserService/generated"
 	"github.com/SawitProRecruitment/UserService/handler"
+	"github.com/SawitProRecruitment/UserService/jobs"
+	"github.com/SawitProRecruitment/UserService/logging"
+	"github.com/SawitProRecruitment/UserService/metrics"
+	"github.com/SawitProRecruitment/UserService/models"
+	"github.com/SawitProRecruitment/UserService/outbox"
 	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tracing"
+	"github.com/SawitProRecruitment/UserService/webhooks"
 
 	"github.com/labstack/echo/v4"
-	"github.com/labstack/echo/v4/middleware"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func main() {
+	cfg, err := config.Load(os.Args[1:], os.LookupEnv)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if cfg.PrintConfig {
+		if err := cfg.Write(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	logger := logging.New(os.Stderr, logging.Options{
+		Level:  cfg.Log.Level,
+		Format: cfg.Log.Format,
+	})
+	slog.SetDefault(logger)
+
+	if len(cfg.Args) > 0 {
+		if cfg.Args[0] != "migrate" {
+			fmt.Fprintf(os.Stderr, "unknown command %q, expected migrate\n", cfg.Args[0])
+			os.Exit(2)
+		}
+
+		if err := runMigrate(cfg, cfg.Args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
 	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+
+	e.Server.ReadHeaderTimeout = cfg.Timeouts.ReadHeader
+	e.Server.ReadTimeout = cfg.Timeouts.Read
+	e.Server.WriteTimeout = cfg.Timeouts.Write
+	e.Server.IdleTimeout = cfg.Timeouts.Idle
+
+	// The otlp exporter sends the spans to the collector of
+	// OTEL_EXPORTER_OTLP_ENDPOINT, stdout writes them as JSON
+	tracerProvider, shutdownTracing, err := tracing.New(context.Background(), tracing.Options{
+		Exporter: cfg.Tracing.Exporter,
+	})
+	if err != nil {
+		fatal(logger, err)
+	}
+
+	m := metrics.New()
+	server, err := newServer(cfg, logger, m, tracerProvider)
+	if err != nil {
+		fatal(logger, err)
+	}
+
+	requestValidator, err := handler.RequestValidator()
+	if err != nil {
+		fatal(logger, err)
+	}
+
+	e.HTTPErrorHandler = server.ErrorHandler
+	e.Use(logging.Middleware(logger))
+	e.Use(m.Middleware())
+	e.Use(tracing.Middleware(tracerProvider))
 
-	var server generated.ServerInterface = newServer()
+	// The metrics and the probes are reached without credentials, only the
+	// API is authenticated and validated against api.yml
+	e.GET("/metrics", echo.WrapHandler(m.Handler()))
+	e.GET("/healthz", server.Healthz)
+	e.GET("/readyz", server.Readyz)
 
-	generated.RegisterHandlers(e, server)
-	e.Use(middleware.Logger())
-	e.Logger.Fatal(e.Start(":1323"))
+	api := &apiRouter{e: e}
+
+	if !cfg.Auth.Disabled {
+		api.Use(auth.Middleware(newAuthenticator(cfg.Auth)))
+	}
+
+	api.Use(requestValidator)
+	generated.RegisterHandlers(api, handler.NewTracedServer(server))
+
+	publisher, err := newPublisher(cfg.Outbox)
+	if err != nil {
+		fatal(logger, err)
+	}
+
+	// The webhooks are delivered and the outbox published in the background
+	// until the shutdown, what is left is sent by the next start
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	var background sync.WaitGroup
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		webhooks.NewDispatcher(webhooks.NewDispatcherOptions{
+			Repository:   server.Repository,
+			Timeout:      cfg.Webhooks.Timeout,
+			Logger:       logger,
+			Metrics:      m,
+			PollInterval: cfg.Webhooks.PollInterval,
+			MaxAttempts:  cfg.Webhooks.MaxAttempts,
+			Policy:       newWebhookPolicy(cfg.Webhooks),
+		}).Run(backgroundCtx)
+	}()
+
+	if publisher != nil {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			outbox.NewRelay(outbox.NewRelayOptions{
+				Repository:   server.Repository,
+				Publisher:    publisher,
+				Logger:       logger,
+				Metrics:      m,
+				PollInterval: cfg.Outbox.PollInterval,
+				BatchSize:    cfg.Outbox.BatchSize,
+			}).Run(backgroundCtx)
+		}()
+	}
+	backgroundDone := make(chan struct{})
+	go func() {
+		background.Wait()
+		close(backgroundDone)
+	}()
+
+	signals, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	started := make(chan error, 1)
+	go func() {
+		logger.Info("server started", "address", cfg.Listen)
+		started <- e.Start(cfg.Listen)
+	}()
+
+	select {
+	case err := <-started:
+		fatal(logger, err)
+	case <-signals.Done():
+	}
+
+	logger.Info("server shutting down", "timeout", cfg.Timeouts.Shutdown.String())
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeouts.Shutdown)
+	defer cancel()
+
+	// The drone plans may fly for long, they are cancelled first so their
+	// requests answer right away, then the requests in flight are drained
+	if err := server.Close(ctx); err != nil {
+		logger.Error("cancelling the drone plans", "error", err)
+	}
+	if err := e.Shutdown(ctx); err != nil {
+		logger.Error("draining the requests", "error", err)
+	}
+
+	stopBackground()
+	select {
+	case <-backgroundDone:
+	case <-ctx.Done():
+		logger.Error("draining the webhook deliveries and the outbox", "error", ctx.Err())
+	}
+	if publisher != nil {
+		if err := publisher.Close(); err != nil {
+			logger.Error("closing the outbox sink", "error", err)
+		}
+	}
+	if closer, ok := server.Repository.(io.Closer); ok {
+		closer.Close()
+	}
+
+	// Flushes the spans left in the batch
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Error("flushing the spans", "error", err)
+	}
+
+	logger.Info("server stopped")
 }
 
-func newServer() *handler.Server {
-	dbDsn := os.Getenv("DATABASE_URL")
+func fatal(logger *slog.Logger, err error) {
+	logger.Error("server stopped", "error", err)
+	os.Exit(1)
+}
 
-	var repo repository.RepositoryInterface = repository.NewRepository(repository.NewRepositoryOptions{
-		Dsn: dbDsn,
-	})
+// newAuthenticator accepts the API keys of the configuration and the HS256
+// JWTs signed with its secret, whose iss claim must be its issuer when set.
+func newAuthenticator(cfg config.Auth) *auth.Authenticator {
+	opts := auth.NewAuthenticatorOptions{
+		JWTSecret: []byte(cfg.JWTSecret),
+		JWTIssuer: cfg.JWTIssuer,
+	}
+
+	for _, key := range cfg.APIKeys {
+		opts.APIKeys = append(opts.APIKeys, auth.APIKey{
+			Key:            key.Key,
+			Name:           key.Organisation + " api key",
+			OrganisationID: key.Organisation,
+		})
+	}
+
+	return auth.NewAuthenticator(opts)
+}
+
+func newServer(cfg *config.Config, logger *slog.Logger, m *metrics.Metrics, tracerProvider trace.TracerProvider) (*handler.Server, error) {
+	repo, err := newRepository(cfg, m, tracerProvider)
+	if err != nil {
+		return nil, err
+	}
+	m.RegisterTotals(repo)
+
+	flightTrace, err := newFlightTrace(cfg.Log.FlightTrace)
+	if err != nil {
+		return nil, err
+	}
 
 	opts := handler.NewServerOptions{
 		Repository: repo,
+		YieldCurve: cfg.YieldCurve,
+		Jobs: jobs.NewPool(jobs.NewPoolOptions{
+			Workers:   cfg.Drone.Workers,
+			QueueSize: cfg.Drone.QueueSize,
+			Retention: cfg.Drone.JobRetention,
+		}),
+		PlanCache:          cache.NewLRU(cfg.Drone.PlanCacheSize),
+		Logger:             logger,
+		FlightTrace:        flightTrace,
+		Metrics:            m,
+		TracerProvider:     tracerProvider,
+		DefaultMaxDistance: cfg.Drone.MaxDistance,
+		WebhookPolicy:      newWebhookPolicy(cfg.Webhooks),
+	}
+	return handler.NewServer(opts), nil
+}
+
+// newWebhookPolicy is the addresses the webhooks may be sent to
+func newWebhookPolicy(cfg config.Webhooks) models.WebhookPolicy {
+	return models.WebhookPolicy{
+		AllowHTTP:            cfg.AllowHTTP,
+		AllowPrivateNetworks: cfg.AllowPrivateNetworks,
+	}
+}
+
+// newPublisher is the sink of the outbox, nil without one. The changes are
+// then kept in the outbox until a sink is configured.
+func newPublisher(cfg config.Outbox) (outbox.Publisher, error) {
+	switch cfg.Sink {
+	case "file":
+		return outbox.NewFilePublisher(cfg.File)
+	case "http":
+		return outbox.NewHTTPPublisher(outbox.NewHTTPPublisherOptions{
+			URL:   cfg.URL,
+			Token: cfg.Token,
+		}), nil
+	default:
+		return nil, nil
 	}
-	return handler.NewServer(opts)
+}
+
+// newFlightTrace writes every move of the drone plans to the file path as
+// JSON, or to stderr when it is "stderr". The flights are not traced by
+// default, the trace holds several records per plot.
+func newFlightTrace(path string) (*slog.Logger, error) {
+	switch path {
+	case "":
+		return nil, nil
+	case "stderr":
+		return logging.New(os.Stderr, logging.Options{}), nil
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("opening the flight trace: %w", err)
+	}
+
+	return logging.New(file, logging.Options{}), nil
+}
+
+// newRepository uses the database of the configuration, Postgres or SQLite
+// depending on its scheme, unless the storage is memory, which keeps
+// everything in memory so the API can run without a database.
+func newRepository(cfg *config.Config, m *metrics.Metrics, tracerProvider trace.TracerProvider) (repository.RepositoryInterface, error) {
+	if cfg.Storage == "memory" {
+		return repository.NewMemoryRepository(), nil
+	}
+
+	repo, err := newDatabaseRepository(cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+	repo.Db.AddQueryHook(m.QueryHook())
+	repo.Db.AddQueryHook(tracing.QueryHook(tracerProvider, repo.System()))
+	m.RegisterDBStats(repo.Db.DB)
+
+	// Without auto_migrate the schema is up to the migrate command
+	if cfg.Database.AutoMigrate {
+		if _, err := repo.Migrator().Up(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+
+	return repo, nil
+}
+
+// newDatabaseRepository connects to the database, the defaults of the
+// repository apply to the pool settings left to zero.
+func newDatabaseRepository(cfg config.Database) (*repository.Repository, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("database.url is required, set DATABASE_URL")
+	}
+
+	return repository.NewRepository(repository.NewRepositoryOptions{
+		Dsn:    cfg.URL,
+		Logger: slog.Default(),
+		Pool: repository.PoolOptions{
+			MaxOpenConns:    cfg.Pool.MaxOpenConns,
+			MaxIdleConns:    cfg.Pool.MaxIdleConns,
+			ConnMaxLifetime: cfg.Pool.ConnMaxLifetime,
+			ConnMaxIdleTime: cfg.Pool.ConnMaxIdleTime,
+		},
+	})
 }
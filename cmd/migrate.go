@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/config"
+)
+
+// runMigrate implements `main migrate [up | down [steps] | status]` on the
+// database of the configuration, up being the default.
+func runMigrate(cfg *config.Config, args []string) error {
+	repo, err := newDatabaseRepository(cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer repo.Db.Close()
+
+	ctx := context.Background()
+	migrator := repo.Migrator()
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Println("applied", migration)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no pending migration")
+		}
+
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+
+		reverted, err := migrator.Down(ctx, steps)
+		for _, migration := range reverted {
+			fmt.Println("reverted", migration)
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Println("no applied migration")
+		}
+
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, status := range statuses {
+			if status.Applied {
+				fmt.Printf("%s applied at %s\n", status.Migration, status.AppliedAt.Format(time.RFC3339))
+			} else {
+				fmt.Printf("%s pending\n", status.Migration)
+			}
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down or status", command)
+	}
+}
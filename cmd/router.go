@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// apiRouter registers the routes of api.yml with its middleware. Unlike the
+// middleware of an echo.Group, which also answers the unknown paths of the
+// group, it only runs on these routes, the other paths are still answered a
+// 404 or a 405 without credentials.
+type apiRouter struct {
+	e          *echo.Echo
+	middleware []echo.MiddlewareFunc
+}
+
+func (r *apiRouter) Use(middleware ...echo.MiddlewareFunc) {
+	r.middleware = append(r.middleware, middleware...)
+}
+
+func (r *apiRouter) add(method string, path string, h echo.HandlerFunc, m []echo.MiddlewareFunc) *echo.Route {
+	middleware := make([]echo.MiddlewareFunc, 0, len(r.middleware)+len(m))
+	middleware = append(middleware, r.middleware...)
+	return r.e.Add(method, path, h, append(middleware, m...)...)
+}
+
+func (r *apiRouter) CONNECT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(http.MethodConnect, path, h, m)
+}
+
+func (r *apiRouter) DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(http.MethodDelete, path, h, m)
+}
+
+func (r *apiRouter) GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(http.MethodGet, path, h, m)
+}
+
+func (r *apiRouter) HEAD(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(http.MethodHead, path, h, m)
+}
+
+func (r *apiRouter) OPTIONS(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(http.MethodOptions, path, h, m)
+}
+
+func (r *apiRouter) PATCH(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(http.MethodPatch, path, h, m)
+}
+
+func (r *apiRouter) POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(http.MethodPost, path, h, m)
+}
+
+func (r *apiRouter) PUT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(http.MethodPut, path, h, m)
+}
+
+func (r *apiRouter) TRACE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(http.MethodTrace, path, h, m)
+}
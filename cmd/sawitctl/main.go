@@ -0,0 +1,334 @@
+// Command sawitctl administers the estates of the API, and plans the drone
+// flights of an estate described by a CSV of trees without any server, so the
+// planners can try their what-if plans on their laptops.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/SawitProRecruitment/UserService/client"
+	"github.com/SawitProRecruitment/UserService/models"
+)
+
+const usage = `Usage: sawitctl [flags] <command> [flags]
+
+Commands on the API:
+  create-estate --width W --length L
+  add-tree --estate ID --x X --y Y --height H
+  import-trees --estate ID --file trees.csv
+  stats --estate ID
+  drone-plan --estate ID [--max-distance D]
+
+Commands on a CSV of trees, without the API:
+  stats --trees trees.csv --width W --length L
+  drone-plan --trees trees.csv --width W --length L [--max-distance D]
+
+The CSV holds one tree per line as x,y,height, optionally under a header, "-"
+reads it from stdin.
+
+Flags:
+  --api-url URL   API to call ($SAWIT_API_URL, http://localhost:1323)
+  --api-key KEY   API key of the organisation ($SAWIT_API_KEY)
+  --token JWT     JWT of the user, instead of an API key ($SAWIT_TOKEN)
+  --output FORMAT table or json (table)
+`
+
+func main() {
+	if err := run(context.Background(), os.Args[1:], os.Stdin, os.Stdout, os.Getenv); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(2)
+		}
+
+		fmt.Fprintln(os.Stderr, "sawitctl:", err)
+		os.Exit(1)
+	}
+}
+
+// app holds what the commands share, set from the flags before the command
+type app struct {
+	api    *client.EstateClient
+	out    *output
+	stdin  io.Reader
+}
+
+var commands = map[string]func(ctx context.Context, a *app, args []string) error{
+	"create-estate": createEstate,
+	"add-tree":      addTree,
+	"import-trees":  importTrees,
+	"stats":         stats,
+	"drone-plan":    dronePlan,
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, getenv func(string) string) error {
+	apiURL := getenv("SAWIT_API_URL")
+	if apiURL == "" {
+		apiURL = "http://localhost:1323"
+	}
+
+	fs := newFlagSet("sawitctl")
+	fs.StringVar(&apiURL, "api-url", apiURL, "")
+	apiKey := fs.String("api-key", getenv("SAWIT_API_KEY"), "")
+	token := fs.String("token", getenv("SAWIT_TOKEN"), "")
+	format := fs.String("output", "table", "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		return flag.ErrHelp
+	}
+	command, ok := commands[fs.Arg(0)]
+	if !ok {
+		return fmt.Errorf("unknown command %q, run sawitctl --help", fs.Arg(0))
+	}
+
+	out, err := newOutput(stdout, *format)
+	if err != nil {
+		return err
+	}
+
+	var opts []client.ClientOption
+	if *apiKey != "" {
+		opts = append(opts, client.WithAPIKey(*apiKey))
+	}
+	if *token != "" {
+		opts = append(opts, client.WithBearerToken(*token))
+	}
+	api, err := client.New(apiURL, opts...)
+	if err != nil {
+		return err
+	}
+
+	a := &app{
+		api:   api,
+		out:   out,
+		stdin: stdin,
+	}
+	return command(ctx, a, fs.Args()[1:])
+}
+
+// newFlagSet reports the errors of the flags to the caller only, the usage
+// is printed once by main
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+func createEstate(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("create-estate")
+	width := fs.Int("width", 0, "")
+	length := fs.Int("length", 0, "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	estate, err := a.api.CreateEstate(ctx, client.EstateRequest{Width: *width, Length: *length})
+	if err != nil {
+		return err
+	}
+
+	return a.out.print(estate, []string{"ID"}, []string{estate.Id})
+}
+
+func addTree(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("add-tree")
+	estateID := fs.String("estate", "", "")
+	x := fs.Int("x", 0, "")
+	y := fs.Int("y", 0, "")
+	height := fs.Int("height", 0, "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *estateID == "" {
+		return errors.New("--estate is required")
+	}
+
+	tree, err := a.api.AddTree(ctx, *estateID, client.TreeRequest{X: *x, Y: *y, Height: *height})
+	if err != nil {
+		return err
+	}
+
+	return a.out.print(tree, []string{"ID"}, []string{tree.Id})
+}
+
+// importResult is the outcome of the import of a tree of the CSV
+type importResult struct {
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Height int    `json:"height"`
+	ID     string `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// importTrees plants every tree of the CSV, a rejected tree does not stop
+// the import, the command fails once every tree was sent
+func importTrees(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("import-trees")
+	estateID := fs.String("estate", "", "")
+	file := fs.String("file", "", "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *estateID == "" || *file == "" {
+		return errors.New("--estate and --file are required")
+	}
+
+	trees, err := readTreesFile(*file, a.stdin)
+	if err != nil {
+		return err
+	}
+
+	results := make([]importResult, 0, len(trees))
+	rows := make([][]string, 0, len(trees))
+	failed := 0
+	for _, req := range trees {
+		result := importResult{X: req.X, Y: req.Y, Height: req.Height}
+
+		tree, err := a.api.AddTree(ctx, *estateID, req)
+		var apiErr *client.Error
+		switch {
+		case errors.As(err, &apiErr):
+			result.Error = apiErr.Error()
+			failed++
+		case err != nil:
+			// The API is unreachable, the next trees would fail the same way
+			return err
+		default:
+			result.ID = tree.Id
+		}
+
+		results = append(results, result)
+		rows = append(rows, []string{
+			strconv.Itoa(result.X), strconv.Itoa(result.Y), strconv.Itoa(result.Height), result.ID, result.Error,
+		})
+	}
+
+	if err := a.out.print(results, []string{"X", "Y", "HEIGHT", "ID", "ERROR"}, rows...); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d trees were rejected", failed, len(trees))
+	}
+
+	return nil
+}
+
+// estateFlags select an estate of the API with --estate, or an estate
+// planted with the trees of a CSV with --trees
+type estateFlags struct {
+	estateID string
+	trees    string
+	width    int
+	length   int
+}
+
+func (f *estateFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.estateID, "estate", "", "")
+	fs.StringVar(&f.trees, "trees", "", "")
+	fs.IntVar(&f.width, "width", 0, "")
+	fs.IntVar(&f.length, "length", 0, "")
+}
+
+func (f *estateFlags) offline() (bool, error) {
+	switch {
+	case f.estateID != "" && f.trees != "":
+		return false, errors.New("--estate and --trees are exclusive")
+	case f.estateID != "":
+		return false, nil
+	case f.trees != "":
+		return true, nil
+	default:
+		return false, errors.New("--estate or --trees is required")
+	}
+}
+
+// plant reads the trees of the CSV and plants them in an estate of the width
+// and the length of the flags
+func (f *estateFlags) plant(stdin io.Reader) (*models.Estate, []models.Tree, error) {
+	trees, err := readTreesFile(f.trees, stdin)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return plantEstate(f.width, f.length, trees)
+}
+
+func stats(ctx context.Context, a *app, args []string) error {
+	var estate estateFlags
+	fs := newFlagSet("stats")
+	estate.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	offline, err := estate.offline()
+	if err != nil {
+		return err
+	}
+
+	var resp *client.EstateStatsResponse
+	if offline {
+		local, _, err := estate.plant(a.stdin)
+		if err != nil {
+			return err
+		}
+		resp = statsResponse(local)
+	} else if resp, err = a.api.Stats(ctx, estate.estateID); err != nil {
+		return err
+	}
+
+	return a.out.print(resp, []string{"COUNT", "MIN", "MAX", "MEDIAN"}, []string{
+		strconv.FormatInt(resp.Count, 10), strconv.Itoa(resp.Min), strconv.Itoa(resp.Max), strconv.Itoa(resp.Median),
+	})
+}
+
+func dronePlan(ctx context.Context, a *app, args []string) error {
+	var estate estateFlags
+	fs := newFlagSet("drone-plan")
+	estate.register(fs)
+	maxDistance := fs.Int64("max-distance", 0, "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *maxDistance < 0 {
+		return errors.New("--max-distance must be positive")
+	}
+
+	offline, err := estate.offline()
+	if err != nil {
+		return err
+	}
+
+	var resp *client.DronePlanResponse
+	if offline {
+		local, trees, err := estate.plant(a.stdin)
+		if err != nil {
+			return err
+		}
+		resp = planDrone(local, trees, *maxDistance)
+	} else {
+		var params client.GetEstateIdDronePlanParams
+		if *maxDistance > 0 {
+			params.MaxDistance = maxDistance
+		}
+		if resp, err = a.api.DronePlan(ctx, estate.estateID, &params); err != nil {
+			return err
+		}
+	}
+
+	row := []string{strconv.FormatInt(resp.Distance, 10), "", ""}
+	if resp.Rest != nil && resp.Rest.X != nil && resp.Rest.Y != nil {
+		row[1] = strconv.Itoa(*resp.Rest.X)
+		row[2] = strconv.Itoa(*resp.Rest.Y)
+	}
+
+	return a.out.print(resp, []string{"DISTANCE", "REST_X", "REST_Y"}, row)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/client"
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/handler"
+	"github.com/SawitProRecruitment/UserService/logging"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The estate of the drone plan of the API tests, 5 plots long with 3 trees
+const treesCSV = `x,y,height
+2,1,10
+3,1,20
+4,1,10
+`
+
+func writeTrees(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "trees.csv")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// sawitctl runs the command against the API of apiURL and returns what it
+// printed
+func sawitctl(t *testing.T, apiURL string, args ...string) (string, error) {
+	var stdout bytes.Buffer
+	getenv := func(name string) string {
+		if name == "SAWIT_API_URL" {
+			return apiURL
+		}
+		return ""
+	}
+
+	err := run(context.Background(), args, strings.NewReader(treesCSV), &stdout, getenv)
+	return stdout.String(), err
+}
+
+// newAPI is the API storing everything in memory, without authentication
+func newAPI(t *testing.T) *httptest.Server {
+	server := handler.NewServer(handler.NewServerOptions{
+		Repository: repository.NewMemoryRepository(),
+		Logger:     logging.Discard(),
+	})
+
+	e := echo.New()
+	e.HTTPErrorHandler = server.ErrorHandler
+	generated.RegisterHandlers(e, server)
+
+	api := httptest.NewServer(e)
+	t.Cleanup(api.Close)
+	return api
+}
+
+func TestReadTrees(t *testing.T) {
+	trees, err := readTrees(strings.NewReader("2,1,10\n# comment\n3, 1, 20\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []client.TreeRequest{{X: 2, Y: 1, Height: 10}, {X: 3, Y: 1, Height: 20}}, trees)
+
+	// The columns of a header are taken by name
+	trees, err = readTrees(strings.NewReader("height,block,y,x\n10,A,1,2\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []client.TreeRequest{{X: 2, Y: 1, Height: 10}}, trees)
+
+	tests := []struct {
+		name  string
+		csv   string
+		error string
+	}{
+		{"missing column", "x,y\n1,1\n", "no height column"},
+		{"missing value", "1,1\n", "line 1: missing height"},
+		{"not a number", "1,1,10\n1,one,10\n", "line 2: y must be an integer"},
+		{"too tall", "1,1,31\n", "height must be an integer between 1 and 30"},
+		{"out of the API bounds", "0,1,10\n", "x must be an integer between 1 and 50000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readTrees(strings.NewReader(tt.csv))
+			assert.ErrorContains(t, err, tt.error)
+		})
+	}
+}
+
+func TestOffline(t *testing.T) {
+	path := writeTrees(t, treesCSV)
+
+	out, err := sawitctl(t, "", "stats", "--trees", path, "--width", "1", "--length", "5")
+	require.NoError(t, err)
+	assert.Equal(t, "COUNT  MIN  MAX  MEDIAN\n3      10   20   10\n", out)
+
+	out, err = sawitctl(t, "", "--output", "json", "drone-plan", "--trees", path, "--width", "1", "--length", "5")
+	require.NoError(t, err)
+	var plan client.DronePlanResponse
+	require.NoError(t, json.Unmarshal([]byte(out), &plan))
+	assert.Equal(t, int64(82), plan.Distance)
+	assert.Nil(t, plan.Rest)
+
+	// The trees from stdin, with a battery
+	out, err = sawitctl(t, "", "drone-plan", "--trees", "-", "--width", "1", "--length", "5", "--max-distance", "30")
+	require.NoError(t, err)
+	assert.Contains(t, out, "DISTANCE  REST_X  REST_Y\n")
+
+	_, err = sawitctl(t, "", "stats", "--trees", path, "--width", "1", "--length", "3")
+	assert.ErrorContains(t, err, "tree at 4,1: outside of boundaries")
+
+	_, err = sawitctl(t, "", "stats", "--trees", writeTrees(t, "1,1,10\n1,1,12\n"), "--width", "1", "--length", "5")
+	assert.ErrorContains(t, err, "tree at 1,1: tree already exist in that coordinate")
+
+	_, err = sawitctl(t, "", "stats", "--trees", path)
+	assert.ErrorContains(t, err, "width must be between 1 and 50000")
+}
+
+func TestAPI(t *testing.T) {
+	api := newAPI(t)
+
+	out, err := sawitctl(t, api.URL, "--output", "json", "create-estate", "--width", "1", "--length", "5")
+	require.NoError(t, err)
+	var estate client.EstateResponse
+	require.NoError(t, json.Unmarshal([]byte(out), &estate))
+
+	// A rejected tree does not stop the import
+	path := writeTrees(t, treesCSV+"9,1,10\n")
+	out, err = sawitctl(t, api.URL, "import-trees", "--estate", estate.Id, "--file", path)
+	assert.EqualError(t, err, "1 of 4 trees were rejected")
+	assert.Equal(t, 5, strings.Count(out, "\n"))
+	assert.Contains(t, out, "out_of_bounds: outside of boundaries")
+
+	// The API and the CSV plan the same flight
+	offline, err := sawitctl(t, "", "drone-plan", "--trees", writeTrees(t, treesCSV), "--width", "1", "--length", "5")
+	require.NoError(t, err)
+	out, err = sawitctl(t, api.URL, "drone-plan", "--estate", estate.Id)
+	require.NoError(t, err)
+	assert.Equal(t, offline, out)
+
+	out, err = sawitctl(t, api.URL, "stats", "--estate", estate.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "COUNT  MIN  MAX  MEDIAN\n3      10   20   10\n", out)
+
+	_, err = sawitctl(t, api.URL, "add-tree", "--estate", estate.Id, "--x", "2", "--y", "1", "--height", "5")
+	assert.ErrorContains(t, err, "duplicate_coordinate")
+}
+
+func TestRun_Errors(t *testing.T) {
+	_, err := sawitctl(t, "")
+	assert.ErrorIs(t, err, flag.ErrHelp)
+
+	_, err = sawitctl(t, "", "plant")
+	assert.ErrorContains(t, err, `unknown command "plant"`)
+
+	_, err = sawitctl(t, "", "--output", "yaml", "stats")
+	assert.ErrorContains(t, err, `unknown output "yaml"`)
+
+	_, err = sawitctl(t, "", "stats", "--estate", "a", "--trees", "b")
+	assert.ErrorContains(t, err, "exclusive")
+}
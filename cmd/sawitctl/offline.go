@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/SawitProRecruitment/UserService/client"
+	"github.com/SawitProRecruitment/UserService/models"
+)
+
+// plantEstate is an estate of width by length planted with trees the way the
+// API plants them, without any server or database. The trees out of the
+// estate or on the plot of another tree are rejected.
+func plantEstate(width int, length int, trees []client.TreeRequest) (*models.Estate, []models.Tree, error) {
+	estate, err := models.NewEstate(width, length)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	planted := make([]models.Tree, 0, len(trees))
+	plots := make(map[[2]int]bool, len(trees))
+	for _, req := range trees {
+		plot := [2]int{req.X, req.Y}
+		if plots[plot] {
+			return nil, nil, fmt.Errorf("tree at %d,%d: %w", req.X, req.Y, models.ErrDuplicateCoordinate)
+		}
+		plots[plot] = true
+
+		tree, err := models.NewTree(estate, req.X, req.Y, req.Height)
+		if err != nil {
+			return nil, nil, fmt.Errorf("tree at %d,%d: %w", req.X, req.Y, err)
+		}
+		planted = append(planted, *tree)
+	}
+
+	// The stats of the API are kept up to date tree by tree, they are exact
+	// here since every tree is known
+	if err := estate.RecalculateEstateTreeStats(&planted); err != nil {
+		return nil, nil, err
+	}
+
+	return estate, planted, nil
+}
+
+func statsResponse(estate *models.Estate) *client.EstateStatsResponse {
+	return &client.EstateStatsResponse{
+		Count:  int64(estate.TreeCount),
+		Max:    int(estate.MaxTreeHeight),
+		Min:    int(estate.MinTreeHeight),
+		Median: int(estate.MedianTreeHeight),
+	}
+}
+
+// planDrone flies the drone over the estate, with a battery of maxDistance
+// when positive, and answers as GET /estate/{id}/drone-plan
+func planDrone(estate *models.Estate, trees []models.Tree, maxDistance int64) *client.DronePlanResponse {
+	var battery *uint64
+	if maxDistance > 0 {
+		distance := uint64(maxDistance)
+		battery = &distance
+	}
+
+	drone := models.NewDrone(estate, &trees, battery)
+	drone.CalculateFlight()
+
+	resp := client.DronePlanResponse{Distance: int64(drone.Travelled)}
+	if battery != nil {
+		x := int(drone.LastCoordinateX)
+		y := int(drone.LastCoordinateY)
+		resp.Rest = &client.DroneRestResponse{X: &x, Y: &y}
+	}
+
+	return &resp
+}
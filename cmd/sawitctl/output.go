@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// output prints the results as an aligned table, or as the JSON the API
+// answers so they can be piped to jq
+type output struct {
+	w    io.Writer
+	json bool
+}
+
+func newOutput(w io.Writer, format string) (*output, error) {
+	switch format {
+	case "table", "":
+		return &output{w: w}, nil
+	case "json":
+		return &output{w: w, json: true}, nil
+	default:
+		return nil, fmt.Errorf("unknown output %q, expected table or json", format)
+	}
+}
+
+// print writes value as JSON, or the rows under header as a table
+func (o *output) print(value any, header []string, rows ...[]string) error {
+	if o.json {
+		encoder := json.NewEncoder(o.w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+
+	tw := tabwriter.NewWriter(o.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
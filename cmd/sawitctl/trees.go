@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/SawitProRecruitment/UserService/client"
+)
+
+// The bounds of the API, checked before anything is sent or planned
+const (
+	maxDimension  = 50000
+	maxTreeHeight = 30
+)
+
+// readTreesFile reads the trees of a CSV file, or of stdin when path is "-"
+func readTreesFile(path string, stdin io.Reader) ([]client.TreeRequest, error) {
+	if path == "-" {
+		return readTrees(stdin)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	trees, err := readTrees(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return trees, nil
+}
+
+// readTrees reads one tree per line as x,y,height. A header naming the x, y
+// and height columns may come first, the columns are then taken by name and
+// the other columns are ignored.
+func readTrees(r io.Reader) ([]client.TreeRequest, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	columns := map[string]int{"x": 0, "y": 1, "height": 2}
+	var trees []client.TreeRequest
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return trees, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if line == 1 && isHeader(record) {
+			columns, err = headerColumns(record)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		tree, err := parseTree(record, columns)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		trees = append(trees, tree)
+	}
+}
+
+func isHeader(record []string) bool {
+	for _, field := range record {
+		if _, err := strconv.Atoi(strings.TrimSpace(field)); err == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func headerColumns(record []string) (map[string]int, error) {
+	columns := make(map[string]int)
+	for i, field := range record {
+		columns[strings.ToLower(strings.TrimSpace(field))] = i
+	}
+
+	for _, name := range []string{"x", "y", "height"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("the header has no %s column", name)
+		}
+	}
+
+	return columns, nil
+}
+
+func parseTree(record []string, columns map[string]int) (client.TreeRequest, error) {
+	value := func(name string, max int) (int, error) {
+		i := columns[name]
+		if i >= len(record) {
+			return 0, fmt.Errorf("missing %s", name)
+		}
+
+		parsed, err := strconv.Atoi(strings.TrimSpace(record[i]))
+		if err != nil || parsed < 1 || parsed > max {
+			return 0, fmt.Errorf("%s must be an integer between 1 and %d, not %q", name, max, record[i])
+		}
+
+		return parsed, nil
+	}
+
+	var tree client.TreeRequest
+	var err error
+	if tree.X, err = value("x", maxDimension); err != nil {
+		return tree, err
+	}
+	if tree.Y, err = value("y", maxDimension); err != nil {
+		return tree, err
+	}
+	if tree.Height, err = value("height", maxTreeHeight); err != nil {
+		return tree, err
+	}
+
+	return tree, nil
+}
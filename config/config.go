@@ -0,0 +1,268 @@
+// This file contains the configuration of the server, loaded from an
+// optional YAML file, the environment and the command line flags, in that
+// order of precedence.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/models"
+)
+
+type Config struct {
+	// Listen is the address the API is served on
+	Listen   string   `yaml:"listen"`
+	Timeouts Timeouts `yaml:"timeouts"`
+	// Storage is database, or memory to run without a database for tests
+	// and demos, the memory storage does not isolate its transactions
+	Storage  string   `yaml:"storage"`
+	Database Database `yaml:"database"`
+	Log      Log      `yaml:"log"`
+	Auth     Auth     `yaml:"auth"`
+	Drone    Drone    `yaml:"drone"`
+	Tracing  Tracing  `yaml:"tracing"`
+	Webhooks Webhooks `yaml:"webhooks"`
+	Outbox   Outbox   `yaml:"outbox"`
+	// YieldCurve estimates the yield forecasts, the default curve of the
+	// models when empty. It is only set by the file.
+	YieldCurve models.YieldCurve `yaml:"yield_curve"`
+
+	// PrintConfig is set by --print-config, the binary then prints the
+	// configuration and exits
+	PrintConfig bool `yaml:"-"`
+	// Args are the arguments left after the flags, the commands of the
+	// binary such as migrate
+	Args []string `yaml:"-"`
+}
+
+type Timeouts struct {
+	ReadHeader time.Duration `yaml:"read_header"`
+	Read       time.Duration `yaml:"read"`
+	Write      time.Duration `yaml:"write"`
+	Idle       time.Duration `yaml:"idle"`
+	// Shutdown is how long the requests in flight are drained on SIGTERM
+	Shutdown time.Duration `yaml:"shutdown"`
+}
+
+type Database struct {
+	// URL is a Postgres URL, or a SQLite database with sqlite:// or file:
+	URL string `yaml:"url"`
+	// AutoMigrate migrates the schema on startup, the migrate command does
+	// it otherwise
+	AutoMigrate bool `yaml:"auto_migrate"`
+	Pool        Pool `yaml:"pool"`
+}
+
+// Pool is left to the defaults of the repository when zero
+type Pool struct {
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+}
+
+type Log struct {
+	// Level is debug, info, warn or error
+	Level string `yaml:"level"`
+	// Format is json or text
+	Format string `yaml:"format"`
+	// FlightTrace is a file receiving the moves of the drone plans, or
+	// stderr, they are not traced when empty
+	FlightTrace string `yaml:"flight_trace"`
+}
+
+type Auth struct {
+	// Disabled runs the API without authentication for local demos, every
+	// estate then belongs to the same empty organisation
+	Disabled  bool     `yaml:"disabled"`
+	APIKeys   []APIKey `yaml:"api_keys"`
+	JWTSecret string   `yaml:"jwt_secret"`
+	// JWTIssuer is the iss claim required from the JWTs when set
+	JWTIssuer string `yaml:"jwt_issuer"`
+}
+
+type APIKey struct {
+	Organisation string `yaml:"organisation"`
+	Key          string `yaml:"key"`
+}
+
+type Drone struct {
+	// MaxDistance is the battery of the drone when the request gives no
+	// max_distance, the battery never drains when zero
+	MaxDistance uint64 `yaml:"max_distance"`
+	// Workers compute the drone plan jobs, one per CPU when zero
+	Workers      int           `yaml:"workers"`
+	QueueSize    int           `yaml:"queue_size"`
+	JobRetention time.Duration `yaml:"job_retention"`
+	// PlanCacheSize is the number of drone plans kept in memory
+	PlanCacheSize int `yaml:"plan_cache_size"`
+}
+
+type Tracing struct {
+	// Exporter is otlp, stdout or none
+	Exporter string `yaml:"exporter"`
+}
+
+type Webhooks struct {
+	// PollInterval is how often the due deliveries are sent
+	PollInterval time.Duration `yaml:"poll_interval"`
+	// MaxAttempts is the number of attempts before a delivery is abandoned
+	MaxAttempts int `yaml:"max_attempts"`
+	// Timeout bounds an attempt
+	Timeout time.Duration `yaml:"timeout"`
+	// AllowHTTP accepts the http URLs, the webhooks must be https otherwise
+	AllowHTTP bool `yaml:"allow_http"`
+	// AllowPrivateNetworks lets the webhooks reach the loopback, private and
+	// link-local addresses, which the tenants could otherwise use to reach
+	// the services next to the server
+	AllowPrivateNetworks bool `yaml:"allow_private_networks"`
+}
+
+type Outbox struct {
+	// Sink receives the changes of the estates: file, http, or none to keep
+	// them in the outbox
+	Sink string `yaml:"sink"`
+	// File is appended the changes with the file sink
+	File string `yaml:"file"`
+	// URL is POSTed the changes with the http sink
+	URL string `yaml:"url"`
+	// Token is sent as a bearer token with the http sink when set
+	Token        string        `yaml:"token"`
+	PollInterval time.Duration `yaml:"poll_interval"`
+	BatchSize    int           `yaml:"batch_size"`
+}
+
+// Default is the configuration before any file, variable or flag
+func Default() *Config {
+	return &Config{
+		Listen: ":1323",
+		Timeouts: Timeouts{
+			ReadHeader: 10 * time.Second,
+			Read:       time.Minute,
+			Write:      2 * time.Minute,
+			Idle:       2 * time.Minute,
+			Shutdown:   25 * time.Second,
+		},
+		Storage: "database",
+		Database: Database{
+			AutoMigrate: true,
+		},
+		Log: Log{
+			Level:  "info",
+			Format: "json",
+		},
+		Drone: Drone{
+			QueueSize:     100,
+			JobRetention:  time.Hour,
+			PlanCacheSize: 1000,
+		},
+		Tracing: Tracing{
+			Exporter: "none",
+		},
+		Webhooks: Webhooks{
+			PollInterval: time.Second,
+			MaxAttempts:  10,
+			Timeout:      10 * time.Second,
+		},
+		Outbox: Outbox{
+			Sink:         "none",
+			PollInterval: time.Second,
+			BatchSize:    100,
+		},
+	}
+}
+
+// Validate reports every invalid setting at once
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Listen != "", "listen must not be empty")
+	check(c.Timeouts.ReadHeader >= 0 && c.Timeouts.Read >= 0 && c.Timeouts.Write >= 0 && c.Timeouts.Idle >= 0,
+		"timeouts must not be negative")
+	check(c.Timeouts.Shutdown > 0, "timeouts.shutdown must be positive")
+
+	check(c.Storage == "database" || c.Storage == "memory", "storage must be database or memory, not %q", c.Storage)
+	if c.Storage == "database" {
+		check(c.Database.URL != "", "database.url is required with the database storage")
+	}
+	check(c.Database.Pool.MaxOpenConns >= 0 && c.Database.Pool.MaxIdleConns >= 0 &&
+		c.Database.Pool.ConnMaxLifetime >= 0 && c.Database.Pool.ConnMaxIdleTime >= 0,
+		"database.pool settings must not be negative")
+
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.Log.Level)) == nil, "log.level must be debug, info, warn or error, not %q", c.Log.Level)
+	check(c.Log.Format == "json" || c.Log.Format == "text", "log.format must be json or text, not %q", c.Log.Format)
+
+	if !c.Auth.Disabled {
+		check(len(c.Auth.APIKeys) > 0 || c.Auth.JWTSecret != "",
+			"no credentials accepted, set auth.api_keys or auth.jwt_secret, or auth.disabled")
+	}
+	for i, key := range c.Auth.APIKeys {
+		check(key.Organisation != "" && key.Key != "", "auth.api_keys[%d] needs an organisation and a key", i)
+	}
+
+	check(c.Drone.Workers >= 0 && c.Drone.QueueSize >= 0 && c.Drone.JobRetention >= 0 && c.Drone.PlanCacheSize >= 0,
+		"drone settings must not be negative")
+
+	exporter := strings.ToLower(c.Tracing.Exporter)
+	check(exporter == "none" || exporter == "otlp" || exporter == "stdout",
+		"tracing.exporter must be otlp, stdout or none, not %q", c.Tracing.Exporter)
+
+	check(c.Webhooks.PollInterval > 0 && c.Webhooks.MaxAttempts > 0 && c.Webhooks.Timeout > 0,
+		"webhooks settings must be positive")
+
+	check(c.Outbox.Sink == "none" || c.Outbox.Sink == "file" || c.Outbox.Sink == "http",
+		"outbox.sink must be file, http or none, not %q", c.Outbox.Sink)
+	if c.Outbox.Sink == "file" {
+		check(c.Outbox.File != "", "outbox.file is required with the file sink")
+	}
+	if c.Outbox.Sink == "http" {
+		u, err := url.Parse(c.Outbox.URL)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"outbox.url must be an http or https URL with the http sink")
+	}
+	check(c.Outbox.PollInterval > 0 && c.Outbox.BatchSize > 0, "outbox settings must be positive")
+
+	if len(c.YieldCurve) > 0 {
+		err := c.YieldCurve.Validate()
+		check(err == nil, "yield_curve is invalid: %v", err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Redacted is a copy of the configuration without its secrets, to be printed
+func (c *Config) Redacted() *Config {
+	redacted := *c
+
+	if u, err := url.Parse(c.Database.URL); err == nil {
+		redacted.Database.URL = u.Redacted()
+	}
+
+	redacted.Auth.APIKeys = make([]APIKey, len(c.Auth.APIKeys))
+	for i, key := range c.Auth.APIKeys {
+		redacted.Auth.APIKeys[i] = APIKey{Organisation: key.Organisation, Key: redact(key.Key)}
+	}
+	redacted.Auth.JWTSecret = redact(c.Auth.JWTSecret)
+	redacted.Outbox.Token = redact(c.Outbox.Token)
+
+	return &redacted
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+
+	return "xxxxx"
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func env(values map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := values[name]
+		return value, ok
+	}
+}
+
+func writeFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	c, err := Load(nil, env(nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, Default(), c)
+	assert.Equal(t, ":1323", c.Listen)
+	assert.True(t, c.Database.AutoMigrate)
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, `
+listen: ":8080"
+storage: memory
+log:
+  level: debug
+  format: text
+drone:
+  max_distance: 500
+  job_retention: 10m
+yield_curve:
+  - name: bearing
+    min_height: 3
+    max_height: 30
+    tonnes_per_tree: 0.15
+`)
+
+	c, err := Load(
+		[]string{"--config", path, "--log-level", "error", "--auth-disabled"},
+		env(map[string]string{
+			"LOG_LEVEL":          "warn",
+			"LOG_FORMAT":         "json",
+			"DRONE_MAX_DISTANCE": "",
+			"API_KEYS":           "estate-co-a:key-a, estate-co-b:key-b",
+			"WEBHOOK_TIMEOUT":    "3s",
+			"WEBHOOK_ALLOW_HTTP": "true",
+		}),
+	)
+	require.NoError(t, err)
+
+	// The file over the defaults
+	assert.Equal(t, ":8080", c.Listen)
+	assert.Equal(t, "memory", c.Storage)
+	assert.Equal(t, 10*time.Minute, c.Drone.JobRetention)
+	assert.Equal(t, 25*time.Second, c.Timeouts.Shutdown)
+	assert.Equal(t, models.YieldCurve{{Name: "bearing", MinHeight: 3, MaxHeight: 30, TonnesPerTree: 0.15}}, c.YieldCurve)
+	// The environment over the file, unless empty
+	assert.Equal(t, "json", c.Log.Format)
+	assert.Equal(t, uint64(500), c.Drone.MaxDistance)
+	assert.Equal(t, []APIKey{{"estate-co-a", "key-a"}, {"estate-co-b", "key-b"}}, c.Auth.APIKeys)
+	assert.Equal(t, 3*time.Second, c.Webhooks.Timeout)
+	assert.True(t, c.Webhooks.AllowHTTP)
+	assert.False(t, c.Webhooks.AllowPrivateNetworks)
+	// The flags over the environment
+	assert.Equal(t, "error", c.Log.Level)
+	assert.True(t, c.Auth.Disabled)
+}
+
+func TestLoad_ConfigFileFromEnv(t *testing.T) {
+	path := writeFile(t, "storage: memory\n")
+
+	c, err := Load(nil, env(map[string]string{"CONFIG_FILE": path}))
+	require.NoError(t, err)
+	assert.Equal(t, "memory", c.Storage)
+}
+
+func TestLoad_Args(t *testing.T) {
+	c, err := Load([]string{"--database-url", "sqlite://./sawit.db", "migrate", "down", "2"}, env(nil))
+	require.NoError(t, err)
+	assert.Equal(t, "sqlite://./sawit.db", c.Database.URL)
+	assert.Equal(t, []string{"migrate", "down", "2"}, c.Args)
+
+	c, err = Load([]string{"--print-config", "--auto-migrate=false"}, env(nil))
+	require.NoError(t, err)
+	assert.True(t, c.PrintConfig)
+	assert.False(t, c.Database.AutoMigrate)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+	}{
+		{name: "unknown flag", args: []string{"--port", "80"}},
+		{name: "invalid duration", env: map[string]string{"SHUTDOWN_TIMEOUT": "25"}},
+		{name: "invalid integer", args: []string{"--db-max-open-conns", "many"}},
+		{name: "invalid boolean", env: map[string]string{"AUTH_DISABLED": "yes please"}},
+		{name: "invalid api keys", env: map[string]string{"API_KEYS": "key-without-organisation"}},
+		{name: "missing file", args: []string{"--config", "missing.yml"}},
+		{name: "unknown setting", args: []string{"--config", writeFile(t, "listen_address: :80\n")}},
+		{name: "help", args: []string{"--help"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.args, env(tt.env))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *Config {
+		c := Default()
+		c.Database.URL = "postgres://localhost/database"
+		c.Auth.JWTSecret = "secret"
+		return c
+	}
+	assert.NoError(t, valid().Validate())
+
+	c := Default()
+	c.Storage = "memory"
+	c.Auth.Disabled = true
+	assert.NoError(t, c.Validate())
+
+	tests := []struct {
+		name   string
+		mutate func(c *Config)
+		error  string
+	}{
+		{"empty listen", func(c *Config) { c.Listen = "" }, "listen must not be empty"},
+		{"no shutdown timeout", func(c *Config) { c.Timeouts.Shutdown = 0 }, "timeouts.shutdown must be positive"},
+		{"unknown storage", func(c *Config) { c.Storage = "redis" }, `storage must be database or memory, not "redis"`},
+		{"no database", func(c *Config) { c.Database.URL = "" }, "database.url is required"},
+		{"negative pool", func(c *Config) { c.Database.Pool.MaxOpenConns = -1 }, "database.pool"},
+		{"unknown level", func(c *Config) { c.Log.Level = "verbose" }, "log.level"},
+		{"unknown format", func(c *Config) { c.Log.Format = "xml" }, "log.format"},
+		{"no credentials", func(c *Config) { c.Auth.JWTSecret = "" }, "no credentials accepted"},
+		{"incomplete key", func(c *Config) { c.Auth.APIKeys = []APIKey{{Organisation: "estate-co-a"}} }, "auth.api_keys[0]"},
+		{"negative workers", func(c *Config) { c.Drone.Workers = -1 }, "drone settings"},
+		{"unknown exporter", func(c *Config) { c.Tracing.Exporter = "zipkin" }, "tracing.exporter"},
+		{"no webhook attempts", func(c *Config) { c.Webhooks.MaxAttempts = 0 }, "webhooks settings"},
+		{"unknown sink", func(c *Config) { c.Outbox.Sink = "kafka" }, "outbox.sink"},
+		{"file sink without file", func(c *Config) { c.Outbox.Sink = "file" }, "outbox.file"},
+		{"http sink without url", func(c *Config) { c.Outbox.Sink = "http"; c.Outbox.URL = "warehouse" }, "outbox.url"},
+		{"overlapping yield bands", func(c *Config) {
+			c.YieldCurve = models.YieldCurve{{MinHeight: 1, MaxHeight: 5}, {MinHeight: 5, MaxHeight: 10}}
+		}, "yield_curve is invalid: yield bands are overlapping"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid()
+			tt.mutate(c)
+			assert.ErrorContains(t, c.Validate(), tt.error)
+		})
+	}
+
+	// Every error at once
+	c = valid()
+	c.Listen = ""
+	c.Log.Format = "xml"
+	err := c.Validate()
+	assert.ErrorContains(t, err, "listen")
+	assert.ErrorContains(t, err, "log.format")
+}
+
+func TestWrite(t *testing.T) {
+	c := Default()
+	c.Database.URL = "postgres://postgres:password@db:5432/database?sslmode=disable"
+	c.Auth.APIKeys = []APIKey{{Organisation: "estate-co-a", Key: "key-a"}}
+	c.Auth.JWTSecret = "signing-secret"
+	c.Outbox.Token = "warehouse-token"
+
+	var buf bytes.Buffer
+	require.NoError(t, c.Write(&buf))
+
+	assert.NotContains(t, buf.String(), "password")
+	assert.NotContains(t, buf.String(), "key-a")
+	assert.NotContains(t, buf.String(), "signing-secret")
+	assert.NotContains(t, buf.String(), "warehouse-token")
+	assert.Contains(t, buf.String(), "shutdown: 25s")
+	// The secrets of the configuration are left untouched
+	assert.Equal(t, "key-a", c.Auth.APIKeys[0].Key)
+
+	// The output is a configuration file
+	path := writeFile(t, buf.String())
+	read, err := Load([]string{"--config", path}, env(nil))
+	require.NoError(t, err)
+	assert.Equal(t, c.Timeouts, read.Timeouts)
+	assert.Equal(t, "estate-co-a", read.Auth.APIKeys[0].Organisation)
+}
+
+func TestLoad_Example(t *testing.T) {
+	c, err := Load([]string{"--config", "../config.example.yml"}, env(nil))
+	require.NoError(t, err)
+
+	// The example documents the defaults
+	c.Args = nil
+	assert.Equal(t, Default(), c)
+}
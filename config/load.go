@@ -0,0 +1,274 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// setting is a value of the configuration set from the environment variable
+// env and the flag of the same name, --flag
+type setting struct {
+	flag  string
+	env   string
+	usage string
+	// boolean flags are set without a value
+	boolean bool
+	set     func(c *Config, value string) error
+}
+
+var settings = []setting{
+	{flag: "listen", env: "LISTEN_ADDRESS", usage: "address the API is served on",
+		set: func(c *Config, v string) error { c.Listen = v; return nil }},
+	{flag: "read-header-timeout", env: "READ_HEADER_TIMEOUT", usage: "time to read the headers of a request",
+		set: func(c *Config, v string) error { return setDuration(&c.Timeouts.ReadHeader, v) }},
+	{flag: "read-timeout", env: "READ_TIMEOUT", usage: "time to read a request",
+		set: func(c *Config, v string) error { return setDuration(&c.Timeouts.Read, v) }},
+	{flag: "write-timeout", env: "WRITE_TIMEOUT", usage: "time to write a response",
+		set: func(c *Config, v string) error { return setDuration(&c.Timeouts.Write, v) }},
+	{flag: "idle-timeout", env: "IDLE_TIMEOUT", usage: "time a keep-alive connection waits for the next request",
+		set: func(c *Config, v string) error { return setDuration(&c.Timeouts.Idle, v) }},
+	{flag: "shutdown-timeout", env: "SHUTDOWN_TIMEOUT", usage: "time the requests in flight are drained on SIGTERM",
+		set: func(c *Config, v string) error { return setDuration(&c.Timeouts.Shutdown, v) }},
+
+	{flag: "storage", env: "STORAGE", usage: "database, or memory to run without a database",
+		set: func(c *Config, v string) error { c.Storage = v; return nil }},
+	{flag: "database-url", env: "DATABASE_URL", usage: "Postgres URL, or SQLite database with sqlite:// or file:",
+		set: func(c *Config, v string) error { c.Database.URL = v; return nil }},
+	{flag: "auto-migrate", env: "AUTO_MIGRATE", usage: "migrate the schema on startup", boolean: true,
+		set: func(c *Config, v string) error { return setBool(&c.Database.AutoMigrate, v) }},
+	{flag: "db-max-open-conns", env: "DB_MAX_OPEN_CONNS", usage: "connections open to Postgres at most",
+		set: func(c *Config, v string) error { return setInt(&c.Database.Pool.MaxOpenConns, v) }},
+	{flag: "db-max-idle-conns", env: "DB_MAX_IDLE_CONNS", usage: "idle connections kept open to Postgres",
+		set: func(c *Config, v string) error { return setInt(&c.Database.Pool.MaxIdleConns, v) }},
+	{flag: "db-conn-max-lifetime", env: "DB_CONN_MAX_LIFETIME", usage: "time a connection to Postgres is reused",
+		set: func(c *Config, v string) error { return setDuration(&c.Database.Pool.ConnMaxLifetime, v) }},
+	{flag: "db-conn-max-idle-time", env: "DB_CONN_MAX_IDLE_TIME", usage: "time an idle connection to Postgres is kept",
+		set: func(c *Config, v string) error { return setDuration(&c.Database.Pool.ConnMaxIdleTime, v) }},
+
+	{flag: "log-level", env: "LOG_LEVEL", usage: "debug, info, warn or error",
+		set: func(c *Config, v string) error { c.Log.Level = v; return nil }},
+	{flag: "log-format", env: "LOG_FORMAT", usage: "json or text",
+		set: func(c *Config, v string) error { c.Log.Format = v; return nil }},
+	{flag: "flight-trace", env: "FLIGHT_TRACE", usage: "file receiving the moves of the drone plans, or stderr",
+		set: func(c *Config, v string) error { c.Log.FlightTrace = v; return nil }},
+
+	{flag: "auth-disabled", env: "AUTH_DISABLED", usage: "run the API without authentication", boolean: true,
+		set: func(c *Config, v string) error { return setBool(&c.Auth.Disabled, v) }},
+	{flag: "api-keys", env: "API_KEYS", usage: "comma separated list of organisation:key",
+		set: func(c *Config, v string) error { return setAPIKeys(&c.Auth.APIKeys, v) }},
+	{flag: "jwt-secret", env: "JWT_SECRET", usage: "secret signing the HS256 JWTs",
+		set: func(c *Config, v string) error { c.Auth.JWTSecret = v; return nil }},
+	{flag: "jwt-issuer", env: "JWT_ISSUER", usage: "iss claim required from the JWTs",
+		set: func(c *Config, v string) error { c.Auth.JWTIssuer = v; return nil }},
+
+	{flag: "drone-max-distance", env: "DRONE_MAX_DISTANCE", usage: "battery of the drone when the request gives none, 0 never drains",
+		set: func(c *Config, v string) error { return setUint(&c.Drone.MaxDistance, v) }},
+	{flag: "drone-workers", env: "DRONE_WORKERS", usage: "drone plan jobs computed at the same time, 0 for one per CPU",
+		set: func(c *Config, v string) error { return setInt(&c.Drone.Workers, v) }},
+	{flag: "drone-queue-size", env: "DRONE_QUEUE_SIZE", usage: "drone plan jobs waiting for a worker",
+		set: func(c *Config, v string) error { return setInt(&c.Drone.QueueSize, v) }},
+	{flag: "drone-job-retention", env: "DRONE_JOB_RETENTION", usage: "time a finished drone plan job is kept",
+		set: func(c *Config, v string) error { return setDuration(&c.Drone.JobRetention, v) }},
+	{flag: "drone-plan-cache-size", env: "DRONE_PLAN_CACHE_SIZE", usage: "drone plans kept in memory",
+		set: func(c *Config, v string) error { return setInt(&c.Drone.PlanCacheSize, v) }},
+
+	{flag: "trace-exporter", env: "TRACE_EXPORTER", usage: "otlp, stdout or none",
+		set: func(c *Config, v string) error { c.Tracing.Exporter = v; return nil }},
+
+	{flag: "webhook-poll-interval", env: "WEBHOOK_POLL_INTERVAL", usage: "how often the due webhook deliveries are sent",
+		set: func(c *Config, v string) error { return setDuration(&c.Webhooks.PollInterval, v) }},
+	{flag: "webhook-max-attempts", env: "WEBHOOK_MAX_ATTEMPTS", usage: "attempts before a webhook delivery is abandoned",
+		set: func(c *Config, v string) error { return setInt(&c.Webhooks.MaxAttempts, v) }},
+	{flag: "webhook-timeout", env: "WEBHOOK_TIMEOUT", usage: "time an attempt to deliver a webhook is given",
+		set: func(c *Config, v string) error { return setDuration(&c.Webhooks.Timeout, v) }},
+	{flag: "webhook-allow-http", env: "WEBHOOK_ALLOW_HTTP", usage: "accept the http webhook URLs, not only the https ones", boolean: true,
+		set: func(c *Config, v string) error { return setBool(&c.Webhooks.AllowHTTP, v) }},
+	{flag: "webhook-allow-private-networks", env: "WEBHOOK_ALLOW_PRIVATE_NETWORKS", usage: "let the webhooks reach the loopback, private and link-local addresses", boolean: true,
+		set: func(c *Config, v string) error { return setBool(&c.Webhooks.AllowPrivateNetworks, v) }},
+
+	{flag: "outbox-sink", env: "OUTBOX_SINK", usage: "file, http or none, where the changes of the estates are published",
+		set: func(c *Config, v string) error { c.Outbox.Sink = v; return nil }},
+	{flag: "outbox-file", env: "OUTBOX_FILE", usage: "file appended the changes with the file sink",
+		set: func(c *Config, v string) error { c.Outbox.File = v; return nil }},
+	{flag: "outbox-url", env: "OUTBOX_URL", usage: "URL POSTed the changes with the http sink",
+		set: func(c *Config, v string) error { c.Outbox.URL = v; return nil }},
+	{flag: "outbox-token", env: "OUTBOX_TOKEN", usage: "bearer token sent with the http sink",
+		set: func(c *Config, v string) error { c.Outbox.Token = v; return nil }},
+	{flag: "outbox-poll-interval", env: "OUTBOX_POLL_INTERVAL", usage: "how often the outbox is published",
+		set: func(c *Config, v string) error { return setDuration(&c.Outbox.PollInterval, v) }},
+	{flag: "outbox-batch-size", env: "OUTBOX_BATCH_SIZE", usage: "changes published together",
+		set: func(c *Config, v string) error { return setInt(&c.Outbox.BatchSize, v) }},
+}
+
+// Load reads the configuration, the defaults are overridden by the YAML file
+// of --config or CONFIG_FILE if any, then by the environment, then by the
+// flags of args. lookupEnv is os.LookupEnv outside of the tests. The
+// configuration is not validated, so that --print-config shows it anyway.
+func Load(args []string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	c := Default()
+
+	// The flags are applied last, they are recorded in the meantime
+	type flagValue struct {
+		setting setting
+		value   string
+	}
+	var flagValues []flagValue
+	var file string
+
+	fs := flag.NewFlagSet("sawit", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&file, "config", "", "YAML configuration file (CONFIG_FILE)")
+	fs.BoolVar(&c.PrintConfig, "print-config", false, "print the configuration and exit")
+	for _, s := range settings {
+		s := s
+		record := func(value string) error {
+			flagValues = append(flagValues, flagValue{setting: s, value: value})
+			return nil
+		}
+
+		usage := fmt.Sprintf("%s (%s)", s.usage, s.env)
+		if s.boolean {
+			fs.BoolFunc(s.flag, usage, record)
+		} else {
+			fs.Func(s.flag, usage, record)
+		}
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			var usage strings.Builder
+			fs.SetOutput(&usage)
+			fs.PrintDefaults()
+			return nil, fmt.Errorf("usage:\n%s", usage.String())
+		}
+		return nil, err
+	}
+	c.Args = fs.Args()
+
+	if file == "" {
+		file, _ = lookupEnv("CONFIG_FILE")
+	}
+	if file != "" {
+		if err := c.readFile(file); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range settings {
+		value, ok := lookupEnv(s.env)
+		if !ok || value == "" {
+			continue
+		}
+
+		if err := s.set(c, value); err != nil {
+			return nil, fmt.Errorf("%s: %w", s.env, err)
+		}
+	}
+
+	for _, f := range flagValues {
+		if err := f.setting.set(c, f.value); err != nil {
+			return nil, fmt.Errorf("--%s: %w", f.setting.flag, err)
+		}
+	}
+
+	return c, nil
+}
+
+// readFile overrides the configuration with the settings of the YAML file,
+// the unknown settings are rejected so a typo is not silently ignored
+func (c *Config) readFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening the configuration: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Write prints the configuration as YAML, the secrets redacted, in the
+// format of the configuration file
+func (c *Config) Write(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c.Redacted()); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+func setDuration(target *time.Duration, value string) error {
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("must be a duration such as 30s: %w", err)
+	}
+
+	*target = parsed
+	return nil
+}
+
+func setInt(target *int, value string) error {
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("must be an integer: %w", err)
+	}
+
+	*target = parsed
+	return nil
+}
+
+func setUint(target *uint64, value string) error {
+	parsed, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("must be a positive integer: %w", err)
+	}
+
+	*target = parsed
+	return nil
+}
+
+func setBool(target *bool, value string) error {
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("must be true or false: %w", err)
+	}
+
+	*target = parsed
+	return nil
+}
+
+// setAPIKeys parses a comma separated list of organisation:key
+func setAPIKeys(target *[]APIKey, value string) error {
+	var keys []APIKey
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		organisation, key, ok := strings.Cut(entry, ":")
+		if !ok || organisation == "" || key == "" {
+			return errors.New("must be a comma separated list of organisation:key")
+		}
+
+		keys = append(keys, APIKey{Organisation: organisation, Key: key})
+	}
+
+	*target = keys
+	return nil
+}
@@ -0,0 +1,15 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/SawitProRecruitment/UserService/auth"
+	"github.com/SawitProRecruitment/UserService/models"
+)
+
+// canAccessEstate tells if the caller of ctx may read and modify estate. The
+// estates of the other organisations are answered as not found so their IDs
+// cannot be probed.
+func canAccessEstate(ctx context.Context, estate *models.Estate) bool {
+	return estate != nil && estate.OrganisationID == auth.OrganisationID(ctx)
+}
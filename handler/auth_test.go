@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/auth"
+	"github.com/SawitProRecruitment/UserService/jobs"
+	"github.com/SawitProRecruitment/UserService/models"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// newOrganisationContext is an echo context of a caller of organisation
+func newOrganisationContext(req *http.Request, organisation string) (echo.Context, *httptest.ResponseRecorder) {
+	req = req.WithContext(auth.NewContext(req.Context(), &auth.Principal{
+		Subject:        "user-1",
+		OrganisationID: organisation,
+	}))
+
+	rec := httptest.NewRecorder()
+	return echo.New().NewContext(req, rec), rec
+}
+
+func TestPostEstate_Organisation(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/estate", bytes.NewBufferString(`{"width": 10, "length": 10}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	c, rec := newOrganisationContext(req, "org-a")
+
+	expectSaveEvents(t, mockRepo, models.EventEstateCreated)
+	mockRepo.EXPECT().SaveEstate(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, estate *models.Estate) error {
+		assert.Equal(t, "org-a", estate.OrganisationID)
+		return nil
+	})
+
+	assert.NoError(t, s.PostEstate(c))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
+
+func TestGetEstateStats_OtherOrganisation(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 10, 10)
+	estate.OrganisationID = "org-a"
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/estate/%s/stats", estateUuid.String()), nil)
+	c, _ := newOrganisationContext(req, "org-b")
+
+	mockRepo.EXPECT().GetEstate(gomock.Any(), estateUuid.String()).Return(estate, nil)
+
+	assertHTTPError(t, s.GetEstateIdStats(c, estateUuid), http.StatusNotFound, "estate not found")
+}
+
+func TestPostTree_OtherOrganisation(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 10, 10)
+	estate.OrganisationID = "org-a"
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/estate/%s/tree", estateUuid.String()), bytes.NewBufferString(`{"x": 1, "y": 1, "height": 10}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	c, _ := newOrganisationContext(req, "org-b")
+
+	mockRepo.EXPECT().GetEstate(gomock.Any(), estateUuid.String()).Return(estate, nil)
+
+	assertHTTPError(t, s.PostEstateIdTree(c, estateUuid), http.StatusNotFound, "estate not found")
+}
+
+func TestGetJob_OtherOrganisation(t *testing.T) {
+	jobPool := jobs.NewPool(jobs.NewPoolOptions{Workers: 1})
+	defer jobPool.Close(context.Background())
+
+	s := &Server{
+		Jobs: jobPool,
+	}
+
+	job, err := jobPool.Submit("org-a", func(ctx context.Context, progress func(int)) (any, error) {
+		return nil, nil
+	})
+	assert.NoError(t, err)
+	jobUuid := uuid.MustParse(job.ID)
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/jobs/%s", jobUuid), nil)
+	c, _ := newOrganisationContext(req, "org-b")
+
+	assertHTTPError(t, s.GetJobsId(c, jobUuid), http.StatusNotFound, "job not found")
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/jobs/%s", jobUuid), nil)
+	c, rec := newOrganisationContext(req, "org-a")
+
+	assert.NoError(t, s.GetJobsId(c, jobUuid))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
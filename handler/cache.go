@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// newETag builds a strong entity tag from the values identifying a response
+func newETag(values ...string) string {
+	hash := sha1.Sum([]byte(strings.Join(values, ":")))
+	return `"` + hex.EncodeToString(hash[:8]) + `"`
+}
+
+// checkETag sets the ETag header and reports whether the client already has
+// this version of the response, in which case 304 Not Modified has been sent.
+func checkETag(ctx echo.Context, etag string) (bool, error) {
+	ctx.Response().Header().Set("ETag", etag)
+
+	ifNoneMatch := ctx.Request().Header.Get("If-None-Match")
+	if ifNoneMatch == "" {
+		return false, nil
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true, ctx.NoContent(http.StatusNotModified)
+		}
+	}
+
+	return false, nil
+}
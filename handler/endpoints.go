@@ -1,30 +1,59 @@
 package handler
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"sort"
+	"strconv"
 
+	"github.com/SawitProRecruitment/UserService/auth"
+	"github.com/SawitProRecruitment/UserService/cache"
 	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/jobs"
 	"github.com/SawitProRecruitment/UserService/models"
-	"github.com/go-playground/validator/v10"
+	"github.com/SawitProRecruitment/UserService/repository"
 	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func (s *Server) PostEstate(ctx echo.Context) error {
 	body := new(EstateRequest)
 	if err := ctx.Bind(body); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return bindError(err)
 	}
 
-	if err := validator.New().Struct(body); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	context := ctx.Request().Context()
+	estate, err := models.NewEstate(body.Width, body.Length)
+	if err != nil {
+		return httpError(err)
 	}
+	estate.OrganisationID = auth.OrganisationID(context)
+
+	// The event is saved with the estate, or not at all
+	err = s.Repository.RunInTx(context, func(repo repository.RepositoryInterface) error {
+		if err := repo.SaveEstate(context, estate); err != nil {
+			return httpError(err)
+		}
 
-	estate := models.NewEstate(body.Width, body.Length)
+		event, err := newEvent(models.EventEstateCreated, estate, generated.EstateRequest{
+			Width:  int(estate.Width),
+			Length: int(estate.Length),
+		})
+		if err != nil {
+			return httpError(err)
+		}
+
+		if err := repo.SaveEvents(context, []models.Event{event}); err != nil {
+			return httpError(err)
+		}
 
-	err := s.Repository.SaveEstate(ctx.Request().Context(), estate)
+		return nil
+	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "error saving data")
+		return err
 	}
 
 	return ctx.JSON(http.StatusCreated, generated.EstateResponse{
@@ -36,131 +65,532 @@ func (s *Server) PostEstateIdTree(ctx echo.Context, id generated.EstateIDPathPar
 	context := ctx.Request().Context()
 	body := new(TreeRequest)
 	if err := ctx.Bind(body); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return bindError(err)
 	}
 
-	if err := validator.New().Struct(body); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	// The checks, the insert and the stats update are done in one transaction.
+	// The estate stats are computed from the estate we read, when another tree
+	// is saved in the meantime the whole computation is done again
+	var newTree *models.Tree
+	err := retryOnConflict(func() error {
+		return s.Repository.RunInTx(context, func(repo repository.RepositoryInterface) error {
+			// Start Check if the estate exist and belongs to the caller
+			estate, err := repo.GetEstate(context, id.String())
+			if err != nil {
+				return httpError(err)
+			}
+
+			if !canAccessEstate(context, estate) {
+				return httpError(models.ErrEstateNotFound)
+			}
+			// Done Check if the estate exist and belongs to the caller
+
+			// Create New Tree Entity, the body is checked before its
+			// coordinate is looked up
+			newTree, err = models.NewTree(estate, body.X, body.Y, body.Height)
+			if err != nil {
+				return httpError(err)
+			}
+
+			// Start Check if the tree with the same coordinate already exists
+			oldTree, err := repo.GetTreeByCoordinate(context, estate.ID, newTree.X, newTree.Y)
+			if err != nil {
+				return httpError(err)
+			}
+
+			if oldTree != nil {
+				return httpError(models.ErrDuplicateCoordinate)
+			}
+			// Done Check if the tree with the same coordinate already exists
+
+			// Get Existing trees to calculate median
+			trees, err := repo.GetTreesByEstate(context, estate.ID)
+			if err != nil {
+				return httpError(err)
+			}
+
+			treeValues := *trees
+			treeValues = append(treeValues, *newTree)
+
+			sort.Slice(treeValues, func(i, j int) bool {
+				return treeValues[i].Height < treeValues[j].Height
+			})
+
+			if err := estate.CalculateEstateTreeMedian(&treeValues); err != nil {
+				return httpError(err)
+			}
+			newTree.Estate = estate
+
+			// Save New Tree Entity
+			err = repo.SaveTree(context, newTree)
+			if errors.Is(err, repository.ErrVersionConflict) {
+				return err
+			}
+
+			if err != nil {
+				return httpError(err)
+			}
+
+			return saveTreeEvents(context, repo, models.EventTreeAdded, newTree)
+		})
+	})
+	if err != nil {
+		return err
 	}
 
-	// Start Check if the estate exist
+	return ctx.JSON(http.StatusCreated, generated.EstateResponse{
+		Id: newTree.UUID,
+	})
+}
+
+func (s *Server) GetEstateIdStats(ctx echo.Context, id generated.EstateIDPathParam) error {
+	context := ctx.Request().Context()
+
+	// Start Check if the estate exist and belongs to the caller
 	estate, err := s.Repository.GetEstate(context, id.String())
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return httpError(err)
+	}
+
+	if !canAccessEstate(context, estate) {
+		return httpError(models.ErrEstateNotFound)
+	}
+	// Done Check if the estate exist and belongs to the caller
+
+	etag := newETag("stats", estate.UUID, strconv.FormatUint(estate.Version, 10))
+	if notModified, err := checkETag(ctx, etag); notModified {
+		return err
 	}
 
-	if estate == nil {
-		return echo.NewHTTPError(http.StatusNotFound, "estate not found")
+	return ctx.JSON(http.StatusOK, newEstateStatsResponse(estate))
+}
+
+func newEstateStatsResponse(estate *models.Estate) generated.EstateStatsResponse {
+	return generated.EstateStatsResponse{
+		Count:  int64(estate.TreeCount),
+		Max:    int(estate.MaxTreeHeight),
+		Min:    int(estate.MinTreeHeight),
+		Median: int(estate.MedianTreeHeight),
+	}
+}
+
+func (s *Server) GetEstateIdDronePlan(ctx echo.Context, id generated.EstateIDPathParam, params generated.GetEstateIdDronePlanParams) error {
+	context := ctx.Request().Context()
+	maxDistance, err := newMaxDistance(params.MaxDistance, s.DefaultMaxDistance)
+	if err != nil {
+		return err
 	}
-	// Done Check if the estate exist
 
-	// Start Check if the tree with the same coordinate already exists
-	oldTree, err := s.Repository.GetTreeByCoordinate(context, estate.ID, uint16(body.X), uint16(body.Y))
+	// Start Check if the estate exist and belongs to the caller
+	estate, err := s.Repository.GetEstate(context, id.String())
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return httpError(err)
+	}
+
+	if !canAccessEstate(context, estate) {
+		return httpError(models.ErrEstateNotFound)
+	}
+	// Done Check if the estate exist and belongs to the caller
+
+	cacheKey := cache.DronePlanKey(estate.UUID, estate.Version, maxDistance)
+	if notModified, err := checkETag(ctx, newETag(cacheKey)); notModified {
+		return err
 	}
 
-	if oldTree != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "tree already exist in that coordinate")
+	if s.PlanCache != nil {
+		cached, ok, err := s.PlanCache.Get(context, cacheKey)
+		if err == nil && ok {
+			return ctx.JSONBlob(http.StatusOK, cached)
+		}
 	}
-	// Done Check if the tree with the same coordinate already exists
 
-	// Get Existing trees to calculate median
 	trees, err := s.Repository.GetTreesByEstate(context, estate.ID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return httpError(err)
+	}
+
+	drone := s.newDrone(context, estate, trees, maxDistance)
+	if err := s.flyDronePlan(context, drone); err != nil {
+		return err
+	}
+
+	response := newDronePlanResponse(drone)
+	if s.PlanCache != nil {
+		if body, err := json.Marshal(response); err == nil {
+			s.PlanCache.Set(context, cacheKey, body)
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// saveTreeEvents saves the events of tree with the tree, in the transaction
+// of repo
+func saveTreeEvents(ctx context.Context, repo repository.RepositoryInterface, eventType models.EventType, tree *models.Tree) error {
+	events, err := newTreeEvents(eventType, tree)
+	if err != nil {
+		return httpError(err)
+	}
+
+	if err := repo.SaveEvents(ctx, events); err != nil {
+		return httpError(err)
+	}
+
+	return nil
+}
+
+// newMaxDistance converts the max_distance query parameter for the drone, it
+// must be positive since the drone uses an unsigned distance. fallback is the
+// distance without the parameter, the battery never drains when zero.
+func newMaxDistance(value *int64, fallback uint64) (*uint64, error) {
+	if value == nil {
+		if fallback == 0 {
+			return nil, nil
+		}
+
+		return &fallback, nil
+	}
+
+	if *value < 1 {
+		return nil, httpError(models.NewValidationError(models.FieldError{
+			Field:   "max_distance",
+			Message: "must be at least 1",
+		}))
+	}
+
+	maxDistance := uint64(*value)
+	return &maxDistance, nil
+}
+
+func newDronePlanResponse(drone *models.Drone) generated.DronePlanResponse {
+	if drone.MaximumBattery == nil {
+		return generated.DronePlanResponse{
+			Distance: int64(drone.Travelled),
+		}
+	}
+
+	lastCoordinateX := int(drone.LastCoordinateX)
+	lastCoordinateY := int(drone.LastCoordinateY)
+
+	return generated.DronePlanResponse{
+		Distance: int64(drone.Travelled),
+		Rest: &generated.DroneRestResponse{
+			X: &lastCoordinateX,
+			Y: &lastCoordinateY,
+		},
+	}
+}
+
+func (s *Server) PostEstateIdDronePlanJobs(ctx echo.Context, id generated.EstateIDPathParam, params generated.PostEstateIdDronePlanJobsParams) error {
+	context := ctx.Request().Context()
+	maxDistance, err := newMaxDistance(params.MaxDistance, s.DefaultMaxDistance)
+	if err != nil {
+		return err
 	}
 
-	// Create New Tree Entity
-	newTree, err := models.NewTree(estate, uint16(body.X), uint16(body.Y), uint8(body.Height))
+	// Start Check if the estate exist and belongs to the caller
+	estate, err := s.Repository.GetEstate(context, id.String())
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return httpError(err)
+	}
+
+	if !canAccessEstate(context, estate) {
+		return httpError(models.ErrEstateNotFound)
+	}
+	// Done Check if the estate exist and belongs to the caller
+
+	job, err := s.Jobs.Submit(estate.OrganisationID, s.newDronePlanJob(context, estate, maxDistance))
+	switch {
+	case errors.Is(err, jobs.ErrQueueFull):
+		ctx.Response().Header().Set("Retry-After", jobQueueRetryAfter)
+		return httpError(errJobQueueFull)
+	case errors.Is(err, jobs.ErrPoolClosed):
+		return errShuttingDown
+	case err != nil:
+		return httpError(err)
+	}
+
+	return ctx.JSON(http.StatusAccepted, newDronePlanJobResponse(job))
+}
+
+// newDronePlanJob loads the trees of estate and flies the drone in the
+// background, requestCtx is the context of the request submitting the job
+func (s *Server) newDronePlanJob(requestCtx context.Context, estate *models.Estate, maxDistance *uint64) jobs.Func {
+	link := trace.LinkFromContext(requestCtx)
+
+	return func(ctx context.Context, progress func(percentage int)) (any, error) {
+		trees, err := s.Repository.GetTreesByEstate(ctx, estate.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		drone := s.newDrone(requestCtx, estate, trees, maxDistance)
+		if err := s.flyDrone(ctx, drone, progress, link); err != nil {
+			return nil, err
+		}
+
+		response := newDronePlanResponse(drone)
+		s.saveDronePlanEvent(ctx, drone.Estate, response)
+
+		return response, nil
 	}
+}
+
+func (s *Server) GetJobsId(ctx echo.Context, id generated.JobIDPathParam) error {
+	// The jobs of the other organisations are not found either
+	job, ok := s.Jobs.Get(id.String())
+	if !ok || job.Owner != auth.OrganisationID(ctx.Request().Context()) {
+		return httpError(errJobNotFound)
+	}
+
+	return ctx.JSON(http.StatusOK, newDronePlanJobResponse(job))
+}
+
+func newDronePlanJobResponse(job jobs.Job) generated.DronePlanJobResponse {
+	response := generated.DronePlanJobResponse{
+		Id:        job.ID,
+		Status:    generated.DronePlanJobResponseStatus(job.Status),
+		Progress:  job.Progress,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}
+
+	if result, ok := job.Result.(generated.DronePlanResponse); ok {
+		response.Result = &result
+	}
+
+	if job.Error != "" {
+		response.Error = &job.Error
+	}
+
+	return response
+}
+
+func (s *Server) PostEstateIdTreeTreeIdRetire(ctx echo.Context, id generated.EstateIDPathParam, treeId generated.TreeIDPathParam) error {
+	context := ctx.Request().Context()
+
+	var tree *models.Tree
+	err := retryOnConflict(func() error {
+		return s.Repository.RunInTx(context, func(repo repository.RepositoryInterface) error {
+			// Start Check if the estate exist and belongs to the caller
+			estate, err := repo.GetEstate(context, id.String())
+			if err != nil {
+				return httpError(err)
+			}
+
+			if !canAccessEstate(context, estate) {
+				return httpError(models.ErrEstateNotFound)
+			}
+			// Done Check if the estate exist and belongs to the caller
+
+			// Start Check if the tree exist
+			tree, err = repo.GetTree(context, estate.ID, treeId.String())
+			if err != nil {
+				return httpError(err)
+			}
+
+			if tree == nil {
+				return httpError(models.ErrTreeNotFound)
+			}
+			// Done Check if the tree exist
+
+			if err := tree.Retire(); err != nil {
+				return httpError(err)
+			}
 
-	treeValues := *trees
-	treeValues = append(treeValues, *newTree)
+			// Get Existing trees to recalculate stats without the retired tree
+			trees, err := repo.GetTreesByEstate(context, estate.ID)
+			if err != nil {
+				return httpError(err)
+			}
 
-	sort.Slice(treeValues, func(i, j int) bool {
-		return treeValues[i].Height < treeValues[j].Height
+			activeTrees := make([]models.Tree, 0, len(*trees))
+			for _, activeTree := range *trees {
+				if activeTree.UUID != tree.UUID {
+					activeTrees = append(activeTrees, activeTree)
+				}
+			}
+
+			if err := estate.RecalculateEstateTreeStats(&activeTrees); err != nil {
+				return httpError(err)
+			}
+			tree.Estate = estate
+
+			// Save Retired Tree Entity
+			err = repo.SaveTree(context, tree)
+			if errors.Is(err, repository.ErrVersionConflict) {
+				return err
+			}
+
+			if err != nil {
+				return httpError(err)
+			}
+
+			return saveTreeEvents(context, repo, models.EventTreeRetired, tree)
+		})
 	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, generated.TreeResponse{
+		Id: tree.UUID,
+	})
+}
 
-	estate.CalculateEstateTreeMedian(&treeValues)
-	newTree.Estate = estate
+func (s *Server) GetEstateIdPlotXYHistory(ctx echo.Context, id generated.EstateIDPathParam, x generated.PlotXPathParam, y generated.PlotYPathParam) error {
+	context := ctx.Request().Context()
 
-	// Save New Tree Entity
-	err = s.Repository.SaveTree(context, newTree)
+	// Start Check if the estate exist and belongs to the caller
+	estate, err := s.Repository.GetEstate(context, id.String())
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return httpError(err)
 	}
 
-	return ctx.JSON(http.StatusCreated, generated.EstateResponse{
-		Id: newTree.UUID,
+	if !canAccessEstate(context, estate) {
+		return httpError(models.ErrEstateNotFound)
+	}
+	// Done Check if the estate exist and belongs to the caller
+
+	if x < 1 || x > int(estate.Length) || y < 1 || y > int(estate.Width) {
+		return httpError(models.ErrOutOfBounds)
+	}
+
+	trees, err := s.Repository.GetTreeHistoryByCoordinate(context, estate.ID, uint16(x), uint16(y))
+	if err != nil {
+		return httpError(err)
+	}
+
+	history := make([]generated.TreeHistoryResponse, 0, len(*trees))
+	for i := range *trees {
+		history = append(history, newTreeHistoryResponse(&(*trees)[i]))
+	}
+
+	return ctx.JSON(http.StatusOK, generated.PlotHistoryResponse{
+		Trees: history,
 	})
 }
 
-func (s *Server) GetEstateIdStats(ctx echo.Context, id generated.EstateIDPathParam) error {
+func newTreeHistoryResponse(tree *models.Tree) generated.TreeHistoryResponse {
+	status := generated.Active
+	if tree.IsRetired() {
+		status = generated.Retired
+	}
+
+	return generated.TreeHistoryResponse{
+		Id:        tree.UUID,
+		X:         int(tree.X),
+		Y:         int(tree.Y),
+		Height:    int(tree.Height),
+		Status:    status,
+		PlantedAt: tree.CreatedAt,
+		RetiredAt: tree.RetiredAt,
+	}
+}
+
+func (s *Server) GetEstateIdYieldForecast(ctx echo.Context, id generated.EstateIDPathParam) error {
 	context := ctx.Request().Context()
 
-	// Start Check if the estate exist
+	// Start Check if the estate exist and belongs to the caller
 	estate, err := s.Repository.GetEstate(context, id.String())
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return httpError(err)
 	}
 
-	if estate == nil {
-		return echo.NewHTTPError(http.StatusNotFound, "estate not found")
+	if !canAccessEstate(context, estate) {
+		return httpError(models.ErrEstateNotFound)
 	}
-	// Done Check if the estate exist
+	// Done Check if the estate exist and belongs to the caller
 
-	return ctx.JSON(http.StatusOK, generated.EstateStatsResponse{
-		Count:  int(estate.TreeCount),
-		Max:    int(estate.MaxTreeHeight),
-		Min:    int(estate.MinTreeHeight),
-		Median: int(estate.MedianTreeHeight),
+	trees, err := s.Repository.GetTreesByEstate(context, estate.ID)
+	if err != nil {
+		return httpError(err)
+	}
+
+	yieldCurve := s.YieldCurve
+	if yieldCurve == nil {
+		yieldCurve = models.DefaultYieldCurve()
+	}
+
+	estimate := yieldCurve.EstimateYield(trees)
+
+	bands := make([]generated.YieldBandResponse, 0, len(estimate.Bands))
+	for _, band := range estimate.Bands {
+		bands = append(bands, generated.YieldBandResponse{
+			Name:          band.Name,
+			MinHeight:     int(band.MinHeight),
+			MaxHeight:     int(band.MaxHeight),
+			TonnesPerTree: band.TonnesPerTree,
+			TreeCount:     band.TreeCount,
+			TonnesPerYear: band.TonnesPerYear,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, generated.YieldForecastResponse{
+		TreeCount:     estimate.TreeCount,
+		TonnesPerYear: estimate.TonnesPerYear,
+		Bands:         bands,
 	})
 }
 
-func (s *Server) GetEstateIdDronePlan(ctx echo.Context, id generated.EstateIDPathParam, params generated.GetEstateIdDronePlanParams) error {
+func (s *Server) GetEstateIdRaster(ctx echo.Context, id generated.EstateIDPathParam, params generated.GetEstateIdRasterParams) error {
 	context := ctx.Request().Context()
-	var maxDistance *uint16
-	if params.MaxDistance != nil {
-		maxDistanceRaw := uint16(*params.MaxDistance)
-		maxDistance = &maxDistanceRaw
+
+	metric := models.RasterMetricHeight
+	if params.Metric != nil {
+		metric = models.RasterMetric(*params.Metric)
 	}
 
-	// Start Check if the estate exist
+	format := generated.Png
+	if params.Format != nil {
+		format = *params.Format
+	}
+
+	size := models.DefaultRasterSize
+	if params.Size != nil {
+		size = *params.Size
+	}
+
+	// Start Check if the estate exist and belongs to the caller
 	estate, err := s.Repository.GetEstate(context, id.String())
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return httpError(err)
 	}
 
-	if estate == nil {
-		return echo.NewHTTPError(http.StatusNotFound, "estate not found")
+	if !canAccessEstate(context, estate) {
+		return httpError(models.ErrEstateNotFound)
 	}
-	// Done Check if the estate exist
+	// Done Check if the estate exist and belongs to the caller
 
 	trees, err := s.Repository.GetTreesByEstate(context, estate.ID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return httpError(err)
 	}
 
-	drone := models.NewDrone(estate, trees, maxDistance)
-	drone.StartFlight()
+	raster, err := models.NewRaster(estate, trees, metric, size)
+	if err != nil {
+		return httpError(err)
+	}
 
-	if maxDistance == nil {
-		return ctx.JSON(http.StatusOK, generated.DronePlanResponse{
-			Distance: int(drone.Travelled),
-		})
-	} else {
-		lastCoordinateX := int(drone.LastCoordinateX)
-		lastCoordinateY := int(drone.LastCoordinateY)
-
-		return ctx.JSON(http.StatusOK, generated.DronePlanResponse{
-			Distance: int(drone.Travelled),
-			Rest: &generated.DroneRestResponse{
-				X: &lastCoordinateX,
-				Y: &lastCoordinateY,
-			},
-		})
+	var body bytes.Buffer
+	contentType := "image/png"
+
+	switch format {
+	case generated.Png:
+		err = raster.EncodePNG(&body)
+	case generated.Asc:
+		contentType = echo.MIMETextPlainCharsetUTF8
+		err = raster.EncodeASCIIGrid(&body)
+	default:
+		return httpError(models.NewValidationError(models.FieldError{
+			Field:   "format",
+			Message: "is unknown",
+		}))
 	}
+
+	if err != nil {
+		return httpError(err)
+	}
+
+	return ctx.Blob(http.StatusOK, contentType, body.Bytes())
 }
@@ -1,706 +1,866 @@
 package handler
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/SawitProRecruitment/UserService/auth"
+	"github.com/SawitProRecruitment/UserService/cache"
 	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/jobs"
+	"github.com/SawitProRecruitment/UserService/logging"
+	"github.com/SawitProRecruitment/UserService/metrics"
 	"github.com/SawitProRecruitment/UserService/models"
 	"github.com/SawitProRecruitment/UserService/repository"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.uber.org/mock/gomock"
 )
 
-func TestPostEstate(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	mockRepo := repository.NewMockRepositoryInterface(ctrl)
-
-	s := &Server{
-		Repository: mockRepo,
-	}
-
-	requestBody := `{"width": 10, "length": 20}`
+// expectRunInTx makes the mock run the unit of work on itself
+func expectRunInTx(mockRepo *repository.MockRepositoryInterface) *gomock.Call {
+	return mockRepo.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, fn func(repo repository.RepositoryInterface) error) error {
+		return fn(mockRepo)
+	}).AnyTimes()
+}
 
-	req := httptest.NewRequest(http.MethodPost, "/estate", bytes.NewBufferString(requestBody))
-	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+// expectSaveEvents expects the events of eventTypes to be saved together, once
+func expectSaveEvents(t *testing.T, mockRepo *repository.MockRepositoryInterface, eventTypes ...models.EventType) *gomock.Call {
+	return mockRepo.EXPECT().SaveEvents(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, events []models.Event) error {
+		saved := make([]models.EventType, 0, len(events))
+		for _, event := range events {
+			saved = append(saved, event.Type)
+		}
 
-	rec := httptest.NewRecorder()
-	c := echo.New().NewContext(req, rec)
+		assert.Equal(t, eventTypes, saved)
+		return nil
+	})
+}
 
-	mockRepo.EXPECT().SaveEstate(c.Request().Context(), gomock.Any()).Return(nil)
+// newTestServer is a Server on a mock repository checked at the end of the
+// test, whose units of work run on the mock itself
+func newTestServer(t *testing.T) (*Server, *repository.MockRepositoryInterface) {
+	mockRepo := repository.NewMockRepositoryInterface(gomock.NewController(t))
+	expectRunInTx(mockRepo)
 
-	if assert.NoError(t, s.PostEstate(c)) {
-		var responseBody generated.EstateResponse
-		err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
-		if err != nil {
-			t.Fatalf("failed to unmarshal response body: %v", err)
-		}
+	return &Server{Repository: mockRepo}, mockRepo
+}
 
-		assert.Equal(t, http.StatusCreated, rec.Code)
-		assert.NotEmpty(t, responseBody.Id, "id should not be empty")
+// fixtureEstate is an empty estate of width by length, of id 1
+func fixtureEstate(estateUuid uuid.UUID, width uint16, length uint16) *models.Estate {
+	return &models.Estate{
+		ID:     1,
+		UUID:   estateUuid.String(),
+		Width:  width,
+		Length: length,
 	}
 }
 
-func TestPostEstate_BadPayload(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	mockRepo := repository.NewMockRepositoryInterface(ctrl)
-
-	s := &Server{
-		Repository: mockRepo,
+// newTestContext is the echo context of a request to target, body is sent as
+// JSON unless empty
+func newTestContext(method string, target string, body string) (echo.Context, *httptest.ResponseRecorder) {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
 	}
 
-	requestBody := `{"invalid_json": "missing required fields"}`
-	req := httptest.NewRequest(http.MethodPost, "/estate", bytes.NewBufferString(requestBody))
-	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req := httptest.NewRequest(method, target, reader)
+	if body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
 
 	rec := httptest.NewRecorder()
-	c := echo.New().NewContext(req, rec)
-
-	err := s.PostEstate(c)
+	return echo.New().NewContext(req, rec), rec
+}
 
-	if httpErr, ok := err.(*echo.HTTPError); ok {
-		statusCode := httpErr.Code
+// assertHTTPError checks err is answered with status and message
+func assertHTTPError(t *testing.T, err error, status int, message string) {
+	t.Helper()
 
-		assert.Error(t, err)
-		assert.Equal(t, http.StatusBadRequest, statusCode)
+	var httpErr *echo.HTTPError
+	if assert.ErrorAs(t, err, &httpErr) {
+		assert.Equal(t, status, httpErr.Code)
+		assert.Equal(t, message, httpErr.Message)
 	}
 }
 
-func TestPostEstate_ErrorPersisting(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
+func TestPostEstate(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	c, rec := newTestContext(http.MethodPost, "/estate", `{"width": 10, "length": 20}`)
 
-	mockRepo := repository.NewMockRepositoryInterface(ctrl)
+	mockRepo.EXPECT().SaveEstate(c.Request().Context(), gomock.Any()).Return(nil)
+	expectSaveEvents(t, mockRepo, models.EventEstateCreated)
 
-	s := &Server{
-		Repository: mockRepo,
-	}
+	require.NoError(t, s.PostEstate(c))
+
+	var responseBody generated.EstateResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &responseBody))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.NotEmpty(t, responseBody.Id, "id should not be empty")
+}
 
-	requestBody := `{"width": 10, "length": 20}`
+func TestPostEstate_BadPayload(t *testing.T) {
+	s, _ := newTestServer(t)
 
-	req := httptest.NewRequest(http.MethodPost, "/estate", bytes.NewBufferString(requestBody))
+	req := httptest.NewRequest(http.MethodPost, "/estate", strings.NewReader(`{"invalid_json": "missing required fields"}`))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 
 	rec := httptest.NewRecorder()
-	c := echo.New().NewContext(req, rec)
+	newValidatedEcho(t, s).ServeHTTP(rec, req)
 
-	mockRepo.EXPECT().SaveEstate(c.Request().Context(), gomock.Any()).Return(errors.New("error"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestPostEstate_ErrorPersisting(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	c, _ := newTestContext(http.MethodPost, "/estate", `{"width": 10, "length": 20}`)
 
-	err := s.PostEstate(c)
-	if httpErr, ok := err.(*echo.HTTPError); ok {
-		statusCode := httpErr.Code
+	mockRepo.EXPECT().SaveEstate(c.Request().Context(), gomock.Any()).Return(errors.New("error"))
 
-		assert.Error(t, err)
-		assert.Equal(t, http.StatusBadRequest, statusCode, "error saving data")
-	}
+	assertHTTPError(t, s.PostEstate(c), http.StatusInternalServerError, "internal server error")
 }
 
 func TestPostTree(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	estateId := uint64(1)
+	s, mockRepo := newTestServer(t)
 	estateUuid := uuid.New()
-
-	mockRepo := repository.NewMockRepositoryInterface(ctrl)
-	mockEstate := models.Estate{
-		ID:     estateId,
-		UUID:   estateUuid.String(),
-		Width:  10,
-		Length: 10,
-	}
-	mockTreesResponse := []models.Tree{
-		{
-			ID:       1,
-			EstateID: mockEstate.ID,
-			UUID:     uuid.NewString(),
-			X:        1,
-			Y:        1,
-			Height:   10,
-		},
+	estate := fixtureEstate(estateUuid, 10, 10)
+	mockTrees := []models.Tree{
+		{ID: 1, EstateID: estate.ID, UUID: uuid.NewString(), X: 1, Y: 1, Height: 10},
 	}
 
-	s := &Server{
-		Repository: mockRepo,
-	}
+	c, rec := newTestContext(http.MethodPost, fmt.Sprintf("/estate/%s/tree", estateUuid), `{"x": 1, "y": 1, "height": 10}`)
 
-	requestBody := `{"x": 1, "y": 1, "height": 10}`
-
-	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/estate/%s/tree", estateUuid), bytes.NewBufferString(requestBody))
-	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
-
-	rec := httptest.NewRecorder()
-	c := echo.New().NewContext(req, rec)
-
-	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(&mockEstate, nil)
-	mockRepo.EXPECT().GetTreeByCoordinate(c.Request().Context(), estateId, uint16(1), uint16(1)).Return(nil, nil)
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(estate, nil)
+	mockRepo.EXPECT().GetTreeByCoordinate(c.Request().Context(), estate.ID, uint16(1), uint16(1)).Return(nil, nil)
 	mockRepo.EXPECT().SaveTree(c.Request().Context(), gomock.Any())
-	mockRepo.EXPECT().GetTreesByEstate(c.Request().Context(), estateId).Return(&mockTreesResponse, nil)
+	mockRepo.EXPECT().GetTreesByEstate(c.Request().Context(), estate.ID).Return(&mockTrees, nil)
+	expectSaveEvents(t, mockRepo, models.EventTreeAdded, models.EventEstateStatsChanged)
 
-	if assert.NoError(t, s.PostEstateIdTree(c, estateUuid)) {
-		var responseBody generated.TreeResponse
-		err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
-		if err != nil {
-			t.Fatalf("failed to unmarshal response body: %v", err)
-		}
+	require.NoError(t, s.PostEstateIdTree(c, estateUuid))
 
-		assert.Equal(t, http.StatusCreated, rec.Code)
-		assert.NotEmpty(t, responseBody.Id, "id should not be empty")
-	}
+	var responseBody generated.TreeResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &responseBody))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.NotEmpty(t, responseBody.Id, "id should not be empty")
 }
 
 func TestPostTree_ErrorGetEstate(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
+	s, mockRepo := newTestServer(t)
 	estateUuid := uuid.New()
+	c, _ := newTestContext(http.MethodPost, fmt.Sprintf("/estate/%s/tree", estateUuid), `{"x": 1, "y": 1, "height": 10}`)
 
-	mockRepo := repository.NewMockRepositoryInterface(ctrl)
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(nil, errors.New("error"))
 
-	s := &Server{
-		Repository: mockRepo,
-	}
+	assertHTTPError(t, s.PostEstateIdTree(c, estateUuid), http.StatusInternalServerError, "internal server error")
+}
 
-	requestBody := `{"x": 1, "y": 1, "height": 10}`
+func TestPostTree_ErrorEstateNotFound(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	c, _ := newTestContext(http.MethodPost, fmt.Sprintf("/estate/%s/tree", estateUuid), `{"x": 1, "y": 1, "height": 10}`)
 
-	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/estate/%s/tree", estateUuid), bytes.NewBufferString(requestBody))
-	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(nil, nil)
 
-	rec := httptest.NewRecorder()
-	c := echo.New().NewContext(req, rec)
+	assertHTTPError(t, s.PostEstateIdTree(c, estateUuid), http.StatusNotFound, "estate not found")
+}
 
-	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(nil, errors.New("error"))
+func TestPostTree_TreeError(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 10, 10)
+	c, _ := newTestContext(http.MethodPost, fmt.Sprintf("/estate/%s/tree", estateUuid), `{"x": 1, "y": 1, "height": 10}`)
 
-	err := s.PostEstateIdTree(c, estateUuid)
-	if httpErr, ok := err.(*echo.HTTPError); ok {
-		statusCode := httpErr.Code
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(estate, nil)
+	mockRepo.EXPECT().GetTreeByCoordinate(c.Request().Context(), estate.ID, uint16(1), uint16(1)).Return(nil, errors.New("error"))
 
-		assert.Error(t, err)
-		assert.Equal(t, http.StatusInternalServerError, statusCode)
-	}
+	assertHTTPError(t, s.PostEstateIdTree(c, estateUuid), http.StatusInternalServerError, "internal server error")
 }
 
-func TestPostTree_ErrorEstateNotFound(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
+func TestPostTree_TreeAlreadyExists(t *testing.T) {
+	s, mockRepo := newTestServer(t)
 	estateUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 10, 10)
+	mockTree := models.Tree{ID: 1, UUID: uuid.NewString(), X: 1, Y: 1, Height: 10}
+	c, _ := newTestContext(http.MethodPost, fmt.Sprintf("/estate/%s/tree", estateUuid), `{"x": 1, "y": 1, "height": 10}`)
 
-	mockRepo := repository.NewMockRepositoryInterface(ctrl)
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(estate, nil)
+	mockRepo.EXPECT().GetTreeByCoordinate(c.Request().Context(), estate.ID, uint16(1), uint16(1)).Return(&mockTree, nil)
 
-	s := &Server{
-		Repository: mockRepo,
-	}
+	assertHTTPError(t, s.PostEstateIdTree(c, estateUuid), http.StatusBadRequest, "tree already exist in that coordinate")
+}
 
-	requestBody := `{"x": 1, "y": 1, "height": 10}`
+func TestPostTree_TreeErrorWhenCreate(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 10, 10)
+	mockTrees := []models.Tree{
+		{ID: 1, EstateID: estate.ID, UUID: uuid.NewString(), X: 1, Y: 1, Height: 10},
+	}
+	c, _ := newTestContext(http.MethodPost, fmt.Sprintf("/estate/%s/tree", estateUuid), `{"x": 1, "y": 1, "height": 10}`)
 
-	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/estate/%s/tree", estateUuid), bytes.NewBufferString(requestBody))
-	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(estate, nil)
+	mockRepo.EXPECT().GetTreeByCoordinate(c.Request().Context(), estate.ID, uint16(1), uint16(1)).Return(nil, nil)
+	mockRepo.EXPECT().SaveTree(c.Request().Context(), gomock.Any()).Return(errors.New("error"))
+	mockRepo.EXPECT().GetTreesByEstate(c.Request().Context(), estate.ID).Return(&mockTrees, nil)
 
-	rec := httptest.NewRecorder()
-	c := echo.New().NewContext(req, rec)
+	assertHTTPError(t, s.PostEstateIdTree(c, estateUuid), http.StatusInternalServerError, "internal server error")
+}
 
-	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(nil, nil)
+func TestGetEstateStats_ErrorWhenGet(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	c, _ := newTestContext(http.MethodGet, fmt.Sprintf("/estate/%s/stats", estateUuid), "")
 
-	err := s.PostEstateIdTree(c, estateUuid)
-	if httpErr, ok := err.(*echo.HTTPError); ok {
-		statusCode := httpErr.Code
-		statusMessage := httpErr.Message
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), gomock.Any()).Return(nil, errors.New("error"))
 
-		assert.Error(t, err)
-		assert.Equal(t, http.StatusNotFound, statusCode)
-		assert.Equal(t, "estate not found", statusMessage)
-	}
+	assertHTTPError(t, s.GetEstateIdStats(c, estateUuid), http.StatusInternalServerError, "internal server error")
 }
 
-func TestPostTree_TreeError(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	estateId := uint64(1)
+func TestGetEstateStats_EstateNotFound(t *testing.T) {
+	s, mockRepo := newTestServer(t)
 	estateUuid := uuid.New()
+	c, _ := newTestContext(http.MethodGet, fmt.Sprintf("/estate/%s/stats", estateUuid), "")
 
-	mockRepo := repository.NewMockRepositoryInterface(ctrl)
-	mockEstate := models.Estate{
-		ID:     estateId,
-		UUID:   estateUuid.String(),
-		Width:  10,
-		Length: 10,
-	}
-	s := &Server{
-		Repository: mockRepo,
-	}
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), gomock.Any()).Return(nil, nil)
 
-	requestBody := `{"x": 1, "y": 1, "height": 10}`
+	assertHTTPError(t, s.GetEstateIdStats(c, estateUuid), http.StatusNotFound, "estate not found")
+}
 
-	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/estate/%s/tree", estateUuid), bytes.NewBufferString(requestBody))
-	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+func TestGetEstateStats(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 10, 10)
+	estate.TreeCount = 2
+	estate.MaxTreeHeight = 10
+	estate.MinTreeHeight = 1
+	estate.MedianTreeHeight = 5
 
-	rec := httptest.NewRecorder()
-	c := echo.New().NewContext(req, rec)
+	c, rec := newTestContext(http.MethodGet, fmt.Sprintf("/estate/%s/stats", estateUuid), "")
 
-	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(&mockEstate, nil)
-	mockRepo.EXPECT().GetTreeByCoordinate(c.Request().Context(), estateId, uint16(1), uint16(1)).Return(nil, errors.New("error"))
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(estate, nil)
 
-	err := s.PostEstateIdTree(c, estateUuid)
-	if httpErr, ok := err.(*echo.HTTPError); ok {
-		statusCode := httpErr.Code
-		statusMessage := httpErr.Message
+	require.NoError(t, s.GetEstateIdStats(c, estateUuid))
 
-		assert.Error(t, err)
-		assert.Equal(t, http.StatusInternalServerError, statusCode)
-		assert.Equal(t, "error", statusMessage)
-	}
+	var responseBody generated.EstateStatsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &responseBody))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, generated.EstateStatsResponse{Count: 2, Max: 10, Min: 1, Median: 5}, responseBody)
 }
 
-func TestPostTree_TreeAlreadyExists(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	estateId := uint64(1)
-	treeId := uint64(1)
+func TestGetEstateStats_MoreThan255Trees(t *testing.T) {
+	s, mockRepo := newTestServer(t)
 	estateUuid := uuid.New()
-	treeUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 50000, 50000)
+	estate.TreeCount = 3000000
+	estate.MinTreeHeight = 1
+	estate.MaxTreeHeight = 30
+	estate.MedianTreeHeight = 15
 
-	mockRepo := repository.NewMockRepositoryInterface(ctrl)
-	mockEstate := models.Estate{
-		ID:     estateId,
-		UUID:   estateUuid.String(),
-		Width:  10,
-		Length: 10,
-	}
-	mockTree := models.Tree{
-		ID:     treeId,
-		UUID:   treeUuid.String(),
-		X:      1,
-		Y:      1,
-		Height: 10,
-	}
+	c, rec := newTestContext(http.MethodGet, fmt.Sprintf("/estate/%s/stats", estateUuid), "")
 
-	s := &Server{
-		Repository: mockRepo,
-	}
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(estate, nil)
 
-	requestBody := `{"x": 1, "y": 1, "height": 10}`
+	require.NoError(t, s.GetEstateIdStats(c, estateUuid))
 
-	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/estate/%s/tree", estateUuid), bytes.NewBufferString(requestBody))
-	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	var responseBody generated.EstateStatsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &responseBody))
+	assert.Equal(t, int64(3000000), responseBody.Count)
+}
 
-	rec := httptest.NewRecorder()
-	c := echo.New().NewContext(req, rec)
+func TestGetEstateStats_NotModified(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 10, 10)
+	estate.TreeCount = 2
+	estate.Version = 2
+
+	target := fmt.Sprintf("/estate/%s/stats", estateUuid)
+	mockRepo.EXPECT().GetEstate(gomock.Any(), estateUuid.String()).Return(estate, nil).Times(3)
+
+	c, rec := newTestContext(http.MethodGet, target, "")
+	require.NoError(t, s.GetEstateIdStats(c, estateUuid))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	c, rec = newTestContext(http.MethodGet, target, "")
+	c.Request().Header.Set("If-None-Match", etag)
+	require.NoError(t, s.GetEstateIdStats(c, estateUuid))
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	// A new tree bumps the estate version
+	estate.Version++
+
+	c, rec = newTestContext(http.MethodGet, target, "")
+	c.Request().Header.Set("If-None-Match", etag)
+	require.NoError(t, s.GetEstateIdStats(c, estateUuid))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+}
 
-	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(&mockEstate, nil)
-	mockRepo.EXPECT().GetTreeByCoordinate(c.Request().Context(), estateId, uint16(1), uint16(1)).Return(&mockTree, nil)
+func TestGetDronePlan_ErrorGet(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	c, _ := newTestContext(http.MethodGet, fmt.Sprintf("/estate/%s/drone-plan", estateUuid), "")
 
-	err := s.PostEstateIdTree(c, estateUuid)
-	if httpErr, ok := err.(*echo.HTTPError); ok {
-		statusCode := httpErr.Code
-		statusMessage := httpErr.Message
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(nil, errors.New("error"))
 
-		assert.Error(t, err)
-		assert.Equal(t, http.StatusBadRequest, statusCode)
-		assert.Equal(t, "tree already exist in that coordinate", statusMessage)
-	}
+	err := s.GetEstateIdDronePlan(c, estateUuid, generated.GetEstateIdDronePlanParams{})
+	assertHTTPError(t, err, http.StatusInternalServerError, "internal server error")
 }
 
-func TestPostTree_TreeErrorWhenCreate(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
+func TestGetDronePlan_ErrorEstateNotFound(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	c, _ := newTestContext(http.MethodGet, fmt.Sprintf("/estate/%s/drone-plan", estateUuid), "")
+
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(nil, nil)
+
+	err := s.GetEstateIdDronePlan(c, estateUuid, generated.GetEstateIdDronePlanParams{})
+	assertHTTPError(t, err, http.StatusNotFound, "estate not found")
+}
 
-	estateId := uint64(1)
+func TestGetDronePlan_ErrorGetTreesError(t *testing.T) {
+	s, mockRepo := newTestServer(t)
 	estateUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 10, 10)
+	c, _ := newTestContext(http.MethodGet, fmt.Sprintf("/estate/%s/drone-plan", estateUuid), "")
 
-	mockRepo := repository.NewMockRepositoryInterface(ctrl)
-	mockEstate := models.Estate{
-		ID:     estateId,
-		UUID:   estateUuid.String(),
-		Width:  10,
-		Length: 10,
-	}
-	mockTreesResponse := []models.Tree{
-		{
-			ID:       1,
-			EstateID: mockEstate.ID,
-			UUID:     uuid.NewString(),
-			X:        1,
-			Y:        1,
-			Height:   10,
-		},
-	}
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(estate, nil)
+	mockRepo.EXPECT().GetTreesByEstate(c.Request().Context(), estate.ID).Return(nil, errors.New("error"))
 
-	s := &Server{
-		Repository: mockRepo,
+	err := s.GetEstateIdDronePlan(c, estateUuid, generated.GetEstateIdDronePlanParams{})
+	assertHTTPError(t, err, http.StatusInternalServerError, "internal server error")
+}
+
+func TestGetDronePlan_WithoutMaxDistance(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 3, 3)
+	mockTrees := []models.Tree{
+		{ID: 1, EstateID: estate.ID, UUID: uuid.NewString(), X: 1, Y: 1, Height: 10},
 	}
+	c, rec := newTestContext(http.MethodGet, fmt.Sprintf("/estate/%s/drone-plan", estateUuid), "")
 
-	requestBody := `{"x": 1, "y": 1, "height": 10}`
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(estate, nil)
+	mockRepo.EXPECT().GetTreesByEstate(c.Request().Context(), estate.ID).Return(&mockTrees, nil)
 
-	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/estate/%s/tree", estateUuid), bytes.NewBufferString(requestBody))
-	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	require.NoError(t, s.GetEstateIdDronePlan(c, estateUuid, generated.GetEstateIdDronePlanParams{}))
 
-	rec := httptest.NewRecorder()
-	c := echo.New().NewContext(req, rec)
+	var responseBody generated.DronePlanResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &responseBody))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, generated.DronePlanResponse{Distance: 102}, responseBody)
+}
 
-	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(&mockEstate, nil)
-	mockRepo.EXPECT().GetTreeByCoordinate(c.Request().Context(), estateId, uint16(1), uint16(1)).Return(nil, nil)
-	mockRepo.EXPECT().SaveTree(c.Request().Context(), gomock.Any()).Return(errors.New("error"))
-	mockRepo.EXPECT().GetTreesByEstate(c.Request().Context(), estateId).Return(&mockTreesResponse, nil)
+func TestGetDronePlan_WithMaxDistance(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 3, 3)
+	mockTrees := []models.Tree{
+		{ID: 1, EstateID: estate.ID, UUID: uuid.NewString(), X: 1, Y: 1, Height: 10},
+	}
+	c, rec := newTestContext(http.MethodGet, fmt.Sprintf("/estate/%s/drone-plan", estateUuid), "")
 
-	err := s.PostEstateIdTree(c, estateUuid)
-	if httpErr, ok := err.(*echo.HTTPError); ok {
-		statusCode := httpErr.Code
-		statusMessage := httpErr.Message
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(estate, nil)
+	mockRepo.EXPECT().GetTreesByEstate(c.Request().Context(), estate.ID).Return(&mockTrees, nil)
 
-		assert.Error(t, err)
-		assert.Equal(t, http.StatusInternalServerError, statusCode)
-		assert.Equal(t, "error", statusMessage)
-	}
+	maxDistance := int64(10)
+	require.NoError(t, s.GetEstateIdDronePlan(c, estateUuid, generated.GetEstateIdDronePlanParams{
+		MaxDistance: &maxDistance,
+	}))
+
+	var responseBody generated.DronePlanResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &responseBody))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	x, y := 1, 1
+	assert.Equal(t, generated.DronePlanResponse{
+		Distance: 10,
+		Rest:     &generated.DroneRestResponse{X: &x, Y: &y},
+	}, responseBody)
 }
 
-func TestGetEstateStats_ErrorWhenGet(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
+func TestGetDronePlan_InvalidMaxDistance(t *testing.T) {
+	s, _ := newTestServer(t)
+	estateUuid := uuid.New()
+	c, _ := newTestContext(http.MethodGet, fmt.Sprintf("/estate/%s/drone-plan?max_distance=-1", estateUuid), "")
 
-	mockRepo := repository.NewMockRepositoryInterface(ctrl)
+	maxDistance := int64(-1)
+	err := s.GetEstateIdDronePlan(c, estateUuid, generated.GetEstateIdDronePlanParams{
+		MaxDistance: &maxDistance,
+	})
 
-	s := &Server{
-		Repository: mockRepo,
+	var httpErr *echo.HTTPError
+	if assert.ErrorAs(t, err, &httpErr) {
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
 	}
+}
 
+func TestGetDronePlan_DefaultMaxDistance(t *testing.T) {
+	s, mockRepo := newTestServer(t)
 	estateUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 3, 3)
+	c, rec := newTestContext(http.MethodGet, fmt.Sprintf("/estate/%s/drone-plan", estateUuid), "")
 
-	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/estate/%s/stats", estateUuid.String()), nil)
-	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	// The request without max_distance flies with the battery of the server
+	s.DefaultMaxDistance = 10
 
-	rec := httptest.NewRecorder()
-	c := echo.New().NewContext(req, rec)
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(estate, nil)
+	mockRepo.EXPECT().GetTreesByEstate(c.Request().Context(), estate.ID).Return(&[]models.Tree{}, nil)
 
-	mockRepo.EXPECT().GetEstate(c.Request().Context(), gomock.Any()).Return(nil, errors.New("error"))
+	require.NoError(t, s.GetEstateIdDronePlan(c, estateUuid, generated.GetEstateIdDronePlanParams{}))
 
-	err := s.GetEstateIdStats(c, estateUuid)
-	if httpErr, ok := err.(*echo.HTTPError); ok {
-		statusCode := httpErr.Code
-		statusMessage := httpErr.Message
+	var responseBody generated.DronePlanResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &responseBody))
+	assert.NotNil(t, responseBody.Rest)
+}
 
-		assert.Error(t, err)
-		assert.Equal(t, http.StatusInternalServerError, statusCode)
-		assert.Equal(t, "error", statusMessage)
+func TestGetDronePlan_FlightTrace(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 3, 3)
+	mockTrees := []models.Tree{
+		{ID: 1, EstateID: estate.ID, UUID: uuid.NewString(), X: 1, Y: 1, Height: 10},
 	}
-}
 
-func TestGetEstateStats_EstateNotFound(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
+	var trace strings.Builder
+	s.FlightTrace = logging.New(&trace, logging.Options{})
 
-	mockRepo := repository.NewMockRepositoryInterface(ctrl)
+	c, _ := newTestContext(http.MethodGet, fmt.Sprintf("/estate/%s/drone-plan", estateUuid), "")
+	c.SetRequest(c.Request().WithContext(logging.WithRequestID(c.Request().Context(), "request-1")))
 
-	s := &Server{
-		Repository: mockRepo,
-	}
+	mockRepo.EXPECT().GetEstate(gomock.Any(), estateUuid.String()).Return(estate, nil)
+	mockRepo.EXPECT().GetTreesByEstate(gomock.Any(), estate.ID).Return(&mockTrees, nil)
+
+	require.NoError(t, s.GetEstateIdDronePlan(c, estateUuid, generated.GetEstateIdDronePlanParams{}))
 
+	var record map[string]any
+	require.NoError(t, json.NewDecoder(strings.NewReader(trace.String())).Decode(&record))
+	assert.Equal(t, "drone start flight", record["msg"])
+	assert.Equal(t, "request-1", record["request_id"])
+	assert.Equal(t, estateUuid.String(), record["estate_id"])
+}
+
+func TestGetDronePlan_Metrics(t *testing.T) {
+	s, mockRepo := newTestServer(t)
 	estateUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 3, 3)
 
-	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/estate/%s/stats", estateUuid.String()), nil)
-	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	m := metrics.New()
+	s.Metrics = m
 
-	rec := httptest.NewRecorder()
-	c := echo.New().NewContext(req, rec)
+	c, _ := newTestContext(http.MethodGet, fmt.Sprintf("/estate/%s/drone-plan", estateUuid), "")
 
-	mockRepo.EXPECT().GetEstate(c.Request().Context(), gomock.Any()).Return(nil, nil)
+	mockRepo.EXPECT().GetEstate(gomock.Any(), estateUuid.String()).Return(estate, nil)
+	mockRepo.EXPECT().GetTreesByEstate(gomock.Any(), estate.ID).Return(&[]models.Tree{}, nil)
 
-	err := s.GetEstateIdStats(c, estateUuid)
-	if httpErr, ok := err.(*echo.HTTPError); ok {
-		statusCode := httpErr.Code
-		statusMessage := httpErr.Message
+	require.NoError(t, s.GetEstateIdDronePlan(c, estateUuid, generated.GetEstateIdDronePlanParams{}))
 
-		assert.Error(t, err)
-		assert.Equal(t, http.StatusNotFound, statusCode)
-		assert.Equal(t, "estate not found", statusMessage)
-	}
+	scrape := httptest.NewRecorder()
+	m.Handler().ServeHTTP(scrape, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, scrape.Body.String(), "sawit_drone_plots_visited_total 9")
+	assert.Contains(t, scrape.Body.String(), "sawit_drone_plan_duration_seconds_count 1")
 }
 
-func TestGetEstateStats(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	estateId := uint64(1)
-	estateUuid := uuid.New()
-	mockRepo := repository.NewMockRepositoryInterface(ctrl)
-	mockEstate := models.Estate{
-		ID:               estateId,
-		UUID:             estateUuid.String(),
-		Width:            10,
-		Length:           10,
-		TreeCount:        2,
-		MaxTreeHeight:    10,
-		MinTreeHeight:    1,
-		MedianTreeHeight: 5,
+func TestGetDronePlan_Spans(t *testing.T) {
+	server, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 3, 3)
+
+	recorder := tracetest.NewSpanRecorder()
+	server.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	s := NewTracedServer(server)
+
+	c, _ := newTestContext(http.MethodGet, fmt.Sprintf("/estate/%s/drone-plan", estateUuid), "")
+	req := c.Request()
+
+	mockRepo.EXPECT().GetEstate(gomock.Any(), estateUuid.String()).Return(estate, nil).Times(2)
+	mockRepo.EXPECT().GetTreesByEstate(gomock.Any(), estate.ID).Return(&[]models.Tree{}, nil)
+
+	require.NoError(t, s.GetEstateIdDronePlan(c, estateUuid, generated.GetEstateIdDronePlanParams{}))
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 3) {
+		assert.Equal(t, "drone.map_trees", spans[0].Name())
+		assert.Equal(t, "drone.flight", spans[1].Name())
+		assert.Contains(t, spans[1].Attributes(), attribute.Int64("drone.plots_visited", 9))
+		assert.Equal(t, "handler.GetEstateIdDronePlan", spans[2].Name())
+		assert.Equal(t, spans[2].SpanContext().SpanID(), spans[0].Parent().SpanID())
+		assert.Equal(t, spans[2].SpanContext().SpanID(), spans[1].Parent().SpanID())
 	}
 
-	mockResponses := generated.EstateStatsResponse{
-		Count:  2,
-		Max:    10,
-		Min:    1,
-		Median: 5,
+	// The estates of the other organisations fail the span of the method
+	// without marking it as an error
+	c = echo.New().NewContext(req, httptest.NewRecorder())
+	c.SetRequest(req.WithContext(auth.NewContext(req.Context(), &auth.Principal{OrganisationID: "other"})))
+	assert.Error(t, s.GetEstateIdDronePlan(c, estateUuid, generated.GetEstateIdDronePlanParams{}))
+
+	spans = recorder.Ended()
+	if assert.Len(t, spans, 4) {
+		assert.Len(t, spans[3].Events(), 1)
+		assert.Equal(t, codes.Unset, spans[3].Status().Code)
 	}
+}
 
-	s := &Server{
-		Repository: mockRepo,
+func TestGetDronePlan_Cached(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 3, 3)
+	estate.Version = 1
+	mockTrees := []models.Tree{
+		{UUID: uuid.NewString(), X: 1, Y: 1, Height: 10},
 	}
 
-	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/estate/%s/stats", estateUuid.String()), nil)
-	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	s.PlanCache = cache.NewLRU(10)
 
-	rec := httptest.NewRecorder()
-	c := echo.New().NewContext(req, rec)
+	target := fmt.Sprintf("/estate/%s/drone-plan", estateUuid)
+	mockRepo.EXPECT().GetEstate(gomock.Any(), estateUuid.String()).Return(estate, nil).Times(3)
+	mockRepo.EXPECT().GetTreesByEstate(gomock.Any(), estate.ID).Return(&mockTrees, nil).Times(1)
 
-	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(&mockEstate, nil)
+	var etag string
+	for i := 0; i < 2; i++ {
+		c, rec := newTestContext(http.MethodGet, target, "")
 
-	if assert.NoError(t, s.GetEstateIdStats(c, estateUuid)) {
-		var responseBody generated.EstateStatsResponse
-		err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
-		if err != nil {
-			t.Fatalf("failed to unmarshal response body: %v", err)
+		if assert.NoError(t, s.GetEstateIdDronePlan(c, estateUuid, generated.GetEstateIdDronePlanParams{})) {
+			var responseBody generated.DronePlanResponse
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &responseBody))
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, int64(102), responseBody.Distance)
+			etag = rec.Header().Get("ETag")
 		}
-
-		assert.Equal(t, http.StatusOK, rec.Code)
-		assert.Equal(t, mockResponses, responseBody)
 	}
+
+	c, rec := newTestContext(http.MethodGet, target, "")
+	c.Request().Header.Set("If-None-Match", etag)
+
+	require.NoError(t, s.GetEstateIdDronePlan(c, estateUuid, generated.GetEstateIdDronePlanParams{}))
+	assert.Equal(t, http.StatusNotModified, rec.Code)
 }
 
-func TestGetDronePlan_ErrorGet(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
+func TestRetireTree(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	treeUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 10, 10)
+	estate.TreeCount = 2
+	estate.MaxTreeHeight = 20
+	estate.MinTreeHeight = 10
+	estate.MedianTreeHeight = 15
+	mockTree := models.Tree{ID: 1, EstateID: estate.ID, UUID: treeUuid.String(), X: 1, Y: 1, Height: 10}
+	mockTrees := []models.Tree{
+		{UUID: treeUuid.String(), X: 1, Y: 1, Height: 10},
+		{UUID: uuid.NewString(), X: 2, Y: 1, Height: 20},
+	}
+
+	c, rec := newTestContext(http.MethodPost, fmt.Sprintf("/estate/%s/tree/%s/retire", estateUuid, treeUuid), "")
+
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(estate, nil)
+	mockRepo.EXPECT().GetTree(c.Request().Context(), estate.ID, treeUuid.String()).Return(&mockTree, nil)
+	mockRepo.EXPECT().GetTreesByEstate(c.Request().Context(), estate.ID).Return(&mockTrees, nil)
+	expectSaveEvents(t, mockRepo, models.EventTreeRetired, models.EventEstateStatsChanged)
+	mockRepo.EXPECT().SaveTree(c.Request().Context(), gomock.Any()).DoAndReturn(func(_ any, tree *models.Tree) error {
+		assert.True(t, tree.IsRetired())
+		assert.Equal(t, uint32(1), tree.Estate.TreeCount)
+		assert.Equal(t, uint8(20), tree.Estate.MinTreeHeight)
+		assert.Equal(t, uint8(20), tree.Estate.MaxTreeHeight)
+		assert.Equal(t, uint8(20), tree.Estate.MedianTreeHeight)
+		return nil
+	})
+
+	require.NoError(t, s.PostEstateIdTreeTreeIdRetire(c, estateUuid, treeUuid))
+
+	var responseBody generated.TreeResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &responseBody))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, treeUuid.String(), responseBody.Id)
+}
 
+func TestRetireTree_TreeNotFound(t *testing.T) {
+	s, mockRepo := newTestServer(t)
 	estateUuid := uuid.New()
-	mockRepo := repository.NewMockRepositoryInterface(ctrl)
+	treeUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 10, 10)
+	c, _ := newTestContext(http.MethodPost, fmt.Sprintf("/estate/%s/tree/%s/retire", estateUuid, treeUuid), "")
 
-	s := &Server{
-		Repository: mockRepo,
-	}
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(estate, nil)
+	mockRepo.EXPECT().GetTree(c.Request().Context(), estate.ID, treeUuid.String()).Return(nil, nil)
 
-	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/estate/%s/drone-plan", estateUuid.String()), nil)
-	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	assertHTTPError(t, s.PostEstateIdTreeTreeIdRetire(c, estateUuid, treeUuid), http.StatusNotFound, "tree not found")
+}
 
-	rec := httptest.NewRecorder()
-	c := echo.New().NewContext(req, rec)
+func TestRetireTree_AlreadyRetired(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	treeUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 10, 10)
+	retiredAt := time.Now()
+	mockTree := models.Tree{ID: 1, EstateID: estate.ID, UUID: treeUuid.String(), X: 1, Y: 1, Height: 10, RetiredAt: &retiredAt}
+	c, _ := newTestContext(http.MethodPost, fmt.Sprintf("/estate/%s/tree/%s/retire", estateUuid, treeUuid), "")
 
-	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(nil, errors.New("error"))
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(estate, nil)
+	mockRepo.EXPECT().GetTree(c.Request().Context(), estate.ID, treeUuid.String()).Return(&mockTree, nil)
 
-	err := s.GetEstateIdDronePlan(c, estateUuid, generated.GetEstateIdDronePlanParams{})
-	if httpErr, ok := err.(*echo.HTTPError); ok {
-		statusCode := httpErr.Code
-		statusMessage := httpErr.Message
+	assertHTTPError(t, s.PostEstateIdTreeTreeIdRetire(c, estateUuid, treeUuid), http.StatusBadRequest, "tree already retired")
+}
 
-		assert.Error(t, err)
-		assert.Equal(t, http.StatusInternalServerError, statusCode)
-		assert.Equal(t, "error", statusMessage)
+func TestGetPlotHistory(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 10, 10)
+	retiredAt := time.Now()
+	mockTrees := []models.Tree{
+		{UUID: uuid.NewString(), X: 1, Y: 1, Height: 25, RetiredAt: &retiredAt},
+		{UUID: uuid.NewString(), X: 1, Y: 1, Height: 3},
 	}
-}
+	c, rec := newTestContext(http.MethodGet, fmt.Sprintf("/estate/%s/plot/1/1/history", estateUuid), "")
 
-func TestGetDronePlan_ErrorEstateNotFound(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(estate, nil)
+	mockRepo.EXPECT().GetTreeHistoryByCoordinate(c.Request().Context(), estate.ID, uint16(1), uint16(1)).Return(&mockTrees, nil)
 
+	require.NoError(t, s.GetEstateIdPlotXYHistory(c, estateUuid, 1, 1))
+
+	var responseBody generated.PlotHistoryResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &responseBody))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	if assert.Len(t, responseBody.Trees, 2) {
+		assert.Equal(t, generated.Retired, responseBody.Trees[0].Status)
+		assert.NotNil(t, responseBody.Trees[0].RetiredAt)
+		assert.Equal(t, generated.Active, responseBody.Trees[1].Status)
+		assert.Nil(t, responseBody.Trees[1].RetiredAt)
+	}
+}
+
+func TestGetPlotHistory_OutsideBoundaries(t *testing.T) {
+	s, mockRepo := newTestServer(t)
 	estateUuid := uuid.New()
-	mockRepo := repository.NewMockRepositoryInterface(ctrl)
+	estate := fixtureEstate(estateUuid, 10, 10)
+	c, _ := newTestContext(http.MethodGet, fmt.Sprintf("/estate/%s/plot/11/1/history", estateUuid), "")
 
-	s := &Server{
-		Repository: mockRepo,
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(estate, nil)
+
+	assertHTTPError(t, s.GetEstateIdPlotXYHistory(c, estateUuid, 11, 1), http.StatusBadRequest, "outside of boundaries")
+}
+
+func TestGetYieldForecast(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 10, 10)
+	mockTrees := []models.Tree{
+		{UUID: uuid.NewString(), X: 1, Y: 1, Height: 4},
+		{UUID: uuid.NewString(), X: 2, Y: 1, Height: 8},
+		{UUID: uuid.NewString(), X: 3, Y: 1, Height: 10},
 	}
 
-	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/estate/%s/drone-plan", estateUuid.String()), nil)
-	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	s.YieldCurve = models.YieldCurve{
+		{Name: "young", MinHeight: 1, MaxHeight: 5, TonnesPerTree: 0.1},
+		{Name: "prime", MinHeight: 6, MaxHeight: 30, TonnesPerTree: 0.2},
+	}
 
-	rec := httptest.NewRecorder()
-	c := echo.New().NewContext(req, rec)
+	c, rec := newTestContext(http.MethodGet, fmt.Sprintf("/estate/%s/yield-forecast", estateUuid), "")
 
-	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(nil, nil)
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(estate, nil)
+	mockRepo.EXPECT().GetTreesByEstate(c.Request().Context(), estate.ID).Return(&mockTrees, nil)
 
-	err := s.GetEstateIdDronePlan(c, estateUuid, generated.GetEstateIdDronePlanParams{})
-	if httpErr, ok := err.(*echo.HTTPError); ok {
-		statusCode := httpErr.Code
-		statusMessage := httpErr.Message
+	require.NoError(t, s.GetEstateIdYieldForecast(c, estateUuid))
 
-		assert.Error(t, err)
-		assert.Equal(t, http.StatusNotFound, statusCode)
-		assert.Equal(t, "estate not found", statusMessage)
+	var responseBody generated.YieldForecastResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &responseBody))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 3, responseBody.TreeCount)
+	assert.InDelta(t, 0.5, responseBody.TonnesPerYear, 0.0001)
+	if assert.Len(t, responseBody.Bands, 2) {
+		assert.Equal(t, 1, responseBody.Bands[0].TreeCount)
+		assert.Equal(t, 2, responseBody.Bands[1].TreeCount)
 	}
 }
 
-func TestGetDronePlan_ErrorGetTreesError(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	estateId := uint64(1)
-	estateUuid := uuid.New()
-	mockRepo := repository.NewMockRepositoryInterface(ctrl)
-	mockEstate := models.Estate{
-		ID:               estateId,
-		UUID:             estateUuid.String(),
-		Width:            10,
-		Length:           10,
-		TreeCount:        2,
-		MaxTreeHeight:    10,
-		MinTreeHeight:    1,
-		MedianTreeHeight: 5,
-	}
+func TestGetYieldForecast_EstateNotFound(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	c, _ := newTestContext(http.MethodGet, fmt.Sprintf("/estate/%s/yield-forecast", estateUuid), "")
 
-	s := &Server{
-		Repository: mockRepo,
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(nil, nil)
+
+	assertHTTPError(t, s.GetEstateIdYieldForecast(c, estateUuid), http.StatusNotFound, "estate not found")
+}
+
+func TestGetRaster(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 2, 2)
+	mockTrees := []models.Tree{
+		{UUID: uuid.NewString(), X: 1, Y: 1, Height: 10},
 	}
+	c, rec := newTestContext(http.MethodGet, fmt.Sprintf("/estate/%s/raster?format=asc", estateUuid), "")
 
-	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/estate/%s/drone-plan", estateUuid.String()), nil)
-	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(estate, nil)
+	mockRepo.EXPECT().GetTreesByEstate(c.Request().Context(), estate.ID).Return(&mockTrees, nil)
 
-	rec := httptest.NewRecorder()
-	c := echo.New().NewContext(req, rec)
+	format := generated.Asc
+	require.NoError(t, s.GetEstateIdRaster(c, estateUuid, generated.GetEstateIdRasterParams{
+		Format: &format,
+	}))
 
-	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(&mockEstate, nil)
-	mockRepo.EXPECT().GetTreesByEstate(c.Request().Context(), estateId).Return(nil, errors.New("error"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, echo.MIMETextPlainCharsetUTF8, rec.Header().Get(echo.HeaderContentType))
+	assert.Contains(t, rec.Body.String(), "ncols 2")
+	assert.Contains(t, rec.Body.String(), "10 -9999")
+}
 
-	err := s.GetEstateIdDronePlan(c, estateUuid, generated.GetEstateIdDronePlanParams{})
-	if httpErr, ok := err.(*echo.HTTPError); ok {
-		statusCode := httpErr.Code
-		statusMessage := httpErr.Message
+func TestGetRaster_EstateNotFound(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	c, _ := newTestContext(http.MethodGet, fmt.Sprintf("/estate/%s/raster", estateUuid), "")
 
-		assert.Error(t, err)
-		assert.Equal(t, http.StatusInternalServerError, statusCode)
-		assert.Equal(t, "error", statusMessage)
-	}
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(nil, nil)
+
+	err := s.GetEstateIdRaster(c, estateUuid, generated.GetEstateIdRasterParams{})
+	assertHTTPError(t, err, http.StatusNotFound, "estate not found")
 }
 
-func TestGetDronePlan_WithoutMaxDistance(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	estateId := uint64(1)
-	estateUuid := uuid.New()
-	mockRepo := repository.NewMockRepositoryInterface(ctrl)
-	mockEstate := models.Estate{
-		ID:               estateId,
-		UUID:             estateUuid.String(),
-		Width:            3,
-		Length:           3,
-		TreeCount:        1,
-		MaxTreeHeight:    10,
-		MinTreeHeight:    10,
-		MedianTreeHeight: 10,
+func TestPostDronePlanJob(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 3, 3)
+	mockTrees := []models.Tree{
+		{UUID: uuid.NewString(), X: 1, Y: 1, Height: 10},
 	}
 
-	s := &Server{
-		Repository: mockRepo,
-	}
+	s.Jobs = jobs.NewPool(jobs.NewPoolOptions{Workers: 1})
+	defer s.Jobs.Close(context.Background())
 
-	mockTreesResponse := []models.Tree{
-		{
-			ID:       1,
-			EstateID: mockEstate.ID,
-			UUID:     uuid.NewString(),
-			X:        1,
-			Y:        1,
-			Height:   10,
-		},
-	}
+	c, rec := newTestContext(http.MethodPost, fmt.Sprintf("/estate/%s/drone-plan/jobs", estateUuid), "")
 
-	mockResponses := generated.DronePlanResponse{
-		Distance: 102,
-	}
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(estate, nil)
+	// The trees are loaded by the job, not by the request
+	mockRepo.EXPECT().GetTreesByEstate(gomock.Not(c.Request().Context()), estate.ID).Return(&mockTrees, nil)
+	expectSaveEvents(t, mockRepo, models.EventDronePlanComputed)
 
-	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/estate/%s/drone-plan", estateUuid.String()), nil)
-	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	require.NoError(t, s.PostEstateIdDronePlanJobs(c, estateUuid, generated.PostEstateIdDronePlanJobsParams{}))
 
-	rec := httptest.NewRecorder()
-	c := echo.New().NewContext(req, rec)
+	var responseBody generated.DronePlanJobResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &responseBody))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.NotEmpty(t, responseBody.Id)
 
-	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(&mockEstate, nil)
-	mockRepo.EXPECT().GetTreesByEstate(c.Request().Context(), estateId).Return(&mockTreesResponse, nil)
+	jobUuid := uuid.MustParse(responseBody.Id)
+	for i := 0; i < 1000 && responseBody.Status != generated.Succeeded; i++ {
+		time.Sleep(time.Millisecond)
 
-	if assert.NoError(t, s.GetEstateIdDronePlan(c, estateUuid, generated.GetEstateIdDronePlanParams{})) {
-		var responseBody generated.DronePlanResponse
-		err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
-		if err != nil {
-			t.Fatalf("failed to unmarshal response body: %v", err)
-		}
+		c, rec = newTestContext(http.MethodGet, fmt.Sprintf("/jobs/%s", jobUuid), "")
+		assert.NoError(t, s.GetJobsId(c, jobUuid))
+		responseBody = generated.DronePlanJobResponse{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &responseBody))
+	}
 
-		assert.Equal(t, http.StatusOK, rec.Code)
-		assert.Equal(t, mockResponses, responseBody)
+	assert.Equal(t, generated.Succeeded, responseBody.Status)
+	assert.Equal(t, 100, responseBody.Progress)
+	if assert.NotNil(t, responseBody.Result) {
+		assert.Equal(t, int64(102), responseBody.Result.Distance)
 	}
 }
 
-func TestGetDronePlan_WithMaxDistance(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	estateId := uint64(1)
-	estateUuid := uuid.New()
-	mockRepo := repository.NewMockRepositoryInterface(ctrl)
-	mockEstate := models.Estate{
-		ID:               estateId,
-		UUID:             estateUuid.String(),
-		Width:            3,
-		Length:           3,
-		TreeCount:        1,
-		MaxTreeHeight:    10,
-		MinTreeHeight:    10,
-		MedianTreeHeight: 10,
+func TestPostDronePlanJob_QueueFull(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+
+	s.Jobs = jobs.NewPool(jobs.NewPoolOptions{Workers: 1, QueueSize: 1})
+	defer s.Jobs.Close(context.Background())
+
+	// Keep the worker busy and fill the queue
+	blocked := func(ctx context.Context, progress func(percentage int)) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	for {
+		if _, err := s.Jobs.Submit("", blocked); err != nil {
+			assert.ErrorIs(t, err, jobs.ErrQueueFull)
+			break
+		}
 	}
 
+	c, rec := newTestContext(http.MethodPost, fmt.Sprintf("/estate/%s/drone-plan/jobs", estateUuid), "")
+
+	mockRepo.EXPECT().GetEstate(gomock.Any(), estateUuid.String()).Return(fixtureEstate(estateUuid, 3, 3), nil)
+
+	err := s.PostEstateIdDronePlanJobs(c, estateUuid, generated.PostEstateIdDronePlanJobsParams{})
+	status, response := newErrorResponse(err)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, generated.JobQueueFull, response.Code)
+	assert.Equal(t, "5", rec.Header().Get("Retry-After"))
+}
+
+func TestGetJob_NotFound(t *testing.T) {
+	jobPool := jobs.NewPool(jobs.NewPoolOptions{Workers: 1})
+	defer jobPool.Close(context.Background())
+
 	s := &Server{
-		Repository: mockRepo,
+		Jobs: jobPool,
 	}
 
-	mockTreesResponse := []models.Tree{
-		{
-			ID:       1,
-			EstateID: mockEstate.ID,
-			UUID:     uuid.NewString(),
-			X:        1,
-			Y:        1,
-			Height:   10,
-		},
-	}
+	jobUuid := uuid.New()
+	c, _ := newTestContext(http.MethodGet, fmt.Sprintf("/jobs/%s", jobUuid), "")
 
-	x := int(1)
-	y := int(1)
-	mockResponses := generated.DronePlanResponse{
-		Distance: 10,
-		Rest: &generated.DroneRestResponse{
-			X: &x,
-			Y: &y,
-		},
-	}
+	assertHTTPError(t, s.GetJobsId(c, jobUuid), http.StatusNotFound, "job not found")
+}
 
-	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/estate/%s/drone-plan", estateUuid.String()), nil)
-	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+func TestPostTree_RetryOnVersionConflict(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 10, 10)
+	estate.Version = 1
+	c, rec := newTestContext(http.MethodPost, fmt.Sprintf("/estate/%s/tree", estateUuid), `{"x": 1, "y": 1, "height": 10}`)
+
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).DoAndReturn(func(_ any, _ string) (*models.Estate, error) {
+		fresh := *estate
+		return &fresh, nil
+	}).Times(2)
+	mockRepo.EXPECT().GetTreeByCoordinate(c.Request().Context(), estate.ID, uint16(1), uint16(1)).Return(nil, nil).Times(2)
+	mockRepo.EXPECT().GetTreesByEstate(c.Request().Context(), estate.ID).Return(&[]models.Tree{}, nil).Times(2)
+	expectSaveEvents(t, mockRepo, models.EventTreeAdded, models.EventEstateStatsChanged)
+	gomock.InOrder(
+		mockRepo.EXPECT().SaveTree(c.Request().Context(), gomock.Any()).Return(repository.ErrVersionConflict),
+		mockRepo.EXPECT().SaveTree(c.Request().Context(), gomock.Any()).Return(nil),
+	)
+
+	require.NoError(t, s.PostEstateIdTree(c, estateUuid))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
 
-	rec := httptest.NewRecorder()
-	c := echo.New().NewContext(req, rec)
+func TestPostTree_VersionConflictExhausted(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 10, 10)
+	c, _ := newTestContext(http.MethodPost, fmt.Sprintf("/estate/%s/tree", estateUuid), `{"x": 1, "y": 1, "height": 10}`)
 
-	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).Return(&mockEstate, nil)
-	mockRepo.EXPECT().GetTreesByEstate(c.Request().Context(), estateId).Return(&mockTreesResponse, nil)
+	mockRepo.EXPECT().GetEstate(c.Request().Context(), estateUuid.String()).DoAndReturn(func(_ any, _ string) (*models.Estate, error) {
+		fresh := *estate
+		return &fresh, nil
+	}).Times(maxSaveAttempts)
+	mockRepo.EXPECT().GetTreeByCoordinate(c.Request().Context(), estate.ID, uint16(1), uint16(1)).Return(nil, nil).Times(maxSaveAttempts)
+	mockRepo.EXPECT().GetTreesByEstate(c.Request().Context(), estate.ID).Return(&[]models.Tree{}, nil).Times(maxSaveAttempts)
+	mockRepo.EXPECT().SaveTree(c.Request().Context(), gomock.Any()).Return(repository.ErrVersionConflict).Times(maxSaveAttempts)
 
-	maxDistance := int16(10)
-	if assert.NoError(t, s.GetEstateIdDronePlan(c, estateUuid, generated.GetEstateIdDronePlanParams{
-		MaxDistance: &maxDistance,
-	})) {
-		var responseBody generated.DronePlanResponse
-		err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
-		if err != nil {
-			t.Fatalf("failed to unmarshal response body: %v", err)
-		}
+	err := s.PostEstateIdTree(c, estateUuid)
 
-		assert.Equal(t, http.StatusOK, rec.Code)
-		assert.Equal(t, mockResponses, responseBody)
+	var httpErr *echo.HTTPError
+	if assert.ErrorAs(t, err, &httpErr) {
+		assert.Equal(t, http.StatusConflict, httpErr.Code)
 	}
 }
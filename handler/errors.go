@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/models"
+	"github.com/labstack/echo/v4"
+)
+
+// codeJobNotFound is not a domain error, the jobs only exist in the API
+const codeJobNotFound models.ErrorCode = "job_not_found"
+
+var errJobNotFound = &models.Error{Code: codeJobNotFound, Message: "job not found"}
+
+// codeJobQueueFull is answered with a Retry-After of jobQueueRetryAfter
+// seconds, the queue drains as the workers finish their jobs
+const (
+	codeJobQueueFull   models.ErrorCode = "job_queue_full"
+	jobQueueRetryAfter                  = "5"
+)
+
+var errJobQueueFull = &models.Error{Code: codeJobQueueFull, Message: "too many drone plan jobs are queued, retry later"}
+
+// errorStatuses maps the domain errors to their HTTP status
+var errorStatuses = map[models.ErrorCode]int{
+	models.CodeValidation:          http.StatusBadRequest,
+	models.CodeOutOfBounds:         http.StatusBadRequest,
+	models.CodeDuplicateCoordinate: http.StatusBadRequest,
+	models.CodeEstateNotFound:      http.StatusNotFound,
+	models.CodeTreeNotFound:        http.StatusNotFound,
+	models.CodeTreeRetired:         http.StatusBadRequest,
+	models.CodeEstateFull:          http.StatusBadRequest,
+	models.CodeWebhookNotFound:     http.StatusNotFound,
+	codeJobNotFound:                http.StatusNotFound,
+	codeJobQueueFull:               http.StatusServiceUnavailable,
+}
+
+// statusCodes is the error code of the HTTP errors which are not a domain
+// error, e.g. the ones raised by echo itself
+var statusCodes = map[int]generated.ErrorResponseCode{
+	http.StatusBadRequest:          generated.ValidationFailed,
+	http.StatusUnauthorized:        generated.Unauthorized,
+	http.StatusNotFound:            generated.NotFound,
+	http.StatusMethodNotAllowed:    generated.MethodNotAllowed,
+	http.StatusConflict:            generated.Conflict,
+	http.StatusServiceUnavailable:  generated.Unavailable,
+	http.StatusInternalServerError: generated.InternalError,
+}
+
+// httpError is the error returned by the handlers for err. A domain error
+// keeps its message, any other error is hidden behind a generic message so
+// database or internal details never reach the client.
+func httpError(err error) *echo.HTTPError {
+	var domainErr *models.Error
+	if errors.As(err, &domainErr) {
+		status, ok := errorStatuses[domainErr.Code]
+		if !ok {
+			status = http.StatusBadRequest
+		}
+
+		return &echo.HTTPError{
+			Code:     status,
+			Message:  domainErr.Message,
+			Internal: domainErr,
+		}
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr
+	}
+
+	return &echo.HTTPError{
+		Code:     http.StatusInternalServerError,
+		Message:  "internal server error",
+		Internal: err,
+	}
+}
+
+// newErrorResponse is the body answered for err
+func newErrorResponse(err error) (int, generated.ErrorResponse) {
+	httpErr := httpError(err)
+
+	response := generated.ErrorResponse{
+		Code:    generated.InternalError,
+		Message: http.StatusText(httpErr.Code),
+	}
+
+	if httpErr.Code == http.StatusInternalServerError {
+		response.Message = "internal server error"
+	} else if message, ok := httpErr.Message.(string); ok {
+		response.Message = message
+	}
+
+	var domainErr *models.Error
+	if errors.As(httpErr.Internal, &domainErr) {
+		response.Code = generated.ErrorResponseCode(domainErr.Code)
+
+		if len(domainErr.Fields) > 0 {
+			details := make([]generated.FieldErrorResponse, 0, len(domainErr.Fields))
+			for _, field := range domainErr.Fields {
+				details = append(details, generated.FieldErrorResponse{
+					Field:   field.Field,
+					Message: field.Message,
+				})
+			}
+			response.Details = &details
+		}
+	} else if code, ok := statusCodes[httpErr.Code]; ok {
+		response.Code = code
+	}
+
+	return httpErr.Code, response
+}
+
+// ErrorHandler answers every error with an ErrorResponse, it replaces the
+// default error handler of echo. The internal errors are logged since their
+// details are hidden from the client.
+func (s *Server) ErrorHandler(err error, ctx echo.Context) {
+	if ctx.Response().Committed {
+		return
+	}
+
+	status, response := newErrorResponse(err)
+	if status >= http.StatusInternalServerError {
+		s.logger().ErrorContext(ctx.Request().Context(), "request failed", "error", err)
+	}
+
+	if ctx.Request().Method == http.MethodHead {
+		err = ctx.NoContent(status)
+	} else {
+		err = ctx.JSON(status, response)
+	}
+
+	if err != nil {
+		s.logger().ErrorContext(ctx.Request().Context(), "writing the error response", "error", err)
+	}
+}
+
+// bindError reports a request body which cannot be decoded
+func bindError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return httpError(models.NewValidationError(models.FieldError{
+			Field:   typeErr.Field,
+			Message: "has an invalid value",
+		}))
+	}
+
+	return httpError(models.NewValidationError(models.FieldError{
+		Field:   "body",
+		Message: "is not valid JSON",
+	}))
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/logging"
+	"github.com/SawitProRecruitment/UserService/models"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func serveError(t *testing.T, err error) (int, generated.ErrorResponse) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+
+	(&Server{Logger: logging.Discard()}).ErrorHandler(err, c)
+
+	var response generated.ErrorResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+
+	return rec.Code, response
+}
+
+func TestErrorHandler_DomainError(t *testing.T) {
+	status, response := serveError(t, httpError(models.ErrOutOfBounds))
+
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, generated.OutOfBounds, response.Code)
+	assert.Equal(t, "outside of boundaries", response.Message)
+	assert.Nil(t, response.Details)
+
+	status, response = serveError(t, models.ErrEstateNotFound)
+
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, generated.EstateNotFound, response.Code)
+}
+
+func TestErrorHandler_ValidationDetails(t *testing.T) {
+	err := models.NewValidationError(
+		models.FieldError{Field: "width", Message: "is required"},
+		models.FieldError{Field: "length", Message: "must be at most 50000"},
+	)
+
+	status, response := serveError(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, generated.ValidationFailed, response.Code)
+	assert.Equal(t, "invalid request: width is required, length must be at most 50000", response.Message)
+	if assert.NotNil(t, response.Details) {
+		assert.Equal(t, []generated.FieldErrorResponse{
+			{Field: "width", Message: "is required"},
+			{Field: "length", Message: "must be at most 50000"},
+		}, *response.Details)
+	}
+}
+
+func TestErrorHandler_InternalErrorHidden(t *testing.T) {
+	status, response := serveError(t, errors.New("pq: connection refused"))
+
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, generated.InternalError, response.Code)
+	assert.Equal(t, "internal server error", response.Message)
+}
+
+func TestErrorHandler_EchoError(t *testing.T) {
+	status, response := serveError(t, echo.ErrNotFound)
+
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, generated.NotFound, response.Code)
+	assert.Equal(t, "Not Found", response.Message)
+
+	status, response = serveError(t, echo.ErrMethodNotAllowed)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, status)
+	assert.Equal(t, generated.MethodNotAllowed, response.Code)
+}
+
+func TestPostEstate_InvalidJSON(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := &Server{
+		Repository: repository.NewMockRepositoryInterface(ctrl),
+	}
+
+	requestBody := `{"width": "ten", "length": 10}`
+	req := httptest.NewRequest(http.MethodPost, "/estate", bytes.NewBufferString(requestBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+
+	status, response := newErrorResponse(s.PostEstate(c))
+
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, generated.ValidationFailed, response.Code)
+	if assert.NotNil(t, response.Details) && assert.Len(t, *response.Details, 1) {
+		assert.Equal(t, "width", (*response.Details)[0].Field)
+	}
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/models"
+	"github.com/google/uuid"
+)
+
+// newEvent is the event eventType of estate, its payload is the
+// WebhookEvent of api.yml holding data
+func newEvent(eventType models.EventType, estate *models.Estate, data any) (models.Event, error) {
+	event := models.Event{
+		UUID:           uuid.NewString(),
+		Type:           eventType,
+		OrganisationID: estate.OrganisationID,
+		EstateUUID:     estate.UUID,
+		CreatedAt:      time.Now(),
+	}
+
+	payload, err := json.Marshal(generated.WebhookEvent{
+		Id:        event.UUID,
+		Type:      generated.WebhookEventType(eventType),
+		EstateId:  estate.UUID,
+		CreatedAt: event.CreatedAt,
+		Data:      data,
+	})
+	if err != nil {
+		return models.Event{}, err
+	}
+
+	event.Payload = string(payload)
+	return event, nil
+}
+
+// newTreeEvents are the events of a tree saved with the new stats of its
+// estate, eventType tells what happened to the tree
+func newTreeEvents(eventType models.EventType, tree *models.Tree) ([]models.Event, error) {
+	treeEvent, err := newEvent(eventType, tree.Estate, newTreeHistoryResponse(tree))
+	if err != nil {
+		return nil, err
+	}
+
+	statsEvent, err := newEvent(models.EventEstateStatsChanged, tree.Estate, newEstateStatsResponse(tree.Estate))
+	if err != nil {
+		return nil, err
+	}
+
+	return []models.Event{treeEvent, statsEvent}, nil
+}
+
+// saveDronePlanEvent notifies the drone plan computed by a job for estate,
+// the plans answered by GET /estate/{id}/drone-plan are not notified so that
+// reading writes nothing. The plan changes nothing, the job succeeds even when
+// its event cannot be saved.
+func (s *Server) saveDronePlanEvent(ctx context.Context, estate *models.Estate, plan generated.DronePlanResponse) {
+	event, err := newEvent(models.EventDronePlanComputed, estate, plan)
+	if err == nil {
+		err = s.Repository.SaveEvents(context.WithoutCancel(ctx), []models.Event{event})
+	}
+
+	if err != nil {
+		s.logger().ErrorContext(ctx, "saving the drone plan event", "error", err)
+	}
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// readyTimeout bounds the ping of the repository by /readyz
+const readyTimeout = 2 * time.Second
+
+// Healthz answers as long as the server runs, the database is not checked so
+// an outage does not get the server restarted
+func (s *Server) Healthz(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, HealthResponse{Status: "ok"})
+}
+
+// Readyz answers whether the server can take requests: the repository must
+// answer a ping, and the server must not be shutting down
+func (s *Server) Readyz(ctx echo.Context) error {
+	if s.closed.Load() {
+		return ctx.JSON(http.StatusServiceUnavailable, HealthResponse{
+			Status: "unavailable",
+			Error:  "shutting down",
+		})
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx.Request().Context(), readyTimeout)
+	defer cancel()
+
+	if err := s.Repository.Ping(pingCtx); err != nil {
+		s.logger().WarnContext(pingCtx, "repository not ready", "error", err)
+		return ctx.JSON(http.StatusServiceUnavailable, HealthResponse{
+			Status: "unavailable",
+			Error:  "repository unavailable",
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, HealthResponse{Status: "ok"})
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/logging"
+	"github.com/SawitProRecruitment/UserService/models"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func serveHealth(t *testing.T, s *Server, handler echo.HandlerFunc) (int, HealthResponse) {
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rec := httptest.NewRecorder()
+	assert.NoError(t, handler(echo.New().NewContext(req, rec)))
+
+	var response HealthResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+
+	return rec.Code, response
+}
+
+func TestHealthz(t *testing.T) {
+	s := NewServer(NewServerOptions{})
+
+	status, response := serveHealth(t, s, s.Healthz)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "ok", response.Status)
+}
+
+func TestReadyz(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := repository.NewMockRepositoryInterface(ctrl)
+	s := NewServer(NewServerOptions{Repository: mockRepo, Logger: logging.Discard()})
+
+	mockRepo.EXPECT().Ping(gomock.Any()).Return(nil)
+	status, response := serveHealth(t, s, s.Readyz)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "ok", response.Status)
+
+	mockRepo.EXPECT().Ping(gomock.Any()).Return(errors.New("connection refused"))
+	status, response = serveHealth(t, s, s.Readyz)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, HealthResponse{Status: "unavailable", Error: "repository unavailable"}, response)
+
+	// No ping once shutting down
+	assert.NoError(t, s.Close(context.Background()))
+	status, response = serveHealth(t, s, s.Readyz)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "shutting down", response.Error)
+}
+
+func TestClose_CancelsDronePlans(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	estateUuid := uuid.New()
+	mockRepo := repository.NewMockRepositoryInterface(ctrl)
+	mockEstate := models.Estate{
+		ID:     1,
+		UUID:   estateUuid.String(),
+		Width:  50000,
+		Length: 50000,
+	}
+
+	s := NewServer(NewServerOptions{Repository: mockRepo})
+	assert.NoError(t, s.Close(context.Background()))
+
+	mockRepo.EXPECT().GetEstate(gomock.Any(), estateUuid.String()).Return(&mockEstate, nil).Times(2)
+	mockRepo.EXPECT().GetTreesByEstate(gomock.Any(), uint64(1)).Return(&[]models.Tree{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/estate/%s/drone-plan", estateUuid.String()), nil)
+	err := s.GetEstateIdDronePlan(echo.New().NewContext(req, httptest.NewRecorder()), estateUuid, generated.GetEstateIdDronePlanParams{})
+	if httpErr, ok := err.(*echo.HTTPError); ok {
+		assert.Equal(t, http.StatusServiceUnavailable, httpErr.Code)
+	} else {
+		t.Errorf("expected an HTTP error, got %v", err)
+	}
+
+	// The job pool is closed too
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/estate/%s/drone-plan/jobs", estateUuid.String()), nil)
+	err = s.PostEstateIdDronePlanJobs(echo.New().NewContext(req, httptest.NewRecorder()), estateUuid, generated.PostEstateIdDronePlanJobsParams{})
+	if httpErr, ok := err.(*echo.HTTPError); ok {
+		assert.Equal(t, http.StatusServiceUnavailable, httpErr.Code)
+	} else {
+		t.Errorf("expected an HTTP error, got %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// maxSaveAttempts is how many times a tree is saved before giving up when the
+// estate keeps being modified by concurrent requests
+const maxSaveAttempts = 10
+
+// retryBackoff is the base wait between two attempts, a random jitter spreads
+// the concurrent requests so they do not conflict again right away
+const retryBackoff = 5 * time.Millisecond
+
+// retryOnConflict runs fn again when it fails because the estate was modified
+// after it was read, any other error is returned as is.
+func retryOnConflict(fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if !errors.Is(err, repository.ErrVersionConflict) {
+			return err
+		}
+
+		if attempt >= maxSaveAttempts {
+			return echo.NewHTTPError(http.StatusConflict, "estate is being modified, please retry")
+		}
+
+		time.Sleep(time.Duration(rand.Int63n(int64(retryBackoff) * int64(attempt))))
+	}
+}
@@ -1,17 +1,171 @@
 package handler
 
-import "github.com/SawitProRecruitment/UserService/repository"
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/SawitProRecruitment/UserService/cache"
+	"github.com/SawitProRecruitment/UserService/jobs"
+	"github.com/SawitProRecruitment/UserService/logging"
+	"github.com/SawitProRecruitment/UserService/metrics"
+	"github.com/SawitProRecruitment/UserService/models"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
 
 type Server struct {
 	Repository repository.RepositoryInterface
+	YieldCurve models.YieldCurve
+	Jobs       *jobs.Pool
+	PlanCache  cache.Cache
+	Logger     *slog.Logger
+	// FlightTrace receives the moves of the drone plans, they are not traced
+	// when nil
+	FlightTrace *slog.Logger
+	// Metrics records the drone plans, they are not recorded when nil
+	Metrics *metrics.Metrics
+	// TracerProvider receives the spans of the drone plans, they are not
+	// recorded when nil
+	TracerProvider trace.TracerProvider
+	// DefaultMaxDistance is the battery of the drone when the request gives
+	// no max_distance, the battery never drains when zero
+	DefaultMaxDistance uint64
+	// WebhookPolicy is what the URLs of the webhooks may reach
+	WebhookPolicy models.WebhookPolicy
+
+	// flights is cancelled by Close, stopping the drone plans of the requests
+	flights       context.Context
+	cancelFlights context.CancelFunc
+	closed        atomic.Bool
 }
 
 type NewServerOptions struct {
 	Repository repository.RepositoryInterface
+	YieldCurve models.YieldCurve
+	Jobs       *jobs.Pool
+	PlanCache  cache.Cache
+	// Logger is slog.Default() when nil
+	Logger      *slog.Logger
+	FlightTrace *slog.Logger
+	Metrics     *metrics.Metrics
+	// TracerProvider is the one of the spans of NewTracedServer too
+	TracerProvider     trace.TracerProvider
+	DefaultMaxDistance uint64
+	WebhookPolicy      models.WebhookPolicy
 }
 
 func NewServer(opts NewServerOptions) *Server {
+	yieldCurve := opts.YieldCurve
+	if yieldCurve == nil {
+		yieldCurve = models.DefaultYieldCurve()
+	}
+
+	jobPool := opts.Jobs
+	if jobPool == nil {
+		jobPool = jobs.NewPool(jobs.NewPoolOptions{})
+	}
+
+	planCache := opts.PlanCache
+	if planCache == nil {
+		planCache = cache.NewLRU(1000)
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	flights, cancelFlights := context.WithCancel(context.Background())
+
 	return &Server{
-		Repository: opts.Repository,
+		Repository:         opts.Repository,
+		YieldCurve:         yieldCurve,
+		Jobs:               jobPool,
+		PlanCache:          planCache,
+		Logger:             logger,
+		FlightTrace:        opts.FlightTrace,
+		Metrics:            opts.Metrics,
+		TracerProvider:     opts.TracerProvider,
+		DefaultMaxDistance: opts.DefaultMaxDistance,
+		WebhookPolicy:      opts.WebhookPolicy,
+		flights:            flights,
+		cancelFlights:      cancelFlights,
+	}
+}
+
+// Close cancels the drone plans still flying, in the requests and in the
+// jobs, and waits until the jobs stop or ctx is done. /readyz fails from then
+// on.
+func (s *Server) Close(ctx context.Context) error {
+	s.closed.Store(true)
+	if s.cancelFlights != nil {
+		s.cancelFlights()
+	}
+
+	if s.Jobs == nil {
+		return nil
+	}
+
+	return s.Jobs.Close(ctx)
+}
+
+// logger is the logger of the server, also when the server was not built by
+// NewServer
+func (s *Server) logger() *slog.Logger {
+	if s.Logger == nil {
+		return slog.Default()
 	}
+
+	return s.Logger
+}
+
+// newDrone is a drone tracing its flight to FlightTrace, mapping the trees of
+// the estate is the first phase of the simulation
+func (s *Server) newDrone(ctx context.Context, estate *models.Estate, trees *[]models.Tree, maxDistance *uint64) *models.Drone {
+	_, span := s.tracer().Start(ctx, "drone.map_trees", trace.WithAttributes(
+		attribute.String("estate.id", estate.UUID),
+		attribute.Int("estate.width", int(estate.Width)),
+		attribute.Int("estate.length", int(estate.Length)),
+		attribute.Int("estate.trees", len(*trees)),
+	))
+	drone := models.NewDrone(estate, trees, maxDistance)
+	span.End()
+	if s.FlightTrace != nil {
+		drone.Trace = s.FlightTrace.With(
+			slog.String("request_id", logging.RequestID(ctx)),
+			slog.String("estate_id", estate.UUID),
+		)
+	}
+
+	return drone
+}
+
+// errShuttingDown answers the drone plans cancelled by Close
+var errShuttingDown = echo.NewHTTPError(http.StatusServiceUnavailable, "server shutting down")
+
+// flyDronePlan is flyDrone for the plans answered in the request. They are
+// cached so they are flown to the end even if the caller left, only Close
+// stops them.
+func (s *Server) flyDronePlan(ctx context.Context, drone *models.Drone) error {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+
+	if s.flights != nil {
+		if s.flights.Err() != nil {
+			return errShuttingDown
+		}
+
+		stop := context.AfterFunc(s.flights, cancel)
+		defer stop()
+	}
+
+	if err := s.flyDrone(ctx, drone, nil); err != nil {
+		return errShuttingDown
+	}
+
+	return nil
 }
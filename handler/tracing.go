@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/models"
+	"github.com/SawitProRecruitment/UserService/tracing"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/SawitProRecruitment/UserService/handler"
+
+// tracer is the tracer of the server, recording nothing without a
+// TracerProvider
+func (s *Server) tracer() trace.Tracer {
+	return tracing.Tracer(s.TracerProvider, tracerName)
+}
+
+// TracedServer opens a span around every method of the server, named after
+// the method. The queries and the drone plan of the request are its children.
+type TracedServer struct {
+	*Server
+}
+
+var _ generated.ServerInterface = (*TracedServer)(nil)
+
+func NewTracedServer(s *Server) *TracedServer {
+	return &TracedServer{Server: s}
+}
+
+// trace runs the method fn in the span name, the span holds the error fn
+// returned if any, it only fails on the errors answered with a 5xx status
+func (t *TracedServer) trace(ctx echo.Context, name string, fn func() error) error {
+	req := ctx.Request()
+	spanCtx, span := t.tracer().Start(req.Context(), "handler."+name)
+	defer span.End()
+
+	ctx.SetRequest(req.WithContext(spanCtx))
+	err := fn()
+	if err != nil {
+		span.RecordError(err)
+
+		status := http.StatusInternalServerError
+		var httpErr *echo.HTTPError
+		if errors.As(err, &httpErr) {
+			status = httpErr.Code
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+
+	return err
+}
+
+func (t *TracedServer) PostEstate(ctx echo.Context) error {
+	return t.trace(ctx, "PostEstate", func() error {
+		return t.Server.PostEstate(ctx)
+	})
+}
+
+func (t *TracedServer) GetEstateIdDronePlan(ctx echo.Context, id generated.EstateIDPathParam, params generated.GetEstateIdDronePlanParams) error {
+	return t.trace(ctx, "GetEstateIdDronePlan", func() error {
+		return t.Server.GetEstateIdDronePlan(ctx, id, params)
+	})
+}
+
+func (t *TracedServer) PostEstateIdDronePlanJobs(ctx echo.Context, id generated.EstateIDPathParam, params generated.PostEstateIdDronePlanJobsParams) error {
+	return t.trace(ctx, "PostEstateIdDronePlanJobs", func() error {
+		return t.Server.PostEstateIdDronePlanJobs(ctx, id, params)
+	})
+}
+
+func (t *TracedServer) GetEstateIdPlotXYHistory(ctx echo.Context, id generated.EstateIDPathParam, x generated.PlotXPathParam, y generated.PlotYPathParam) error {
+	return t.trace(ctx, "GetEstateIdPlotXYHistory", func() error {
+		return t.Server.GetEstateIdPlotXYHistory(ctx, id, x, y)
+	})
+}
+
+func (t *TracedServer) GetEstateIdRaster(ctx echo.Context, id generated.EstateIDPathParam, params generated.GetEstateIdRasterParams) error {
+	return t.trace(ctx, "GetEstateIdRaster", func() error {
+		return t.Server.GetEstateIdRaster(ctx, id, params)
+	})
+}
+
+func (t *TracedServer) GetEstateIdStats(ctx echo.Context, id generated.EstateIDPathParam) error {
+	return t.trace(ctx, "GetEstateIdStats", func() error {
+		return t.Server.GetEstateIdStats(ctx, id)
+	})
+}
+
+func (t *TracedServer) PostEstateIdTree(ctx echo.Context, id generated.EstateIDPathParam) error {
+	return t.trace(ctx, "PostEstateIdTree", func() error {
+		return t.Server.PostEstateIdTree(ctx, id)
+	})
+}
+
+func (t *TracedServer) PostEstateIdTreeTreeIdRetire(ctx echo.Context, id generated.EstateIDPathParam, treeId generated.TreeIDPathParam) error {
+	return t.trace(ctx, "PostEstateIdTreeTreeIdRetire", func() error {
+		return t.Server.PostEstateIdTreeTreeIdRetire(ctx, id, treeId)
+	})
+}
+
+func (t *TracedServer) GetEstateIdYieldForecast(ctx echo.Context, id generated.EstateIDPathParam) error {
+	return t.trace(ctx, "GetEstateIdYieldForecast", func() error {
+		return t.Server.GetEstateIdYieldForecast(ctx, id)
+	})
+}
+
+func (t *TracedServer) GetJobsId(ctx echo.Context, id generated.JobIDPathParam) error {
+	return t.trace(ctx, "GetJobsId", func() error {
+		return t.Server.GetJobsId(ctx, id)
+	})
+}
+
+func (t *TracedServer) PostWebhooks(ctx echo.Context) error {
+	return t.trace(ctx, "PostWebhooks", func() error {
+		return t.Server.PostWebhooks(ctx)
+	})
+}
+
+func (t *TracedServer) GetWebhooks(ctx echo.Context) error {
+	return t.trace(ctx, "GetWebhooks", func() error {
+		return t.Server.GetWebhooks(ctx)
+	})
+}
+
+func (t *TracedServer) DeleteWebhooksId(ctx echo.Context, id generated.WebhookIDPathParam) error {
+	return t.trace(ctx, "DeleteWebhooksId", func() error {
+		return t.Server.DeleteWebhooksId(ctx, id)
+	})
+}
+
+func (t *TracedServer) GetWebhooksIdDeliveries(ctx echo.Context, id generated.WebhookIDPathParam, params generated.GetWebhooksIdDeliveriesParams) error {
+	return t.trace(ctx, "GetWebhooksIdDeliveries", func() error {
+		return t.Server.GetWebhooksIdDeliveries(ctx, id, params)
+	})
+}
+
+// flyDrone simulates the flight of drone in the span drone.flight, and
+// records it in the metrics. The jobs fly in a context of their own, link
+// then points to the request which submitted the job.
+func (s *Server) flyDrone(ctx context.Context, drone *models.Drone, progress func(percentage int), links ...trace.Link) error {
+	ctx, span := s.tracer().Start(ctx, "drone.flight", trace.WithLinks(links...))
+	defer span.End()
+
+	start := time.Now()
+	if err := drone.CalculateFlightContext(ctx, progress); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	s.Metrics.ObserveDronePlan(time.Since(start), drone.PlotsVisited)
+
+	span.SetAttributes(
+		attribute.Int64("drone.plots_visited", int64(drone.PlotsVisited)),
+		attribute.Int64("drone.travelled", int64(drone.Travelled)),
+		attribute.Bool("drone.battery_drains", drone.BatteryDrains),
+	)
+
+	return nil
+}
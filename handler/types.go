@@ -1,16 +1,26 @@
 package handler
 
+// The request bodies are validated against api.yml by RequestValidator before
+// reaching the handlers, the constraints are not repeated here.
+
 type EstateRequest struct {
-	Width  uint16 `json:"width" validate:"required,min=1,max=50000"`
-	Length uint16 `json:"length" validate:"required,min=1,max=50000"`
+	Width  int `json:"width"`
+	Length int `json:"length"`
 }
 
 type TreeRequest struct {
-	Height int `json:"height" validate:"required,min=1,max=30"`
-	X      int `json:"x" validate:"required,min=1,max=50000"`
-	Y      int `json:"y" validate:"required,min=1,max=50000"`
+	Height int `json:"height"`
+	X      int `json:"x"`
+	Y      int `json:"y"`
 }
 
 type DronePlanRequest struct {
-	MaxDistance *uint16 `query:"max_distance"`
+	MaxDistance *uint64 `query:"max_distance"`
+}
+
+// HealthResponse is the body of /healthz and /readyz, which are not part of
+// api.yml
+type HealthResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
 }
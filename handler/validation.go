@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/models"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/labstack/echo/v4"
+)
+
+func init() {
+	openapi3.DefineStringFormat("uuid", openapi3.FormatOfStringForUUIDOfRFC4122)
+}
+
+// RequestValidator checks the parameters and the body of every request
+// against api.yml, the constraints are only declared in the spec. The
+// requests which do not match any operation are left to the router.
+func RequestValidator() (echo.MiddlewareFunc, error) {
+	spec, err := generated.GetSwagger()
+	if err != nil {
+		return nil, fmt.Errorf("loading the spec: %w", err)
+	}
+
+	// The routes are matched on the path only, whatever the host
+	spec.Servers = nil
+
+	router, err := legacy.NewRouter(spec)
+	if err != nil {
+		return nil, fmt.Errorf("building the spec router: %w", err)
+	}
+
+	options := &openapi3filter.Options{
+		MultiError:         true,
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+
+			// The router answers a new RouteError rather than ErrPathNotFound
+			// or ErrMethodNotAllowed
+			var routeErr *routers.RouteError
+			route, pathParams, err := router.FindRoute(req)
+			if errors.As(err, &routeErr) {
+				return next(ctx)
+			} else if err != nil {
+				return httpError(err)
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    req,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    options,
+			}
+
+			if err := openapi3filter.ValidateRequest(req.Context(), input); err != nil {
+				return httpError(requestValidationError(err))
+			}
+
+			return next(ctx)
+		}
+	}, nil
+}
+
+// requestValidationError lists the fields rejected by the spec
+func requestValidationError(err error) error {
+	var fields []models.FieldError
+
+	for _, err := range flattenErrors(err) {
+		var requestErr *openapi3filter.RequestError
+		if !errors.As(err, &requestErr) {
+			fields = append(fields, models.FieldError{Field: "request", Message: err.Error()})
+			continue
+		}
+
+		field := "body"
+		if requestErr.Parameter != nil {
+			field = requestErr.Parameter.Name
+		}
+
+		fields = append(fields, requestFieldErrors(field, requestErr)...)
+	}
+
+	return models.NewValidationError(fields...)
+}
+
+func requestFieldErrors(field string, requestErr *openapi3filter.RequestError) []models.FieldError {
+	if errors.Is(requestErr.Err, openapi3filter.ErrInvalidRequired) || errors.Is(requestErr.Err, openapi3filter.ErrInvalidEmptyValue) {
+		return []models.FieldError{{Field: field, Message: "is required"}}
+	}
+
+	var parseErr *openapi3filter.ParseError
+	if errors.As(requestErr.Err, &parseErr) {
+		if requestErr.Parameter != nil {
+			return []models.FieldError{{Field: field, Message: "has an invalid value"}}
+		}
+		return []models.FieldError{{Field: field, Message: "is not valid JSON"}}
+	}
+
+	var fields []models.FieldError
+	for _, err := range flattenErrors(requestErr.Err) {
+		var schemaErr *openapi3.SchemaError
+		if !errors.As(err, &schemaErr) {
+			continue
+		}
+
+		name := field
+		if path := schemaErr.JSONPointer(); requestErr.Parameter == nil && len(path) > 0 {
+			name = strings.Join(path, ".")
+		}
+
+		fields = append(fields, models.FieldError{Field: name, Message: schemaErrorMessage(schemaErr)})
+	}
+
+	if len(fields) == 0 {
+		fields = append(fields, models.FieldError{Field: field, Message: requestErr.Reason})
+	}
+
+	return fields
+}
+
+// schemaErrorMessage words the schema errors like the other validation errors
+func schemaErrorMessage(err *openapi3.SchemaError) string {
+	schema := err.Schema
+
+	switch err.SchemaField {
+	case "required":
+		return "is required"
+	case "minimum":
+		if schema != nil && schema.Min != nil {
+			return fmt.Sprintf("must be at least %v", *schema.Min)
+		}
+	case "maximum":
+		if schema != nil && schema.Max != nil {
+			return fmt.Sprintf("must be at most %v", *schema.Max)
+		}
+	case "type":
+		if schema != nil && len(schema.Type.Slice()) > 0 {
+			return "must be of type " + strings.Join(schema.Type.Slice(), " or ")
+		}
+	case "enum":
+		if schema != nil {
+			values := make([]string, 0, len(schema.Enum))
+			for _, value := range schema.Enum {
+				values = append(values, fmt.Sprint(value))
+			}
+			return "must be one of " + strings.Join(values, ", ")
+		}
+	case "format":
+		if schema != nil {
+			return "must be a valid " + schema.Format
+		}
+	}
+
+	return err.Reason
+}
+
+// flattenErrors unwraps the openapi3.MultiError returned with the
+// MultiError option, the wrapping errors are kept as they name the field
+func flattenErrors(err error) []error {
+	multiErr, ok := err.(openapi3.MultiError)
+	if !ok {
+		return []error{err}
+	}
+
+	var errs []error
+	for _, err := range multiErr {
+		errs = append(errs, flattenErrors(err)...)
+	}
+
+	return errs
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/models"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// newValidatedEcho serves s behind the request validator, the way main does
+func newValidatedEcho(t *testing.T, s *Server) *echo.Echo {
+	requestValidator, err := RequestValidator()
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.HTTPErrorHandler = s.ErrorHandler
+	generated.RegisterHandlers(e, s)
+	e.Use(requestValidator)
+
+	return e
+}
+
+func serveValidated(t *testing.T, method string, target string, body string) (int, generated.ErrorResponse) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := &Server{
+		Repository: repository.NewMockRepositoryInterface(ctrl),
+	}
+
+	var req *http.Request
+	if body == "" {
+		req = httptest.NewRequest(method, target, nil)
+	} else {
+		req = httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+
+	rec := httptest.NewRecorder()
+	newValidatedEcho(t, s).ServeHTTP(rec, req)
+
+	var response generated.ErrorResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+
+	return rec.Code, response
+}
+
+func TestRequestValidator_Body(t *testing.T) {
+	status, response := serveValidated(t, http.MethodPost, "/estate", `{"width": 0, "length": 60000}`)
+
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, generated.ValidationFailed, response.Code)
+	if assert.NotNil(t, response.Details) {
+		assert.ElementsMatch(t, []generated.FieldErrorResponse{
+			{Field: "width", Message: "must be at least 1"},
+			{Field: "length", Message: "must be at most 50000"},
+		}, *response.Details)
+	}
+}
+
+func TestRequestValidator_MissingField(t *testing.T) {
+	target := "/estate/" + uuid.NewString() + "/tree"
+	status, response := serveValidated(t, http.MethodPost, target, `{"x": 1, "y": 1}`)
+
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, generated.ValidationFailed, response.Code)
+	if assert.NotNil(t, response.Details) {
+		assert.Equal(t, []generated.FieldErrorResponse{
+			{Field: "height", Message: "is required"},
+		}, *response.Details)
+	}
+}
+
+func TestRequestValidator_InvalidJSON(t *testing.T) {
+	status, response := serveValidated(t, http.MethodPost, "/estate", `{"width": `)
+
+	assert.Equal(t, http.StatusBadRequest, status)
+	if assert.NotNil(t, response.Details) {
+		assert.Equal(t, []generated.FieldErrorResponse{
+			{Field: "body", Message: "is not valid JSON"},
+		}, *response.Details)
+	}
+}
+
+func TestRequestValidator_Parameters(t *testing.T) {
+	status, response := serveValidated(t, http.MethodGet, "/estate/not-an-uuid/stats", "")
+
+	assert.Equal(t, http.StatusBadRequest, status)
+	if assert.NotNil(t, response.Details) {
+		assert.Equal(t, []generated.FieldErrorResponse{
+			{Field: "id", Message: "must be a valid uuid"},
+		}, *response.Details)
+	}
+
+	target := "/estate/" + uuid.NewString() + "/raster?metric=age&size=0"
+	status, response = serveValidated(t, http.MethodGet, target, "")
+
+	assert.Equal(t, http.StatusBadRequest, status)
+	if assert.NotNil(t, response.Details) {
+		assert.ElementsMatch(t, []generated.FieldErrorResponse{
+			{Field: "metric", Message: "must be one of height, density"},
+			{Field: "size", Message: "must be at least 1"},
+		}, *response.Details)
+	}
+
+	target = "/estate/" + uuid.NewString() + "/drone-plan?max_distance=far"
+	status, response = serveValidated(t, http.MethodGet, target, "")
+
+	assert.Equal(t, http.StatusBadRequest, status)
+	if assert.NotNil(t, response.Details) {
+		assert.Equal(t, []generated.FieldErrorResponse{
+			{Field: "max_distance", Message: "has an invalid value"},
+		}, *response.Details)
+	}
+}
+
+func TestRequestValidator_UnknownRoute(t *testing.T) {
+	status, response := serveValidated(t, http.MethodGet, "/unknown", "")
+
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, generated.NotFound, response.Code)
+}
+
+func TestRequestValidator_ValidRequest(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	expectSaveEvents(t, mockRepo, models.EventEstateCreated)
+	mockRepo.EXPECT().SaveEstate(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, estate *models.Estate) error {
+		assert.Equal(t, uint16(10), estate.Width)
+		assert.Equal(t, uint16(20), estate.Length)
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/estate", bytes.NewBufferString(`{"width": 10, "length": 20}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	rec := httptest.NewRecorder()
+	newValidatedEcho(t, s).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
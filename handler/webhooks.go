@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/SawitProRecruitment/UserService/auth"
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/models"
+	"github.com/labstack/echo/v4"
+)
+
+// defaultDeliveriesLimit is the number of deliveries answered without limit
+const defaultDeliveriesLimit = 50
+
+func (s *Server) PostWebhooks(ctx echo.Context) error {
+	context := ctx.Request().Context()
+	body := new(generated.WebhookRequest)
+	if err := ctx.Bind(body); err != nil {
+		return bindError(err)
+	}
+
+	// Start Check if the estate exist and belongs to the caller
+	var estateUUID *string
+	if body.EstateId != nil {
+		estate, err := s.Repository.GetEstate(context, body.EstateId.String())
+		if err != nil {
+			return httpError(err)
+		}
+
+		if !canAccessEstate(context, estate) {
+			return httpError(models.ErrEstateNotFound)
+		}
+		estateUUID = &estate.UUID
+	}
+	// Done Check if the estate exist and belongs to the caller
+
+	events := make(models.EventTypes, 0, len(body.Events))
+	for _, eventType := range body.Events {
+		events = append(events, models.EventType(eventType))
+	}
+
+	webhook, err := models.NewWebhook(s.WebhookPolicy, auth.OrganisationID(context), estateUUID, body.Url, events)
+	if err != nil {
+		return httpError(err)
+	}
+
+	if err := s.Repository.SaveWebhook(context, webhook); err != nil {
+		return httpError(err)
+	}
+
+	// The secret is only answered now, the caller must keep it
+	response := newWebhookResponse(webhook)
+	response.Secret = &webhook.Secret
+
+	return ctx.JSON(http.StatusCreated, response)
+}
+
+func (s *Server) GetWebhooks(ctx echo.Context) error {
+	context := ctx.Request().Context()
+
+	webhooks, err := s.Repository.GetWebhooksByOrganisation(context, auth.OrganisationID(context))
+	if err != nil {
+		return httpError(err)
+	}
+
+	responses := make([]generated.WebhookResponse, 0, len(*webhooks))
+	for i := range *webhooks {
+		responses = append(responses, newWebhookResponse(&(*webhooks)[i]))
+	}
+
+	return ctx.JSON(http.StatusOK, generated.WebhookListResponse{
+		Webhooks: responses,
+	})
+}
+
+func (s *Server) DeleteWebhooksId(ctx echo.Context, id generated.WebhookIDPathParam) error {
+	context := ctx.Request().Context()
+
+	webhook, err := s.getWebhook(context, id.String())
+	if err != nil {
+		return err
+	}
+
+	if err := s.Repository.DeleteWebhook(context, webhook.ID); err != nil {
+		return httpError(err)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (s *Server) GetWebhooksIdDeliveries(ctx echo.Context, id generated.WebhookIDPathParam, params generated.GetWebhooksIdDeliveriesParams) error {
+	context := ctx.Request().Context()
+
+	limit := defaultDeliveriesLimit
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+
+	if limit < 1 || limit > 100 {
+		return httpError(models.NewValidationError(models.FieldError{
+			Field:   "limit",
+			Message: "must be between 1 and 100",
+		}))
+	}
+
+	webhook, err := s.getWebhook(context, id.String())
+	if err != nil {
+		return err
+	}
+
+	deliveries, err := s.Repository.GetWebhookDeliveries(context, webhook.ID, limit)
+	if err != nil {
+		return httpError(err)
+	}
+
+	responses := make([]generated.WebhookDeliveryResponse, 0, len(*deliveries))
+	for i := range *deliveries {
+		responses = append(responses, newWebhookDeliveryResponse(&(*deliveries)[i]))
+	}
+
+	return ctx.JSON(http.StatusOK, generated.WebhookDeliveryListResponse{
+		Deliveries: responses,
+	})
+}
+
+// getWebhook is the webhook uuid of the caller, the webhooks of the other
+// organisations are not found either
+func (s *Server) getWebhook(ctx context.Context, uuid string) (*models.Webhook, error) {
+	webhook, err := s.Repository.GetWebhook(ctx, uuid)
+	if err != nil {
+		return nil, httpError(err)
+	}
+
+	if webhook == nil || webhook.OrganisationID != auth.OrganisationID(ctx) {
+		return nil, httpError(models.ErrWebhookNotFound)
+	}
+
+	return webhook, nil
+}
+
+func newWebhookResponse(webhook *models.Webhook) generated.WebhookResponse {
+	events := make([]generated.WebhookEventType, 0, len(webhook.Events))
+	for _, eventType := range webhook.Events {
+		events = append(events, generated.WebhookEventType(eventType))
+	}
+
+	return generated.WebhookResponse{
+		Id:        webhook.UUID,
+		Url:       webhook.URL,
+		Events:    events,
+		EstateId:  webhook.EstateUUID,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+func newWebhookDeliveryResponse(delivery *models.WebhookDelivery) generated.WebhookDeliveryResponse {
+	response := generated.WebhookDeliveryResponse{
+		Id:          delivery.UUID,
+		EventId:     delivery.EventUUID,
+		EventType:   generated.WebhookEventType(delivery.EventType),
+		Status:      generated.WebhookDeliveryResponseStatus(delivery.Status),
+		Attempts:    delivery.Attempts,
+		DeliveredAt: delivery.DeliveredAt,
+		CreatedAt:   delivery.CreatedAt,
+		UpdatedAt:   delivery.UpdatedAt,
+	}
+
+	if delivery.Status == models.DeliveryPending {
+		nextAttemptAt := delivery.NextAttemptAt
+		response.NextAttemptAt = &nextAttemptAt
+	}
+
+	if delivery.LastStatusCode != 0 {
+		statusCode := delivery.LastStatusCode
+		response.ResponseStatus = &statusCode
+	}
+
+	if delivery.LastError != "" {
+		lastError := delivery.LastError
+		response.Error = &lastError
+	}
+
+	return response
+}
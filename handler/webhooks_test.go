@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/models"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPostWebhooks(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewBufferString(`{"url": "https://example.com/hooks", "events": ["tree.added", "tree.retired"]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	c, rec := newOrganisationContext(req, "org-a")
+
+	var saved *models.Webhook
+	mockRepo.EXPECT().SaveWebhook(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, webhook *models.Webhook) error {
+		saved = webhook
+		return nil
+	})
+
+	require.NoError(t, s.PostWebhooks(c))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var responseBody generated.WebhookResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &responseBody))
+
+	assert.Equal(t, "org-a", saved.OrganisationID)
+	assert.Nil(t, saved.EstateUUID)
+	assert.Equal(t, saved.UUID, responseBody.Id)
+	assert.Equal(t, []generated.WebhookEventType{generated.TreeAddedEvent, generated.TreeRetiredEvent}, responseBody.Events)
+	if assert.NotNil(t, responseBody.Secret) {
+		assert.Equal(t, saved.Secret, *responseBody.Secret)
+	}
+}
+
+func TestPostWebhooks_OtherOrganisationEstate(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	estateUuid := uuid.New()
+	estate := fixtureEstate(estateUuid, 10, 10)
+	estate.OrganisationID = "org-a"
+
+	requestBody := fmt.Sprintf(`{"url": "https://example.com/hooks", "events": ["tree.added"], "estate_id": "%s"}`, estateUuid)
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewBufferString(requestBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	c, _ := newOrganisationContext(req, "org-b")
+
+	mockRepo.EXPECT().GetEstate(gomock.Any(), estateUuid.String()).Return(estate, nil)
+
+	assertHTTPError(t, s.PostWebhooks(c), http.StatusNotFound, "estate not found")
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	webhookUuid := uuid.New()
+	mockWebhook := models.Webhook{
+		ID:             7,
+		UUID:           webhookUuid.String(),
+		OrganisationID: "org-a",
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/webhooks/%s", webhookUuid), nil)
+	c, rec := newOrganisationContext(req, "org-a")
+
+	mockRepo.EXPECT().GetWebhook(gomock.Any(), webhookUuid.String()).Return(&mockWebhook, nil)
+	mockRepo.EXPECT().DeleteWebhook(gomock.Any(), uint64(7)).Return(nil)
+
+	assert.NoError(t, s.DeleteWebhooksId(c, webhookUuid))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestDeleteWebhook_OtherOrganisation(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	webhookUuid := uuid.New()
+	mockWebhook := models.Webhook{
+		ID:             7,
+		UUID:           webhookUuid.String(),
+		OrganisationID: "org-a",
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/webhooks/%s", webhookUuid), nil)
+	c, _ := newOrganisationContext(req, "org-b")
+
+	mockRepo.EXPECT().GetWebhook(gomock.Any(), webhookUuid.String()).Return(&mockWebhook, nil)
+
+	assertHTTPError(t, s.DeleteWebhooksId(c, webhookUuid), http.StatusNotFound, "webhook not found")
+}
+
+func TestGetWebhookDeliveries(t *testing.T) {
+	s, mockRepo := newTestServer(t)
+	webhookUuid := uuid.New()
+	mockWebhook := models.Webhook{
+		ID:             7,
+		UUID:           webhookUuid.String(),
+		OrganisationID: "org-a",
+	}
+
+	now := time.Now()
+	retryAt := now.Add(time.Minute)
+	retried := models.NewWebhookDelivery(&mockWebhook, &models.Event{UUID: uuid.NewString(), Type: models.EventTreeAdded})
+	retried.Fail(503, "unexpected status 503 Service Unavailable", now, &retryAt)
+	delivered := models.NewWebhookDelivery(&mockWebhook, &models.Event{UUID: uuid.NewString(), Type: models.EventEstateStatsChanged})
+	delivered.Succeed(204, now)
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/webhooks/%s/deliveries", webhookUuid), nil)
+	c, rec := newOrganisationContext(req, "org-a")
+
+	mockRepo.EXPECT().GetWebhook(gomock.Any(), webhookUuid.String()).Return(&mockWebhook, nil)
+	mockRepo.EXPECT().GetWebhookDeliveries(gomock.Any(), uint64(7), defaultDeliveriesLimit).Return(&[]models.WebhookDelivery{*retried, *delivered}, nil)
+
+	require.NoError(t, s.GetWebhooksIdDeliveries(c, webhookUuid, generated.GetWebhooksIdDeliveriesParams{}))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var responseBody generated.WebhookDeliveryListResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &responseBody))
+	require.Len(t, responseBody.Deliveries, 2)
+
+	pending := responseBody.Deliveries[0]
+	assert.Equal(t, generated.Pending, pending.Status)
+	assert.Equal(t, 1, pending.Attempts)
+	if assert.NotNil(t, pending.ResponseStatus) && assert.NotNil(t, pending.NextAttemptAt) {
+		assert.Equal(t, 503, *pending.ResponseStatus)
+		assert.WithinDuration(t, retryAt, *pending.NextAttemptAt, time.Millisecond)
+	}
+
+	sent := responseBody.Deliveries[1]
+	assert.Equal(t, generated.Delivered, sent.Status)
+	assert.Nil(t, sent.NextAttemptAt)
+	assert.Nil(t, sent.Error)
+	assert.NotNil(t, sent.DeliveredAt)
+}
+
+func TestGetWebhookDeliveries_InvalidLimit(t *testing.T) {
+	webhookUuid := uuid.New()
+	status, response := serveValidated(t, http.MethodGet, fmt.Sprintf("/webhooks/%s/deliveries?limit=500", webhookUuid), "")
+
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, generated.ValidationFailed, response.Code)
+}
+
+func TestRequestValidator_WebhookEvents(t *testing.T) {
+	status, response := serveValidated(t, http.MethodPost, "/webhooks", `{"url": "https://example.com/hooks", "events": ["tree.planted"]}`)
+
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, generated.ValidationFailed, response.Code)
+}
@@ -0,0 +1,237 @@
+// This file contains a worker pool running long computations in the
+// background, so HTTP requests only need to submit a job and poll its status.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+var (
+	ErrQueueFull  = errors.New("job queue is full")
+	ErrPoolClosed = errors.New("job pool is closed")
+)
+
+// Func is the work executed by a job. It must stop when the context is
+// cancelled and may report its progress as a percentage.
+type Func func(ctx context.Context, progress func(percentage int)) (any, error)
+
+type Job struct {
+	ID string
+	// Owner is who may poll the job, the pool does not interpret it
+	Owner     string
+	Status    Status
+	Progress  int
+	Result    any
+	Error     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (j *Job) IsFinished() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed || j.Status == StatusCancelled
+}
+
+type task struct {
+	job *Job
+	fn  Func
+}
+
+type Pool struct {
+	mu        sync.RWMutex
+	jobs      map[string]*Job
+	queue     chan task
+	retention time.Duration
+	closed    bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type NewPoolOptions struct {
+	// Workers is the number of jobs running at the same time, default to the number of CPU
+	Workers int
+	// QueueSize is the number of jobs waiting for a worker before Submit is rejected
+	QueueSize int
+	// Retention is how long a finished job is kept for polling
+	Retention time.Duration
+}
+
+func NewPool(opts NewPoolOptions) *Pool {
+	if opts.Workers < 1 {
+		opts.Workers = runtime.NumCPU()
+	}
+
+	if opts.QueueSize < 1 {
+		opts.QueueSize = 100
+	}
+
+	if opts.Retention <= 0 {
+		opts.Retention = time.Hour
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	pool := &Pool{
+		jobs:      make(map[string]*Job),
+		queue:     make(chan task, opts.QueueSize),
+		retention: opts.Retention,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+
+	for i := 0; i < opts.Workers; i++ {
+		pool.wg.Add(1)
+		go pool.work()
+	}
+
+	return pool
+}
+
+// Submit queues a new job of owner and returns a snapshot of it.
+func (p *Pool) Submit(owner string, fn Func) (Job, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return Job{}, ErrPoolClosed
+	}
+
+	p.prune()
+
+	now := time.Now()
+	job := &Job{
+		ID:        uuid.NewString(),
+		Owner:     owner,
+		Status:    StatusQueued,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	select {
+	case p.queue <- task{job: job, fn: fn}:
+	default:
+		return Job{}, ErrQueueFull
+	}
+
+	p.jobs[job.ID] = job
+	return *job, nil
+}
+
+// Get returns a snapshot of the job.
+func (p *Pool) Get(id string) (Job, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	job, ok := p.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+
+	return *job, true
+}
+
+// Close stops accepting jobs, cancels the running ones and waits until the
+// workers exit or the context is done.
+func (p *Pool) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+
+	p.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+
+	for t := range p.queue {
+		p.run(t)
+	}
+}
+
+func (p *Pool) run(t task) {
+	if p.ctx.Err() != nil {
+		p.finish(t.job, nil, p.ctx.Err())
+		return
+	}
+
+	p.update(t.job, func(job *Job) {
+		job.Status = StatusRunning
+	})
+
+	result, err := t.fn(p.ctx, func(percentage int) {
+		p.update(t.job, func(job *Job) {
+			job.Progress = percentage
+		})
+	})
+
+	p.finish(t.job, result, err)
+}
+
+func (p *Pool) finish(job *Job, result any, err error) {
+	p.update(job, func(job *Job) {
+		switch {
+		case err == nil:
+			job.Status = StatusSucceeded
+			job.Progress = 100
+			job.Result = result
+		case errors.Is(err, context.Canceled):
+			job.Status = StatusCancelled
+			job.Error = err.Error()
+		default:
+			job.Status = StatusFailed
+			job.Error = err.Error()
+		}
+	})
+}
+
+func (p *Pool) update(job *Job, fn func(job *Job)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fn(job)
+	job.UpdatedAt = time.Now()
+}
+
+// prune removes the finished jobs older than the retention, must be called
+// while holding the lock.
+func (p *Pool) prune() {
+	expired := time.Now().Add(-p.retention)
+	for id, job := range p.jobs {
+		if job.IsFinished() && job.UpdatedAt.Before(expired) {
+			delete(p.jobs, id)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func waitFinished(t *testing.T, pool *Pool, id string) Job {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, ok := pool.Get(id)
+		if ok && job.IsFinished() {
+			return job
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatalf("job %s did not finish", id)
+	return Job{}
+}
+
+func TestPoolSubmit(t *testing.T) {
+	pool := NewPool(NewPoolOptions{Workers: 2})
+	defer pool.Close(context.Background())
+
+	job, err := pool.Submit("org-1", func(ctx context.Context, progress func(int)) (any, error) {
+		progress(50)
+		return 42, nil
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, job.ID)
+	assert.Equal(t, "org-1", job.Owner)
+
+	job = waitFinished(t, pool, job.ID)
+	assert.Equal(t, StatusSucceeded, job.Status)
+	assert.Equal(t, 100, job.Progress)
+	assert.Equal(t, 42, job.Result)
+}
+
+func TestPoolSubmit_Failed(t *testing.T) {
+	pool := NewPool(NewPoolOptions{Workers: 1})
+	defer pool.Close(context.Background())
+
+	job, err := pool.Submit("", func(ctx context.Context, progress func(int)) (any, error) {
+		return nil, errors.New("error")
+	})
+	assert.NoError(t, err)
+
+	job = waitFinished(t, pool, job.ID)
+	assert.Equal(t, StatusFailed, job.Status)
+	assert.Equal(t, "error", job.Error)
+}
+
+func TestPoolGet_NotFound(t *testing.T) {
+	pool := NewPool(NewPoolOptions{Workers: 1})
+	defer pool.Close(context.Background())
+
+	_, ok := pool.Get("unknown")
+	assert.False(t, ok)
+}
+
+func TestPoolSubmit_QueueFull(t *testing.T) {
+	pool := NewPool(NewPoolOptions{Workers: 1, QueueSize: 1})
+
+	started := make(chan struct{})
+	blocking := func(ctx context.Context, progress func(int)) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	running, err := pool.Submit("", blocking)
+	assert.NoError(t, err)
+	<-started
+
+	queued, err := pool.Submit("", func(ctx context.Context, progress func(int)) (any, error) {
+		return nil, nil
+	})
+	assert.NoError(t, err)
+
+	_, err = pool.Submit("", func(ctx context.Context, progress func(int)) (any, error) {
+		return nil, nil
+	})
+	assert.ErrorIs(t, err, ErrQueueFull)
+
+	assert.NoError(t, pool.Close(context.Background()))
+
+	job, _ := pool.Get(running.ID)
+	assert.Equal(t, StatusCancelled, job.Status)
+
+	job, _ = pool.Get(queued.ID)
+	assert.Equal(t, StatusCancelled, job.Status)
+
+	_, err = pool.Submit("", blocking)
+	assert.ErrorIs(t, err, ErrPoolClosed)
+}
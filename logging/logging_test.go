@@ -0,0 +1,97 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var records []map[string]any
+
+	decoder := json.NewDecoder(buf)
+	for decoder.More() {
+		var record map[string]any
+		require.NoError(t, decoder.Decode(&record))
+		records = append(records, record)
+	}
+
+	return records
+}
+
+func TestNew_RequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, Options{Level: "debug"}).With("component", "test")
+
+	logger.DebugContext(WithRequestID(context.Background(), "request-1"), "with request")
+	logger.Info("without request")
+
+	records := decodeRecords(t, &buf)
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, "request-1", records[0]["request_id"])
+		assert.Equal(t, "test", records[0]["component"])
+		assert.NotContains(t, records[1], "request_id")
+	}
+}
+
+func TestNew_Level(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, Options{Level: "warn", Format: "text"})
+
+	logger.Info("dropped")
+	logger.Warn("kept")
+
+	assert.NotContains(t, buf.String(), "dropped")
+	assert.Contains(t, buf.String(), "msg=kept")
+	assert.Equal(t, slog.LevelInfo, ParseLevel("verbose"))
+}
+
+func TestMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, Options{})
+
+	e := echo.New()
+	e.Use(Middleware(logger))
+	e.GET("/ok", func(ctx echo.Context) error {
+		return ctx.String(http.StatusOK, RequestID(ctx.Request().Context()))
+	})
+	e.GET("/fail", func(ctx echo.Context) error {
+		return errors.New("database down")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ok", nil)
+	req.Header.Set(echo.HeaderXRequestID, "request-1")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, "request-1", rec.Body.String())
+	assert.Equal(t, "request-1", rec.Header().Get(echo.HeaderXRequestID))
+
+	req = httptest.NewRequest(http.MethodGet, "/fail", nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get(echo.HeaderXRequestID))
+
+	records := decodeRecords(t, &buf)
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, "INFO", records[0]["level"])
+		assert.Equal(t, "request-1", records[0]["request_id"])
+		assert.Equal(t, "/ok", records[0]["route"])
+		assert.Equal(t, float64(http.StatusOK), records[0]["status"])
+
+		assert.Equal(t, "ERROR", records[1]["level"])
+		assert.Equal(t, rec.Header().Get(echo.HeaderXRequestID), records[1]["request_id"])
+		assert.Equal(t, float64(http.StatusInternalServerError), records[1]["status"])
+	}
+}
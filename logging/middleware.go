@@ -0,0 +1,54 @@
+package logging
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Middleware gives every request an ID, from the X-Request-ID header when the
+// caller sent one, and logs the request once answered. It replaces the access
+// log of echo.
+func Middleware(logger *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			start := time.Now()
+			req := ctx.Request()
+
+			requestID := req.Header.Get(echo.HeaderXRequestID)
+			if requestID == "" || len(requestID) > 128 {
+				requestID = uuid.NewString()
+			}
+
+			ctx.Response().Header().Set(echo.HeaderXRequestID, requestID)
+			req = req.WithContext(WithRequestID(req.Context(), requestID))
+			ctx.SetRequest(req)
+
+			err := next(ctx)
+			if err != nil {
+				// Answers the error now so its status is the one logged
+				ctx.Error(err)
+			}
+
+			status := ctx.Response().Status
+			level := slog.LevelInfo
+			if status >= 500 {
+				level = slog.LevelError
+			}
+
+			logger.LogAttrs(req.Context(), level, "request",
+				slog.String("method", req.Method),
+				slog.String("path", req.URL.Path),
+				slog.String("route", ctx.Path()),
+				slog.Int("status", status),
+				slog.Int64("bytes", ctx.Response().Size),
+				slog.Duration("latency", time.Since(start)),
+				slog.String("remote_ip", ctx.RealIP()),
+			)
+
+			return nil
+		}
+	}
+}
@@ -0,0 +1,232 @@
+// This file contains the Prometheus metrics of the server, served on /metrics:
+// the requests per route, the database queries, the drone plans, the webhook
+// deliveries, the outbox, the totals of the repository and the database
+// connection pool.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/uptrace/bun"
+)
+
+const namespace = "sawit"
+
+// totalsTimeout bounds the queries counting the estates and trees on scrape
+const totalsTimeout = 5 * time.Second
+
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	queryDuration   *prometheus.HistogramVec
+	planDuration    prometheus.Histogram
+	plotsVisited    prometheus.Counter
+	deliveries      *prometheus.CounterVec
+	outboxEvents    *prometheus.CounterVec
+}
+
+// New registers the metrics of the server, and those of the Go runtime and
+// the process, on a registry of their own
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Requests answered, by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time taken to answer the requests, by method and route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Time taken by the database queries, by operation and outcome.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation", "outcome"}),
+		planDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "drone_plan_duration_seconds",
+			Help:      "Time taken to simulate the flights of the drone plans.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 4, 10),
+		}),
+		plotsVisited: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "drone_plots_visited_total",
+			Help:      "Plots flown over by the drone plans.",
+		}),
+		deliveries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "webhook_deliveries_total",
+			Help:      "Attempts to deliver an event to a webhook, by outcome: delivered, retried or abandoned.",
+		}, []string{"outcome"}),
+		outboxEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "outbox_events_total",
+			Help:      "Events of the outbox sent to the sink, by outcome: published or failed.",
+		}, []string{"outcome"}),
+	}
+
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.queryDuration,
+		m.planDuration,
+		m.plotsVisited,
+		m.deliveries,
+		m.outboxEvents,
+	)
+
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{Registry: m.Registry})
+}
+
+// Middleware counts the requests by the route they matched, as declared in
+// api.yml, so the estate IDs do not end up in the labels. The requests
+// matching no route, answered by echo or by the catch-all route of a group
+// with middlewares, are counted under "unmatched".
+func (m *Metrics) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			start := time.Now()
+
+			err := next(ctx)
+			if err != nil {
+				// Answers the error now so its status is the one counted
+				ctx.Error(err)
+			}
+
+			route := ctx.Path()
+			if route == "" || route == "/*" {
+				route = "unmatched"
+			}
+
+			method := ctx.Request().Method
+			m.requests.WithLabelValues(method, route, strconv.Itoa(ctx.Response().Status)).Inc()
+			m.requestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+
+			return nil
+		}
+	}
+}
+
+// ObserveDronePlan records a drone plan, m may be nil when the metrics are
+// not collected
+func (m *Metrics) ObserveDronePlan(duration time.Duration, plotsVisited uint64) {
+	if m == nil {
+		return
+	}
+
+	m.planDuration.Observe(duration.Seconds())
+	m.plotsVisited.Add(float64(plotsVisited))
+}
+
+// ObserveWebhookDelivery counts an attempt to deliver an event, m may be nil
+// when the metrics are not collected
+func (m *Metrics) ObserveWebhookDelivery(outcome string) {
+	if m == nil {
+		return
+	}
+
+	m.deliveries.WithLabelValues(outcome).Inc()
+}
+
+// ObserveOutboxEvents counts the events of a batch sent to the sink of the
+// outbox, m may be nil when the metrics are not collected
+func (m *Metrics) ObserveOutboxEvents(outcome string, count int) {
+	if m == nil {
+		return
+	}
+
+	m.outboxEvents.WithLabelValues(outcome).Add(float64(count))
+}
+
+// QueryHook times the queries of a bun database
+func (m *Metrics) QueryHook() bun.QueryHook {
+	return &queryHook{duration: m.queryDuration}
+}
+
+type queryHook struct {
+	duration *prometheus.HistogramVec
+}
+
+func (h *queryHook) BeforeQuery(ctx context.Context, event *bun.QueryEvent) context.Context {
+	return ctx
+}
+
+func (h *queryHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
+	outcome := "success"
+	if event.Err != nil && !errors.Is(event.Err, sql.ErrNoRows) {
+		outcome = "error"
+	}
+
+	h.duration.WithLabelValues(event.Operation(), outcome).Observe(time.Since(event.StartTime).Seconds())
+}
+
+// RegisterTotals exposes the number of estates and trees of repo, counted on
+// every scrape
+func (m *Metrics) RegisterTotals(repo repository.RepositoryInterface) {
+	m.Registry.MustRegister(&totalsCollector{
+		repo: repo,
+		estates: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "estates"),
+			"Estates stored.", nil, nil,
+		),
+		trees: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "trees"),
+			"Trees planted over all the estates.", nil, nil,
+		),
+	})
+}
+
+// RegisterDBStats exposes the connection pool statistics of db
+func (m *Metrics) RegisterDBStats(db *sql.DB) {
+	m.Registry.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+}
+
+type totalsCollector struct {
+	repo    repository.RepositoryInterface
+	estates *prometheus.Desc
+	trees   *prometheus.Desc
+}
+
+func (c *totalsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.estates
+	ch <- c.trees
+}
+
+func (c *totalsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), totalsTimeout)
+	defer cancel()
+
+	totals, err := c.repo.GetTotals(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.estates, err)
+		ch <- prometheus.NewInvalidMetric(c.trees, err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.estates, prometheus.GaugeValue, float64(totals.Estates))
+	ch <- prometheus.MustNewConstMetric(c.trees, prometheus.GaugeValue, float64(totals.Trees))
+}
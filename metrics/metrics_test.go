@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/models"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+func TestMiddleware(t *testing.T) {
+	m := New()
+
+	e := echo.New()
+	e.Use(m.Middleware())
+	e.GET("/estate/:id/stats", func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusOK)
+	})
+	e.GET("/fail", func(ctx echo.Context) error {
+		return errors.New("database down")
+	})
+
+	for _, target := range []string{"/estate/a/stats", "/estate/b/stats", "/fail", "/unknown"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.requests.WithLabelValues("GET", "/estate/:id/stats", "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.requests.WithLabelValues("GET", "/fail", "500")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.requests.WithLabelValues("GET", "unmatched", "404")))
+	assert.Equal(t, 3, testutil.CollectAndCount(m.requestDuration))
+}
+
+func TestObserveDronePlan(t *testing.T) {
+	var disabled *Metrics
+	disabled.ObserveDronePlan(time.Second, 10)
+
+	m := New()
+	m.ObserveDronePlan(time.Millisecond, 100)
+	m.ObserveDronePlan(time.Millisecond, 20)
+
+	assert.Equal(t, float64(120), testutil.ToFloat64(m.plotsVisited))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.planDuration))
+}
+
+func TestObserveWebhookDelivery(t *testing.T) {
+	var disabled *Metrics
+	disabled.ObserveWebhookDelivery("delivered")
+
+	m := New()
+	m.ObserveWebhookDelivery("delivered")
+	m.ObserveWebhookDelivery("retried")
+	m.ObserveWebhookDelivery("delivered")
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.deliveries.WithLabelValues("delivered")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.deliveries.WithLabelValues("retried")))
+}
+
+func TestObserveOutboxEvents(t *testing.T) {
+	var disabled *Metrics
+	disabled.ObserveOutboxEvents("published", 3)
+
+	m := New()
+	m.ObserveOutboxEvents("published", 3)
+	m.ObserveOutboxEvents("failed", 2)
+	m.ObserveOutboxEvents("published", 1)
+
+	assert.Equal(t, float64(4), testutil.ToFloat64(m.outboxEvents.WithLabelValues("published")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.outboxEvents.WithLabelValues("failed")))
+}
+
+func TestQueryHook(t *testing.T) {
+	m := New()
+	hook := m.QueryHook()
+
+	hook.AfterQuery(context.Background(), &bun.QueryEvent{Query: "SELECT 1", StartTime: time.Now()})
+	hook.AfterQuery(context.Background(), &bun.QueryEvent{Query: "SELECT 1", StartTime: time.Now(), Err: errors.New("timeout")})
+
+	assert.Equal(t, 2, testutil.CollectAndCount(m.queryDuration))
+}
+
+func TestRegisterTotals(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+
+	estate, err := models.NewEstate(10, 10)
+	require.NoError(t, err)
+	require.NoError(t, repo.SaveEstate(ctx, estate))
+	estate, err = repo.GetEstate(ctx, estate.UUID)
+	require.NoError(t, err)
+
+	tree, err := models.NewTree(estate, 1, 1, 5)
+	require.NoError(t, err)
+	require.NoError(t, repo.SaveTree(ctx, tree))
+
+	m := New()
+	m.RegisterTotals(repo)
+
+	expected := `
+# HELP sawit_estates Estates stored.
+# TYPE sawit_estates gauge
+sawit_estates 1
+# HELP sawit_trees Trees planted over all the estates.
+# TYPE sawit_trees gauge
+sawit_trees 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(m.Registry, strings.NewReader(expected), "sawit_estates", "sawit_trees"))
+}
+
+func TestHandler(t *testing.T) {
+	m := New()
+	m.ObserveDronePlan(time.Millisecond, 4)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "sawit_drone_plots_visited_total 4")
+	assert.Contains(t, rec.Body.String(), "go_goroutines")
+}
@@ -0,0 +1,335 @@
+// This file contains the schema migrations of the database. Every migration is
+// a pair of NNNN_name.up.sql and NNNN_name.down.sql files, one directory per
+// database, embedded in the binary and recorded in the schema_migrations table
+// once applied.
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/driver/pgdriver"
+)
+
+//go:embed postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+var (
+	// Postgres holds the migrations of the Postgres database
+	Postgres = mustSub(files, "postgres")
+	// SQLite holds the migrations of the SQLite database
+	SQLite = mustSub(files, "sqlite")
+)
+
+var (
+	// ErrChecksumMismatch is returned when an applied migration was edited
+	// afterwards, migrations must never change once released.
+	ErrChecksumMismatch = errors.New("migration checksum mismatch")
+	// ErrUnknownMigration is returned when the database has a migration this
+	// binary does not know, it was most likely migrated by a newer version.
+	ErrUnknownMigration = errors.New("unknown migration applied")
+)
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// lockKey is the Postgres advisory lock held while migrating, the servers
+// starting together then apply the pending migrations one after the other
+const lockKey int64 = 0x5a1_7000
+
+const createVersionTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    applied_at TIMESTAMP NOT NULL
+)`
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Checksum identifies the content of the up script
+func (m Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.Up))
+	return hex.EncodeToString(sum[:])
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// Status tells if a migration is applied on the database
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type appliedMigration struct {
+	bun.BaseModel `bun:"table:schema_migrations"`
+
+	Version   int       `bun:"version,pk"`
+	Name      string    `bun:"name,notnull"`
+	Checksum  string    `bun:"checksum,notnull"`
+	AppliedAt time.Time `bun:"applied_at,notnull"`
+}
+
+// Load reads the migrations of fsys sorted by version
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+
+		version, err := strconv.Atoi(match[1])
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("invalid migration version in %q", entry.Name())
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration := byVersion[version]
+		if migration == nil {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %04d has two names, %q and %q", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %s needs both an up and a down script", migration)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+type Migrator struct {
+	db   *bun.DB
+	fsys fs.FS
+}
+
+func NewMigrator(db *bun.DB, fsys fs.FS) *Migrator {
+	return &Migrator{
+		db:   db,
+		fsys: fsys,
+	}
+}
+
+// Status lists every known migration and whether it is applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	migrations, applied, err := m.load(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(migrations))
+	for i, migration := range migrations {
+		statuses[i].Migration = migration
+		if record, ok := applied[migration.Version]; ok {
+			statuses[i].Applied = true
+			statuses[i].AppliedAt = record.AppliedAt
+		}
+	}
+
+	return statuses, nil
+}
+
+// Up applies the pending migrations in order, each in its own transaction,
+// and returns the ones it applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	db, unlock, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	migrations, applied, err := m.load(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		err := db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+			if _, err := tx.Tx.ExecContext(ctx, migration.Up); err != nil {
+				return err
+			}
+
+			_, err := tx.NewInsert().Model(&appliedMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				Checksum:  migration.Checksum(),
+				AppliedAt: time.Now().UTC(),
+			}).Exec(ctx)
+
+			return err
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %s: %w", migration, err)
+		}
+
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns
+// the ones it reverted.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	db, unlock, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	migrations, applied, err := m.load(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		err := db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+			if _, err := tx.Tx.ExecContext(ctx, migration.Down); err != nil {
+				return err
+			}
+
+			_, err := tx.NewDelete().
+				Model((*appliedMigration)(nil)).
+				Where("version = ?", migration.Version).
+				Exec(ctx)
+
+			return err
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %s: %w", migration, err)
+		}
+
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// lock waits for the other migrators of the database to be done and answers
+// the connection holding the lock, the migrations run on it. Only Postgres is
+// locked, a SQLite database is opened by a single server. It is told apart by
+// its driver since it shares the Postgres dialect.
+func (m *Migrator) lock(ctx context.Context) (bun.IDB, func(), error) {
+	if _, ok := m.db.Driver().(pgdriver.Driver); !ok {
+		return m.db, func() {}, nil
+	}
+
+	// The lock belongs to the session, it is released on the same connection
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock(?)", lockKey); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("locking the migrations: %w", err)
+	}
+
+	return conn, func() {
+		conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock(?)", lockKey)
+		conn.Close()
+	}, nil
+}
+
+// load reads the known migrations and the applied ones, making sure the
+// applied ones were not modified since.
+func (m *Migrator) load(ctx context.Context, db bun.IDB) ([]Migration, map[int]appliedMigration, error) {
+	migrations, err := Load(m.fsys)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := db.ExecContext(ctx, createVersionTable); err != nil {
+		return nil, nil, err
+	}
+
+	var records []appliedMigration
+	if err := db.NewSelect().Model(&records).Order("version ASC").Scan(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	known := make(map[int]Migration, len(migrations))
+	for _, migration := range migrations {
+		known[migration.Version] = migration
+	}
+
+	applied := make(map[int]appliedMigration, len(records))
+	for _, record := range records {
+		migration, ok := known[record.Version]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: version %04d", ErrUnknownMigration, record.Version)
+		}
+
+		if migration.Checksum() != record.Checksum {
+			return nil, nil, fmt.Errorf("%w: %s", ErrChecksumMismatch, migration)
+		}
+
+		applied[record.Version] = record
+	}
+
+	return migrations, applied, nil
+}
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+
+	return sub
+}
@@ -0,0 +1,166 @@
+package migrations_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/SawitProRecruitment/UserService/migrations"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+func newTestDB(t *testing.T) *bun.DB {
+	repo, err := repository.NewRepository(repository.NewRepositoryOptions{
+		Dsn: "sqlite://" + filepath.Join(t.TempDir(), "migrations.db"),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		repo.Db.Close()
+	})
+
+	return repo.Db
+}
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"0001_plots.up.sql":     {Data: []byte("CREATE TABLE plots (id INTEGER PRIMARY KEY);")},
+		"0001_plots.down.sql":   {Data: []byte("DROP TABLE plots;")},
+		"0002_harvest.up.sql":   {Data: []byte("CREATE TABLE harvests (id INTEGER PRIMARY KEY);")},
+		"0002_harvest.down.sql": {Data: []byte("DROP TABLE harvests;")},
+	}
+}
+
+func TestLoad_Embedded(t *testing.T) {
+	postgres, err := migrations.Load(migrations.Postgres)
+	require.NoError(t, err)
+	sqlite, err := migrations.Load(migrations.SQLite)
+	require.NoError(t, err)
+
+	// Both databases must go through the same versions
+	require.Len(t, sqlite, len(postgres))
+	for i := range postgres {
+		assert.Equal(t, postgres[i].String(), sqlite[i].String())
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	_, err := migrations.Load(fstest.MapFS{
+		"0001_plots.up.sql": {Data: []byte("CREATE TABLE plots (id INTEGER);")},
+	})
+	assert.ErrorContains(t, err, "needs both an up and a down script")
+
+	_, err = migrations.Load(fstest.MapFS{
+		"plots.sql": {Data: []byte("CREATE TABLE plots (id INTEGER);")},
+	})
+	assert.ErrorContains(t, err, "invalid migration file name")
+}
+
+func TestMigrator_UpDown(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	migrator := migrations.NewMigrator(db, testFS())
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, 2)
+
+	// Nothing left to apply
+	applied, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	_, err = db.ExecContext(ctx, "INSERT INTO harvests (id) VALUES (1)")
+	assert.NoError(t, err)
+
+	reverted, err := migrator.Down(ctx, 1)
+	require.NoError(t, err)
+	if assert.Len(t, reverted, 1) {
+		assert.Equal(t, 2, reverted[0].Version)
+	}
+
+	_, err = db.ExecContext(ctx, "INSERT INTO harvests (id) VALUES (1)")
+	assert.Error(t, err)
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	if assert.Len(t, statuses, 2) {
+		assert.True(t, statuses[0].Applied)
+		assert.False(t, statuses[1].Applied)
+	}
+
+	reverted, err = migrator.Down(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, reverted, 1)
+}
+
+func TestMigrator_FailedMigrationIsRolledBack(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	fsys := testFS()
+	fsys["0002_harvest.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE harvests (id INTEGER PRIMARY KEY); NOT SQL;")}
+	migrator := migrations.NewMigrator(db, fsys)
+
+	applied, err := migrator.Up(ctx)
+	assert.ErrorContains(t, err, "0002_harvest")
+	assert.Len(t, applied, 1)
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	assert.True(t, statuses[0].Applied)
+	assert.False(t, statuses[1].Applied)
+
+	_, err = db.ExecContext(ctx, "SELECT * FROM harvests")
+	assert.Error(t, err)
+}
+
+func TestMigrator_ChecksumMismatch(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	_, err := migrations.NewMigrator(db, testFS()).Up(ctx)
+	require.NoError(t, err)
+
+	fsys := testFS()
+	fsys["0001_plots.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE plots (id BIGINT PRIMARY KEY);")}
+
+	_, err = migrations.NewMigrator(db, fsys).Up(ctx)
+	assert.ErrorIs(t, err, migrations.ErrChecksumMismatch)
+}
+
+func TestMigrator_UnknownMigration(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	_, err := migrations.NewMigrator(db, testFS()).Up(ctx)
+	require.NoError(t, err)
+
+	fsys := testFS()
+	delete(fsys, "0002_harvest.up.sql")
+	delete(fsys, "0002_harvest.down.sql")
+
+	_, err = migrations.NewMigrator(db, fsys).Status(ctx)
+	assert.ErrorIs(t, err, migrations.ErrUnknownMigration)
+}
+
+func TestMigrator_Embedded(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	migrator := migrations.NewMigrator(db, migrations.SQLite)
+
+	_, err := migrator.Up(ctx)
+	require.NoError(t, err)
+
+	_, err = migrator.Down(ctx, 100)
+	require.NoError(t, err)
+
+	_, err = db.ExecContext(ctx, "SELECT * FROM estates")
+	assert.Error(t, err)
+
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+}
@@ -0,0 +1,36 @@
+-- Initial schema, formerly database.sql, left as it was so it matches the
+-- databases which were created from it by the Postgres init hook. IF NOT EXISTS
+-- lets it run on them, the next migrations then bring them up to date.
+
+CREATE TABLE IF NOT EXISTS estates (
+    id SERIAL PRIMARY KEY,
+    uuid VARCHAR(36) UNIQUE,
+    width INT NOT NULL CHECK (width >= 1 AND width <= 50000),
+    length INT NOT NULL CHECK (length >= 1 AND length <= 50000),
+    tree_count SMALLINT DEFAULT 0,
+    min_tree_height SMALLINT,
+    max_tree_height SMALLINT,
+    median_tree_height SMALLINT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_estates_uuid ON estates(uuid);
+
+CREATE TABLE IF NOT EXISTS trees (
+    id SERIAL PRIMARY KEY,
+    uuid VARCHAR(36) UNIQUE,
+    estate_id INTEGER REFERENCES estates(id),
+    x INT NOT NULL CHECK (x >= 1), -- Assuming x and y are coordinates, which cannot be negative
+    y INT NOT NULL CHECK (y >= 1), -- Assuming x and y are coordinates, which cannot be negative
+    height SMALLINT NOT NULL CHECK (height >= 1 AND height <= 30),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_tree_location UNIQUE (estate_id, x, y), -- Ensure one tree per plot
+    CONSTRAINT fk_estate_id FOREIGN KEY (estate_id) REFERENCES estates(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_trees_uuid ON trees(uuid);
+CREATE INDEX IF NOT EXISTS idx_trees_x ON trees(x);
+CREATE INDEX IF NOT EXISTS idx_trees_y ON trees(y);
+CREATE INDEX IF NOT EXISTS idx_trees_estate_id ON trees(estate_id);
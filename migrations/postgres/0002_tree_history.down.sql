@@ -0,0 +1,8 @@
+-- A plot holds a single tree again, the history of the retired trees is lost.
+
+DROP INDEX unique_active_tree_location;
+DELETE FROM trees WHERE retired_at IS NOT NULL;
+ALTER TABLE trees ADD CONSTRAINT unique_tree_location UNIQUE (estate_id, x, y);
+
+ALTER TABLE trees DROP COLUMN retired_at;
+ALTER TABLE estates DROP COLUMN version;
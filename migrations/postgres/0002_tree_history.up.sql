@@ -0,0 +1,12 @@
+-- The changes made to the schema of database.sql before the migrations. A
+-- tree is retired instead of deleted, the plot can then be replanted while
+-- the retired tree stays as history, and the version of an estate is bumped
+-- every time one of its trees is saved.
+
+ALTER TABLE estates ADD COLUMN version BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE trees ADD COLUMN retired_at TIMESTAMP; -- NULL while the tree is still standing
+
+-- Ensure one active tree per plot, retired trees stay in the same plot as history
+ALTER TABLE trees DROP CONSTRAINT unique_tree_location;
+CREATE UNIQUE INDEX unique_active_tree_location ON trees(estate_id, x, y) WHERE retired_at IS NULL;
@@ -0,0 +1,3 @@
+ALTER SEQUENCE trees_id_seq AS INTEGER;
+ALTER TABLE trees ALTER COLUMN id TYPE INTEGER;
+ALTER TABLE estates ALTER COLUMN tree_count TYPE SMALLINT;
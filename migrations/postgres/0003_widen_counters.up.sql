@@ -0,0 +1,6 @@
+-- An estate holds up to 2.5 billion trees, more than a SMALLINT or an INT can
+-- count, and the trees of every estate together outgrow a SERIAL id.
+
+ALTER TABLE estates ALTER COLUMN tree_count TYPE BIGINT;
+ALTER TABLE trees ALTER COLUMN id TYPE BIGINT;
+ALTER SEQUENCE trees_id_seq AS BIGINT;
@@ -0,0 +1,2 @@
+DROP INDEX idx_estates_organisation_id;
+ALTER TABLE estates DROP COLUMN organisation_id;
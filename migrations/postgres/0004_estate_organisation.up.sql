@@ -0,0 +1,7 @@
+-- Every estate belongs to an organisation, only its members can read or
+-- modify it. The existing estates are left to the empty organisation, the one
+-- used when the authentication is disabled, until they are assigned.
+
+ALTER TABLE estates ADD COLUMN organisation_id VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_estates_organisation_id ON estates(organisation_id);
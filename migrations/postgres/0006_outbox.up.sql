@@ -0,0 +1,20 @@
+-- The outbox of the changes of the estates, streamed to the sink of the
+-- relay. The events are written in the transaction of the change and deleted
+-- once published, the id orders them.
+
+CREATE TABLE outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    uuid VARCHAR(36) NOT NULL UNIQUE,
+    type VARCHAR(64) NOT NULL,
+    organisation_id VARCHAR(64) NOT NULL,
+    estate_uuid VARCHAR(36) NOT NULL,
+    payload TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A single relay publishes at a time, the one holding the lease
+CREATE TABLE outbox_lease (
+    name VARCHAR(64) PRIMARY KEY,
+    holder VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
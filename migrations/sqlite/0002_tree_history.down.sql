@@ -0,0 +1,26 @@
+-- The SQLite version of postgres/0002_tree_history.down.sql.
+
+CREATE TABLE trees_baseline (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    uuid VARCHAR(36) UNIQUE,
+    estate_id INTEGER REFERENCES estates(id),
+    x INT NOT NULL CHECK (x >= 1),
+    y INT NOT NULL CHECK (y >= 1),
+    height SMALLINT NOT NULL CHECK (height >= 1 AND height <= 30),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_tree_location UNIQUE (estate_id, x, y) -- Ensure one tree per plot
+);
+
+INSERT INTO trees_baseline (id, uuid, estate_id, x, y, height, created_at, updated_at)
+SELECT id, uuid, estate_id, x, y, height, created_at, updated_at FROM trees WHERE retired_at IS NULL;
+
+DROP TABLE trees;
+ALTER TABLE trees_baseline RENAME TO trees;
+
+CREATE INDEX idx_trees_uuid ON trees(uuid);
+CREATE INDEX idx_trees_x ON trees(x);
+CREATE INDEX idx_trees_y ON trees(y);
+CREATE INDEX idx_trees_estate_id ON trees(estate_id);
+
+ALTER TABLE estates DROP COLUMN version;
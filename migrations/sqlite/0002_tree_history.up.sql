@@ -0,0 +1,29 @@
+-- The SQLite version of postgres/0002_tree_history.up.sql. SQLite cannot drop
+-- a constraint, the trees are copied to a table without it instead.
+
+ALTER TABLE estates ADD COLUMN version BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE trees_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    uuid VARCHAR(36) UNIQUE,
+    estate_id INTEGER REFERENCES estates(id),
+    x INT NOT NULL CHECK (x >= 1),
+    y INT NOT NULL CHECK (y >= 1),
+    height SMALLINT NOT NULL CHECK (height >= 1 AND height <= 30),
+    retired_at TIMESTAMP, -- NULL while the tree is still standing
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO trees_history (id, uuid, estate_id, x, y, height, created_at, updated_at)
+SELECT id, uuid, estate_id, x, y, height, created_at, updated_at FROM trees;
+
+DROP TABLE trees;
+ALTER TABLE trees_history RENAME TO trees;
+
+-- Ensure one active tree per plot, retired trees stay in the same plot as history
+CREATE UNIQUE INDEX unique_active_tree_location ON trees(estate_id, x, y) WHERE retired_at IS NULL;
+CREATE INDEX idx_trees_uuid ON trees(uuid);
+CREATE INDEX idx_trees_x ON trees(x);
+CREATE INDEX idx_trees_y ON trees(y);
+CREATE INDEX idx_trees_estate_id ON trees(estate_id);
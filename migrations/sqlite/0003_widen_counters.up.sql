@@ -0,0 +1,2 @@
+-- Same version as postgres/0003_widen_counters.up.sql, nothing to do since
+-- SQLite stores every integer on up to 64 bits whatever the declared type.
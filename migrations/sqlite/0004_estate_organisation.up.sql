@@ -0,0 +1,5 @@
+-- The SQLite version of postgres/0004_estate_organisation.up.sql.
+
+ALTER TABLE estates ADD COLUMN organisation_id VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_estates_organisation_id ON estates(organisation_id);
@@ -0,0 +1,17 @@
+-- The SQLite version of postgres/0006_outbox.up.sql.
+
+CREATE TABLE outbox_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    uuid VARCHAR(36) NOT NULL UNIQUE,
+    type VARCHAR(64) NOT NULL,
+    organisation_id VARCHAR(64) NOT NULL,
+    estate_uuid VARCHAR(36) NOT NULL,
+    payload TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE outbox_lease (
+    name VARCHAR(64) PRIMARY KEY,
+    holder VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
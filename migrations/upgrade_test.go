@@ -0,0 +1,163 @@
+package migrations_test
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/SawitProRecruitment/UserService/migrations"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+// plantLegacyTree saves an estate with a tree in its 1x1 plot on the schema
+// of database.sql
+func plantLegacyTree(t *testing.T, db *bun.DB) {
+	ctx := context.Background()
+
+	_, err := db.ExecContext(ctx, "INSERT INTO estates (uuid, width, length, tree_count) VALUES (?, 10, 10, 1)", uuid.NewString())
+	require.NoError(t, err)
+
+	_, err = db.ExecContext(ctx, "INSERT INTO trees (uuid, estate_id, x, y, height) SELECT ?, id, 1, 1, 5 FROM estates", uuid.NewString())
+	require.NoError(t, err)
+}
+
+// assertUpgraded checks the estate and the tree of plantLegacyTree were kept,
+// and that its plot can be replanted once the tree is retired
+func assertUpgraded(t *testing.T, db *bun.DB) {
+	ctx := context.Background()
+
+	var version int64
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT version FROM estates").Scan(&version))
+	assert.Equal(t, int64(0), version)
+
+	var standing int
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM trees WHERE retired_at IS NULL").Scan(&standing))
+	assert.Equal(t, 1, standing)
+
+	replant := "INSERT INTO trees (uuid, estate_id, x, y, height) SELECT ?, id, 1, 1, 7 FROM estates"
+
+	// One active tree per plot
+	_, err := db.ExecContext(ctx, replant, uuid.NewString())
+	assert.Error(t, err)
+
+	_, err = db.ExecContext(ctx, "UPDATE trees SET retired_at = CURRENT_TIMESTAMP")
+	require.NoError(t, err)
+
+	_, err = db.ExecContext(ctx, replant, uuid.NewString())
+	assert.NoError(t, err)
+}
+
+func TestMigrator_UpgradeSQLiteBaseline(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	// The databases created before the tree history only applied 0001
+	baseline := fstest.MapFS{}
+	for _, name := range []string{"0001_init.up.sql", "0001_init.down.sql"} {
+		content, err := fs.ReadFile(migrations.SQLite, name)
+		require.NoError(t, err)
+		baseline[name] = &fstest.MapFile{Data: content}
+	}
+
+	_, err := migrations.NewMigrator(db, baseline).Up(ctx)
+	require.NoError(t, err)
+	plantLegacyTree(t, db)
+
+	_, err = migrations.NewMigrator(db, migrations.SQLite).Up(ctx)
+	require.NoError(t, err)
+	assertUpgraded(t, db)
+}
+
+// newPostgresDB connects to a schema of its own on the Postgres database of
+// DATABASE_URL, the tests are skipped when it is not set.
+func newPostgresDB(t *testing.T) *bun.DB {
+	dsn := os.Getenv("DATABASE_URL")
+	if testing.Short() || dsn == "" {
+		t.Skip("Skip Postgres migration tests without DATABASE_URL")
+	}
+
+	ctx := context.Background()
+	admin, err := repository.NewRepository(repository.NewRepositoryOptions{Dsn: dsn})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		admin.Db.Close()
+	})
+
+	schema := fmt.Sprintf("migrations_%s", strings.ReplaceAll(uuid.NewString(), "-", ""))
+	_, err = admin.Db.ExecContext(ctx, "CREATE SCHEMA "+schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		admin.Db.ExecContext(ctx, "DROP SCHEMA "+schema+" CASCADE")
+	})
+
+	u, err := url.Parse(dsn)
+	require.NoError(t, err)
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+
+	repo, err := repository.NewRepository(repository.NewRepositoryOptions{Dsn: u.String()})
+	require.NoError(t, err)
+	// Cleaned up before the schema is dropped
+	t.Cleanup(func() {
+		repo.Db.Close()
+	})
+
+	return repo.Db
+}
+
+// TestMigrator_UpgradeDatabaseSQL runs the migrations on a Postgres schema
+// created by database.sql, as the init hook of the container did.
+func TestMigrator_UpgradeDatabaseSQL(t *testing.T) {
+	ctx := context.Background()
+	db := newPostgresDB(t)
+
+	databaseSQL, err := os.ReadFile("testdata/database.sql")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, string(databaseSQL))
+	require.NoError(t, err)
+	plantLegacyTree(t, db)
+
+	_, err = migrations.NewMigrator(db, migrations.Postgres).Up(ctx)
+	require.NoError(t, err)
+	assertUpgraded(t, db)
+}
+
+// TestMigrator_ConcurrentUp checks the servers starting together apply every
+// migration once, one after the other
+func TestMigrator_ConcurrentUp(t *testing.T) {
+	ctx := context.Background()
+	db := newPostgresDB(t)
+
+	all, err := migrations.Load(migrations.Postgres)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	applied := make([][]migrations.Migration, 4)
+	errs := make([]error, len(applied))
+	for i := range applied {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			applied[i], errs[i] = migrations.NewMigrator(db, migrations.Postgres).Up(ctx)
+		}(i)
+	}
+	wg.Wait()
+
+	total := 0
+	for i := range applied {
+		require.NoError(t, errs[i])
+		total += len(applied[i])
+	}
+	assert.Equal(t, len(all), total)
+}
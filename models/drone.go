@@ -1,7 +1,7 @@
 package models
 
 import (
-	"fmt"
+	"log/slog"
 )
 
 type DroneMovement interface {
@@ -13,27 +13,25 @@ type DroneMovement interface {
 type Drone struct {
 	CurrentHeight   uint8
 	Estate          *Estate
-	MappedTrees     [][]uint8
-	Travelled       uint16
-	MaximumBattery  *uint16
+	MappedTrees     *TreeGrid
+	Travelled       uint64
+	MaximumBattery  *uint64
 	BatteryDrains   bool
 	LastCoordinateX uint16
 	LastCoordinateY uint16
-}
-
-func NewDrone(estate *Estate, estateTrees *[]Tree, maxDistance *uint16) *Drone {
-	mappedTrees := make([][]uint8, estate.Length)
-	for i := range mappedTrees {
-		mappedTrees[i] = make([]uint8, estate.Width)
-	}
+	// PlotsVisited counts the plots the drone flew over, every plot of the
+	// estate when the battery lasts
+	PlotsVisited uint64
 
-	for _, tree := range *estateTrees {
-		mappedTrees[tree.X-1][tree.Y-1] = tree.Height
-	}
+	// Trace receives every move of the drone when set, the flight is not
+	// traced by default since it logs several records per plot
+	Trace *slog.Logger
+}
 
+func NewDrone(estate *Estate, estateTrees *[]Tree, maxDistance *uint64) *Drone {
 	drone := Drone{
 		Estate:         estate,
-		MappedTrees:    mappedTrees,
+		MappedTrees:    NewTreeGrid(estateTrees),
 		MaximumBattery: maxDistance,
 	}
 
@@ -41,18 +39,22 @@ func NewDrone(estate *Estate, estateTrees *[]Tree, maxDistance *uint16) *Drone {
 }
 
 func (d *Drone) TestFlight() {
-	fmt.Print("Drone Start Flight At Plot 1,1 \n")
+	if d.Trace == nil {
+		return
+	}
+
+	d.Trace.Info("test flight started")
 
 	xValue := uint16(1)
 	for y := uint16(1); y <= d.Estate.Width; y++ {
 		if xValue == uint16(1) {
 			for x := xValue; x <= uint16(d.Estate.Length); x++ {
-				fmt.Println(x, y)
+				d.Trace.Info("plot", "x", x, "y", y)
 			}
 			xValue = d.Estate.Length
 		} else if xValue == d.Estate.Length {
 			for x := xValue; x > 0; x-- {
-				fmt.Println(x, y)
+				d.Trace.Info("plot", "x", x, "y", y)
 			}
 			xValue = 1
 		}
@@ -60,10 +62,7 @@ func (d *Drone) TestFlight() {
 }
 
 func (d *Drone) StartFlight() {
-	fmt.Print("Drone Start Flight At Plot 1,1 \n\n")
-	if d.MaximumBattery != nil {
-		fmt.Printf("Drone Maximum Flight is %v m \n\n", *d.MaximumBattery)
-	}
+	d.traceStart()
 
 	xValue := uint16(1)
 	for y := uint16(1); y <= d.Estate.Width; y++ {
@@ -92,35 +91,52 @@ func (d *Drone) StartFlight() {
 	}
 }
 
+// traceStart records the start of the flight, the first plot is always 1,1
+func (d *Drone) traceStart() {
+	if d.Trace == nil {
+		return
+	}
+
+	if d.MaximumBattery != nil {
+		d.Trace.Info("drone start flight", "x", 1, "y", 1, "maximum_distance", *d.MaximumBattery)
+	} else {
+		d.Trace.Info("drone start flight", "x", 1, "y", 1)
+	}
+}
+
 func (d *Drone) ReadData(x uint16, y uint16) {
 	d.LastCoordinateX = x + 1
 	d.LastCoordinateY = y + 1
+	d.PlotsVisited++
 
-	fmt.Printf("Drone At %v, %v\n", d.LastCoordinateX, d.LastCoordinateY)
+	if d.Trace != nil {
+		d.Trace.Info("drone at", "x", d.LastCoordinateX, "y", d.LastCoordinateY)
+	}
 
-	fmt.Println(x, y)
 	// For the first plot, the drone must fly from ground level to a position exactly 1 meter above the plot or tree to get the data
-	treeHigh := d.MappedTrees[x][y]
+	treeHigh := d.MappedTrees.Height(x+1, y+1)
 	var nextTreeHigh uint8
 	if x < d.Estate.Length-1 {
-		nextTreeHigh = d.MappedTrees[x+1][y]
+		nextTreeHigh = d.MappedTrees.Height(x+2, y+1)
 	}
 
 	// First Plot
 	if x == 0 && y == 0 {
 		if treeHigh > 0 {
-			d.Accend(uint16(treeHigh+1), x, y)
+			d.Accend(uint64(treeHigh+1), x, y)
 		} else {
-			d.Accend(uint16(1), x, y)
+			d.Accend(uint64(1), x, y)
 			if d.CurrentHeight < nextTreeHigh {
-				d.Accend(uint16(nextTreeHigh), x, y)
+				d.Accend(uint64(nextTreeHigh), x, y)
 			}
 		}
 	} else if x == d.Estate.Length-1 && y == d.Estate.Width-1 { // Last Plot
 		d.Forward(x, y)
-		d.Decend(uint16(d.CurrentHeight), x, y)
+		d.Decend(uint64(d.CurrentHeight), x, y)
 
-		fmt.Printf("Drone Landed at %v,%v . . .\n\n", x+1, y+1)
+		if d.Trace != nil {
+			d.Trace.Info("drone landed", "x", x+1, "y", y+1, "travelled", d.Travelled)
+		}
 		return
 	} else {
 		if treeHigh == 0 {
@@ -134,7 +150,7 @@ func (d *Drone) ReadData(x uint16, y uint16) {
 			distance := d.CurrentHeight - treeHigh
 
 			d.Forward(x, y)
-			d.Decend(uint16(distance-1), x, y)
+			d.Decend(uint64(distance-1), x, y)
 		} else if d.CurrentHeight < treeHigh+1 {
 			// If Current Position is LOWER than next plot tree / ground
 			// then move forward, and decend to 1 m above the next plot tree or ground
@@ -142,7 +158,7 @@ func (d *Drone) ReadData(x uint16, y uint16) {
 			// Calculate distance require to decend
 			distance := treeHigh - d.CurrentHeight
 
-			d.Accend(uint16(distance+1), x, y)
+			d.Accend(uint64(distance+1), x, y)
 			d.Forward(x, y)
 		} else {
 			// If Current Position is SAME LEVEL with next plot tree / ground
@@ -152,18 +168,24 @@ func (d *Drone) ReadData(x uint16, y uint16) {
 		}
 
 		if d.BatteryDrains {
-			fmt.Printf("Drone Rest at %v,%v . . .\n\n", x+1, y+1)
+			if d.Trace != nil {
+				d.Trace.Info("drone rest", "x", x+1, "y", y+1, "travelled", d.Travelled)
+			}
 			return
 		}
 
-		fmt.Print("Drone Reading The Data . . .\n\n")
+		if d.Trace != nil {
+			d.Trace.Info("drone reading the data", "x", x+1, "y", y+1)
+		}
 	}
 }
 
 func (d *Drone) Forward(x uint16, y uint16) {
 	nextDistance := d.CheckBatteryBeforeDrains(10)
 
-	fmt.Printf("Drone Move for %v m\n", nextDistance)
+	if d.Trace != nil {
+		d.Trace.Info("drone move", "distance", nextDistance)
+	}
 
 	if nextDistance <= 5 {
 		d.LastCoordinateX = d.LastCoordinateX - 1
@@ -174,45 +196,53 @@ func (d *Drone) Forward(x uint16, y uint16) {
 	}
 }
 
-func (d *Drone) Accend(distance uint16, x uint16, y uint16) {
+func (d *Drone) Accend(distance uint64, x uint16, y uint16) {
 	nextDistance := d.CheckBatteryBeforeDrains(distance)
 
 	d.CurrentHeight = d.CurrentHeight + uint8(nextDistance)
-	fmt.Printf("Drone Accend for %v m\n", nextDistance)
+	if d.Trace != nil {
+		d.Trace.Info("drone accend", "distance", nextDistance, "height", d.CurrentHeight)
+	}
 
 	if d.BatteryDrains {
 		return
 	}
 }
 
-func (d *Drone) Decend(distance uint16, x uint16, y uint16) {
+func (d *Drone) Decend(distance uint64, x uint16, y uint16) {
 	nextDistance := d.CheckBatteryBeforeDrains(distance)
 
 	d.CurrentHeight = d.CurrentHeight - uint8(nextDistance)
-	fmt.Printf("Drone Decend for %v m\n", nextDistance)
+	if d.Trace != nil {
+		d.Trace.Info("drone decend", "distance", nextDistance, "height", d.CurrentHeight)
+	}
 
 	if d.BatteryDrains {
 		return
 	}
 }
 
-func (d *Drone) CheckBatteryBeforeDrains(nextDistance uint16) uint16 {
+// CheckBatteryBeforeDrains records the next move and returns how far the drone
+// actually goes. Travelled is an uint64 since the flight over the largest
+// estate is over 25 billion meters, far beyond an uint32. The battery is
+// compared with what is left of it so the sum can never wrap around.
+func (d *Drone) CheckBatteryBeforeDrains(nextDistance uint64) uint64 {
 	// No Maximum Distance
 	if d.MaximumBattery == nil {
-		d.Travelled += uint16(nextDistance)
-		return uint16(nextDistance)
+		d.Travelled += nextDistance
+		return nextDistance
 	}
 
 	// If battery is not enough for next distance will be travelled
-	if (d.Travelled + uint16(nextDistance)) > *d.MaximumBattery {
-		nextDistance = uint16(*d.MaximumBattery - d.Travelled)
+	if nextDistance > *d.MaximumBattery-d.Travelled {
+		nextDistance = *d.MaximumBattery - d.Travelled
 
 		d.BatteryDrains = true
-	} else if (d.Travelled + uint16(nextDistance)) == *d.MaximumBattery {
+	} else if nextDistance == *d.MaximumBattery-d.Travelled {
 		d.BatteryDrains = true
 	}
 
-	d.Travelled += uint16(nextDistance)
+	d.Travelled += nextDistance
 
-	return uint16(nextDistance)
+	return nextDistance
 }
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDroneStartFlight(t *testing.T) {
+	estate := &Estate{Width: 1, Length: 5}
+	trees := []Tree{
+		{X: 2, Y: 1, Height: 10},
+		{X: 3, Y: 1, Height: 20},
+		{X: 4, Y: 1, Height: 10},
+	}
+
+	drone := NewDrone(estate, &trees, nil)
+	drone.StartFlight()
+	assert.Equal(t, uint64(82), drone.Travelled)
+}
+
+func TestDroneStartFlight_MaxDistance(t *testing.T) {
+	estate := &Estate{Width: 3, Length: 3}
+	trees := []Tree{
+		{X: 1, Y: 1, Height: 10},
+	}
+
+	maxDistance := uint64(10)
+	drone := NewDrone(estate, &trees, &maxDistance)
+	drone.StartFlight()
+	assert.Equal(t, uint64(10), drone.Travelled)
+	assert.Equal(t, uint16(1), drone.LastCoordinateX)
+	assert.Equal(t, uint16(1), drone.LastCoordinateY)
+}
+
+func TestDroneStartFlight_Trace(t *testing.T) {
+	estate := &Estate{Width: 1, Length: 3}
+	trees := []Tree{
+		{X: 2, Y: 1, Height: 10},
+	}
+
+	var trace bytes.Buffer
+	drone := NewDrone(estate, &trees, nil)
+	drone.Trace = slog.New(slog.NewJSONHandler(&trace, nil))
+	drone.StartFlight()
+
+	var messages []string
+	decoder := json.NewDecoder(&trace)
+	for decoder.More() {
+		var record map[string]any
+		if !assert.NoError(t, decoder.Decode(&record)) {
+			return
+		}
+		messages = append(messages, record["msg"].(string))
+	}
+
+	assert.Equal(t, "drone start flight", messages[0])
+	assert.Equal(t, "drone landed", messages[len(messages)-1])
+	assert.Contains(t, messages, "drone accend")
+	assert.Contains(t, messages, "drone decend")
+}
+
+func TestNewDrone_LargeEstateBoundedMemory(t *testing.T) {
+	estate := &Estate{Width: 50000, Length: 50000}
+	trees := make([]Tree, 0, 1000)
+	for i := uint16(1); i <= 1000; i++ {
+		trees = append(trees, Tree{X: i * 50, Y: i * 50, Height: 10})
+	}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	drone := NewDrone(estate, &trees, nil)
+
+	runtime.ReadMemStats(&after)
+
+	// A dense grid of this estate would need 2.5 GB
+	assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1<<20))
+	assert.Equal(t, 1000, drone.MappedTrees.Len())
+}
+
+func BenchmarkNewDrone_LargeEstate(b *testing.B) {
+	estate := &Estate{Width: 50000, Length: 50000}
+	trees := make([]Tree, 0, 10000)
+	for i := 0; i < 10000; i++ {
+		trees = append(trees, Tree{X: uint16(i%50000 + 1), Y: uint16(i*7%50000 + 1), Height: uint8(i%30 + 1)})
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		NewDrone(estate, &trees, nil)
+	}
+}
+
+func BenchmarkDroneStartFlight_LongEstate(b *testing.B) {
+	estate := &Estate{Width: 20, Length: 50000}
+	trees := make([]Tree, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		trees = append(trees, Tree{X: uint16(i*50 + 1), Y: uint16(i%20 + 1), Height: uint8(i%30 + 1)})
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		drone := NewDrone(estate, &trees, nil)
+		drone.StartFlight()
+	}
+}
@@ -0,0 +1,71 @@
+package models
+
+import "strings"
+
+// ErrorCode identifies a kind of error for the API clients, the values are
+// part of the API and must not change.
+type ErrorCode string
+
+const (
+	CodeValidation          ErrorCode = "validation_failed"
+	CodeOutOfBounds         ErrorCode = "out_of_bounds"
+	CodeDuplicateCoordinate ErrorCode = "duplicate_coordinate"
+	CodeEstateNotFound      ErrorCode = "estate_not_found"
+	CodeTreeNotFound        ErrorCode = "tree_not_found"
+	CodeTreeRetired         ErrorCode = "tree_retired"
+	CodeEstateFull          ErrorCode = "estate_full"
+	CodeWebhookNotFound     ErrorCode = "webhook_not_found"
+)
+
+// Error is an error of the domain, safe to show to the API clients. Errors
+// are matched by code, so errors.Is(err, ErrOutOfBounds) holds for any out of
+// bounds error whatever its message.
+type Error struct {
+	Code    ErrorCode
+	Message string
+	// Fields lists the invalid fields of a validation error
+	Fields []FieldError
+}
+
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+var (
+	ErrOutOfBounds         = &Error{Code: CodeOutOfBounds, Message: "outside of boundaries"}
+	ErrDuplicateCoordinate = &Error{Code: CodeDuplicateCoordinate, Message: "tree already exist in that coordinate"}
+	ErrEstateNotFound      = &Error{Code: CodeEstateNotFound, Message: "estate not found"}
+	ErrTreeNotFound        = &Error{Code: CodeTreeNotFound, Message: "tree not found"}
+	ErrTreeRetired         = &Error{Code: CodeTreeRetired, Message: "tree already retired"}
+	ErrEstateFull          = &Error{Code: CodeEstateFull, Message: "no empty plot left in the estate"}
+	ErrWebhookNotFound     = &Error{Code: CodeWebhookNotFound, Message: "webhook not found"}
+)
+
+// NewValidationError reports invalid input, one entry per invalid field
+func NewValidationError(fields ...FieldError) *Error {
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields {
+		messages = append(messages, field.Field+" "+field.Message)
+	}
+
+	message := "invalid request"
+	if len(messages) > 0 {
+		message = message + ": " + strings.Join(messages, ", ")
+	}
+
+	return &Error{
+		Code:    CodeValidation,
+		Message: message,
+		Fields:  fields,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Is(target error) bool {
+	other, ok := target.(*Error)
+	return ok && other.Code == e.Code
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError_IsMatchesCode(t *testing.T) {
+	err := fmt.Errorf("saving tree: %w", &Error{Code: CodeOutOfBounds, Message: "x is outside of the estate"})
+
+	assert.True(t, errors.Is(err, ErrOutOfBounds))
+	assert.False(t, errors.Is(err, ErrDuplicateCoordinate))
+	assert.False(t, errors.Is(err, errors.New("outside of boundaries")))
+}
+
+func TestNewValidationError(t *testing.T) {
+	err := NewValidationError(
+		FieldError{Field: "x", Message: "is required"},
+		FieldError{Field: "height", Message: "must be at most 30"},
+	)
+
+	assert.Equal(t, CodeValidation, err.Code)
+	assert.Equal(t, "invalid request: x is required, height must be at most 30", err.Error())
+	assert.Len(t, err.Fields, 2)
+
+	assert.Equal(t, "invalid request", NewValidationError().Error())
+}
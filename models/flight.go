@@ -0,0 +1,177 @@
+package models
+
+import "context"
+
+// CalculateFlight produces the same result as StartFlight without visiting
+// every plot. A plot without a tree only moves the drone forward by 10 m, so
+// runs of empty plots are flown over in a single step and the drone only
+// stops to read the data of the plots with a tree, plus the first and the last
+// plot which have their own rules. The cost depends on the estate width and
+// the number of trees instead of the number of plots.
+func (d *Drone) CalculateFlight() {
+	d.CalculateFlightContext(context.Background(), nil)
+}
+
+// CalculateFlightContext is CalculateFlight stopping early when the context is
+// cancelled, the progress callback receives the percentage of rows flown.
+func (d *Drone) CalculateFlightContext(ctx context.Context, progress func(percentage int)) error {
+	length := d.Estate.Length
+	width := d.Estate.Width
+
+	d.traceStart()
+
+	xValue := uint16(1)
+	for y := uint16(1); y <= width; y++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if progress != nil {
+			progress(int(uint64(y-1) * 100 / uint64(width)))
+		}
+
+		if d.BatteryDrains {
+			break
+		}
+
+		forward := xValue == 1
+		stops := d.flightStops(y, forward)
+
+		x := length
+		if forward {
+			x = 1
+		}
+
+		for _, stop := range stops {
+			gap := stop - x
+			if !forward {
+				gap = x - stop
+			}
+
+			d.FlyOverEmptyPlots(x, y, gap, forward)
+			if d.BatteryDrains {
+				break
+			}
+
+			d.ReadData(stop-1, y-1)
+			if d.BatteryDrains {
+				break
+			}
+
+			if forward {
+				x = stop + 1
+			} else {
+				x = stop - 1
+			}
+		}
+
+		if d.BatteryDrains {
+			break
+		}
+
+		// Remaining empty plots until the end of the row
+		if forward && x <= length {
+			d.FlyOverEmptyPlots(x, y, length-x+1, forward)
+		} else if !forward && x >= 1 {
+			d.FlyOverEmptyPlots(x, y, x, forward)
+		}
+
+		if forward {
+			xValue = length
+		} else {
+			xValue = 1
+		}
+	}
+
+	if progress != nil {
+		progress(100)
+	}
+
+	return nil
+}
+
+// flightStops returns the x coordinates of the plots in the row where the
+// drone needs to read the data, ordered in the direction of the flight.
+func (d *Drone) flightStops(y uint16, forward bool) []uint16 {
+	xs := d.MappedTrees.Row(y)
+
+	// The first and the last plot are never a plain empty plot
+	if y == 1 {
+		xs = insertSorted(xs, 1)
+	}
+	if y == d.Estate.Width {
+		xs = insertSorted(xs, d.Estate.Length)
+	}
+
+	if !forward {
+		for i, j := 0, len(xs)-1; i < j; i, j = i+1, j-1 {
+			xs[i], xs[j] = xs[j], xs[i]
+		}
+	}
+
+	return xs
+}
+
+func insertSorted(xs []uint16, x uint16) []uint16 {
+	i := 0
+	for i < len(xs) && xs[i] < x {
+		i++
+	}
+
+	if i < len(xs) && xs[i] == x {
+		return xs
+	}
+
+	xs = append(xs, 0)
+	copy(xs[i+1:], xs[i:])
+	xs[i] = x
+	return xs
+}
+
+// FlyOverEmptyPlots moves the drone forward over count empty plots starting
+// from the plot (x, y), stopping at the plot where the battery drains.
+func (d *Drone) FlyOverEmptyPlots(x uint16, y uint16, count uint16, forward bool) {
+	if count == 0 {
+		return
+	}
+
+	plotAt := func(i uint64) uint16 {
+		if forward {
+			return x + uint16(i)
+		}
+		return x - uint16(i)
+	}
+
+	distance := uint64(count) * 10
+
+	if d.Trace != nil {
+		d.Trace.Info("drone fly over empty plots", "x", x, "y", y, "count", count, "forward", forward)
+	}
+
+	if d.MaximumBattery == nil || distance < *d.MaximumBattery-d.Travelled {
+		d.Travelled += distance
+		d.PlotsVisited += uint64(count)
+		d.LastCoordinateX = plotAt(uint64(count) - 1)
+		d.LastCoordinateY = y
+		return
+	}
+
+	// The battery drains on one of the plots, the same way Forward does
+	remaining := *d.MaximumBattery - d.Travelled
+	plot := remaining / 10
+	nextDistance := uint64(10)
+	if remaining%10 != 0 {
+		plot++
+		nextDistance = remaining % 10
+	}
+
+	d.Travelled = *d.MaximumBattery
+	d.BatteryDrains = true
+	d.PlotsVisited += plot
+	d.LastCoordinateX = plotAt(plot - 1)
+	d.LastCoordinateY = y
+
+	if nextDistance <= 5 {
+		d.LastCoordinateX = d.LastCoordinateX - 1
+	}
+}
@@ -0,0 +1,200 @@
+package models
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func randomEstate(r *rand.Rand) (*Estate, []Tree) {
+	estate := &Estate{
+		Width:  uint16(r.Intn(12) + 1),
+		Length: uint16(r.Intn(12) + 1),
+	}
+
+	plots := int(estate.Width) * int(estate.Length)
+	trees := make([]Tree, 0)
+	for i := 0; i < plots; i++ {
+		if r.Intn(3) == 0 {
+			trees = append(trees, Tree{
+				X:      uint16(i%int(estate.Length) + 1),
+				Y:      uint16(i/int(estate.Length) + 1),
+				Height: uint8(r.Intn(30) + 1),
+			})
+		}
+	}
+
+	return estate, trees
+}
+
+func TestCalculateFlight_SameAsStartFlight(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		estate, trees := randomEstate(r)
+
+		var maxDistance *uint64
+		if r.Intn(2) == 0 {
+			value := uint64(r.Intn(1500) + 1)
+			maxDistance = &value
+		}
+
+		simulated := NewDrone(estate, &trees, maxDistance)
+		simulated.StartFlight()
+
+		calculated := NewDrone(estate, &trees, maxDistance)
+		calculated.CalculateFlight()
+
+		if !assert.Equal(t, simulated.Travelled, calculated.Travelled, "estate %dx%d trees %v max %v", estate.Length, estate.Width, trees, maxDistance) {
+			return
+		}
+		assert.Equal(t, simulated.BatteryDrains, calculated.BatteryDrains)
+		assert.Equal(t, simulated.CurrentHeight, calculated.CurrentHeight)
+		assert.Equal(t, simulated.LastCoordinateX, calculated.LastCoordinateX)
+		assert.Equal(t, simulated.LastCoordinateY, calculated.LastCoordinateY)
+		assert.Equal(t, simulated.PlotsVisited, calculated.PlotsVisited)
+		if maxDistance == nil {
+			assert.Equal(t, estate.PlotCount(), calculated.PlotsVisited)
+		}
+	}
+}
+
+func TestCalculateFlight_LargestEstate(t *testing.T) {
+	estate := &Estate{Width: 50000, Length: 50000}
+	trees := []Tree{
+		{X: 1, Y: 1, Height: 5},
+		{X: 25000, Y: 25000, Height: 10},
+	}
+
+	maxDistance := uint64(10000)
+	drone := NewDrone(estate, &trees, &maxDistance)
+
+	start := time.Now()
+	drone.CalculateFlight()
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.True(t, drone.BatteryDrains)
+	assert.Equal(t, maxDistance, drone.Travelled)
+}
+
+func TestCalculateFlight_LargestEstateFullFlight(t *testing.T) {
+	estate := &Estate{Width: 50000, Length: 50000}
+	drone := NewDrone(estate, &[]Tree{}, nil)
+	drone.CalculateFlight()
+
+	// 10 m between each plot, up 1 m at the start and down 1 m at the end
+	assert.Equal(t, uint64(10*(2500000000-1)+2), drone.Travelled)
+	assert.Greater(t, drone.Travelled, uint64(math.MaxUint32))
+	assert.False(t, drone.BatteryDrains)
+	assert.Equal(t, uint8(0), drone.CurrentHeight)
+}
+
+// TestCalculateFlight_MillionsOfTrees compares the flight over the largest
+// estate with the distance expected from the trees alone: 10 m between each
+// plot, plus climbing or descending to 1 m above every tree in flight order,
+// and landing on the last plot, which with an even width is the first plot of
+// the last row, the drone then takes off again for the trees left in the row.
+func TestCalculateFlight_MillionsOfTrees(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skip flight over millions of trees")
+	}
+
+	const length, width = 50000, 50000
+	estate := &Estate{Width: width, Length: length}
+
+	r := rand.New(rand.NewSource(1))
+	taken := make(map[[2]uint16]bool)
+	trees := make([]Tree, 0, 2000000)
+	for len(trees) < cap(trees) {
+		x, y := uint16(r.Intn(length)+1), uint16(r.Intn(width)+1)
+
+		// The first two plots and the last one have their own rules
+		if (y == 1 && x <= 2) || (y == width && x == length) || taken[[2]uint16{x, y}] {
+			continue
+		}
+
+		taken[[2]uint16{x, y}] = true
+		trees = append(trees, Tree{X: x, Y: y, Height: uint8(r.Intn(30) + 1)})
+	}
+
+	drone := NewDrone(estate, &trees, nil)
+	start := time.Now()
+	drone.CalculateFlight()
+	t.Logf("flight over %d trees calculated in %v", len(trees), time.Since(start))
+
+	// The landing plot has no tree, a zero height marks it
+	ordered := append([]Tree{{X: length, Y: width}}, trees...)
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].Y != ordered[j].Y {
+			return ordered[i].Y < ordered[j].Y
+		}
+		// Odd rows are flown forward, even rows backward
+		if ordered[i].Y%2 == 1 {
+			return ordered[i].X < ordered[j].X
+		}
+		return ordered[i].X > ordered[j].X
+	})
+
+	expected := uint64(10 * (length*width - 1))
+	altitude := uint64(1)
+	expected += altitude
+	for _, tree := range ordered {
+		if tree.Height == 0 {
+			expected += altitude
+			altitude = 0
+			continue
+		}
+
+		target := uint64(tree.Height) + 1
+		if target > altitude {
+			expected += target - altitude
+		} else {
+			expected += altitude - target
+		}
+		altitude = target
+	}
+
+	assert.Equal(t, expected, drone.Travelled)
+}
+
+func BenchmarkCalculateFlight_LargestEstate(b *testing.B) {
+	estate := &Estate{Width: 50000, Length: 50000}
+	trees := make([]Tree, 0, 10000)
+	for i := 0; i < 10000; i++ {
+		trees = append(trees, Tree{X: uint16(i%50000 + 1), Y: uint16(i*7%50000 + 1), Height: uint8(i%30 + 1)})
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		drone := NewDrone(estate, &trees, nil)
+		drone.CalculateFlight()
+	}
+}
+
+func TestCalculateFlightContext_Cancelled(t *testing.T) {
+	estate := &Estate{Width: 50000, Length: 50000}
+	drone := NewDrone(estate, &[]Tree{}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := drone.CalculateFlightContext(ctx, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestCalculateFlightContext_Progress(t *testing.T) {
+	estate := &Estate{Width: 4, Length: 4}
+	drone := NewDrone(estate, &[]Tree{}, nil)
+
+	var progress []int
+	err := drone.CalculateFlightContext(context.Background(), func(percentage int) {
+		progress = append(progress, percentage)
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 25, 50, 75, 100}, progress)
+}
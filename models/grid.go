@@ -0,0 +1,73 @@
+package models
+
+import "sort"
+
+// TreeGrid is a sparse map of the tree heights in an estate. Only the plots
+// with a tree are stored, indexed by row (y) then by column (x), so the memory
+// used is proportional to the number of trees instead of the estate size.
+type TreeGrid struct {
+	rows  map[uint16]map[uint16]uint8
+	count int
+}
+
+func NewTreeGrid(estateTrees *[]Tree) *TreeGrid {
+	grid := TreeGrid{
+		rows: make(map[uint16]map[uint16]uint8),
+	}
+
+	for _, tree := range *estateTrees {
+		grid.Set(tree.X, tree.Y, tree.Height)
+	}
+
+	return &grid
+}
+
+// Set stores the tree height of the plot, a height of 0 removes the tree.
+func (g *TreeGrid) Set(x uint16, y uint16, height uint8) {
+	row, ok := g.rows[y]
+	if height == 0 {
+		if _, exists := row[x]; exists {
+			delete(row, x)
+			g.count--
+			if len(row) == 0 {
+				delete(g.rows, y)
+			}
+		}
+		return
+	}
+
+	if !ok {
+		row = make(map[uint16]uint8)
+		g.rows[y] = row
+	}
+
+	if _, exists := row[x]; !exists {
+		g.count++
+	}
+	row[x] = height
+}
+
+// Height returns the tree height of the plot, or 0 when the plot is empty.
+// Coordinates start from 1 like the tree coordinates.
+func (g *TreeGrid) Height(x uint16, y uint16) uint8 {
+	return g.rows[y][x]
+}
+
+func (g *TreeGrid) Len() int {
+	return g.count
+}
+
+// Row returns the x coordinates of the trees in the row y, sorted ascending.
+func (g *TreeGrid) Row(y uint16) []uint16 {
+	row := g.rows[y]
+	xs := make([]uint16, 0, len(row))
+	for x := range row {
+		xs = append(xs, x)
+	}
+
+	sort.Slice(xs, func(i, j int) bool {
+		return xs[i] < xs[j]
+	})
+
+	return xs
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTreeGrid(t *testing.T) {
+	trees := []Tree{
+		{X: 1, Y: 1, Height: 10},
+		{X: 50000, Y: 50000, Height: 30},
+	}
+
+	grid := NewTreeGrid(&trees)
+	assert.Equal(t, 2, grid.Len())
+	assert.Equal(t, uint8(10), grid.Height(1, 1))
+	assert.Equal(t, uint8(30), grid.Height(50000, 50000))
+	assert.Equal(t, uint8(0), grid.Height(2, 1))
+	assert.Equal(t, uint8(0), grid.Height(1, 2))
+
+	grid.Set(1, 1, 15)
+	assert.Equal(t, 2, grid.Len())
+	assert.Equal(t, uint8(15), grid.Height(1, 1))
+
+	grid.Set(1, 1, 0)
+	assert.Equal(t, 1, grid.Len())
+	assert.Equal(t, uint8(0), grid.Height(1, 1))
+}
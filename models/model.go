@@ -3,6 +3,7 @@ package models
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,14 +13,15 @@ import (
 type Tree struct {
 	bun.BaseModel `bun:"table:trees"`
 
-	ID        uint64    `bun:"id,pk"`
-	EstateID  uint64    `bun:"estate_id,notnull"`
-	UUID      string    `bun:"uuid,notnull"`
-	X         uint16    `bun:"x,notnull"`
-	Y         uint16    `bun:"y,notnull"`
-	Height    uint8     `bun:"height,notnull"`
-	CreatedAt time.Time `bun:"created_at"`
-	UpdatedAt time.Time `bun:"updated_at"`
+	ID        uint64     `bun:"id,pk"`
+	EstateID  uint64     `bun:"estate_id,notnull"`
+	UUID      string     `bun:"uuid,notnull"`
+	X         uint16     `bun:"x,notnull"`
+	Y         uint16     `bun:"y,notnull"`
+	Height    uint8      `bun:"height,notnull"`
+	RetiredAt *time.Time `bun:"retired_at,nullzero"`
+	CreatedAt time.Time  `bun:"created_at"`
+	UpdatedAt time.Time  `bun:"updated_at"`
 
 	Estate *Estate `bun:"rel:belongs-to"`
 }
@@ -29,34 +31,78 @@ type Estate struct {
 
 	ID               uint64    `bun:"id,pk"`
 	UUID             string    `bun:"uuid,notnull"`
+	OrganisationID   string    `bun:"organisation_id,notnull"`
 	Width            uint16    `bun:"width,notnull"`
 	Length           uint16    `bun:"length,notnull"`
-	TreeCount        uint8     `bun:"tree_count,notnull"`
+	TreeCount        uint32    `bun:"tree_count,notnull"`
 	MinTreeHeight    uint8     `bun:"min_tree_height"`
 	MaxTreeHeight    uint8     `bun:"max_tree_height"`
 	MedianTreeHeight uint8     `bun:"median_tree_height"`
+	Version          uint64    `bun:"version,notnull"`
 	CreatedAt        time.Time `bun:"created_at"`
 	UpdatedAt        time.Time `bun:"updated_at"`
 }
 
-func NewEstate(width uint16, length uint16) *Estate {
+// MaxDimension is the largest width and length of an estate, MaxHeight the
+// height of the tallest tree
+const (
+	MaxDimension = 50000
+	MaxHeight    = 30
+)
+
+// checkRange adds the error of field to fields when value is not between 1
+// and max, the values are checked before being narrowed to the columns
+func checkRange(fields []FieldError, field string, value int, max int) []FieldError {
+	if value < 1 || value > max {
+		fields = append(fields, FieldError{
+			Field:   field,
+			Message: fmt.Sprintf("must be between 1 and %d", max),
+		})
+	}
+
+	return fields
+}
+
+func NewEstate(width int, length int) (*Estate, error) {
+	var fields []FieldError
+	fields = checkRange(fields, "width", width, MaxDimension)
+	fields = checkRange(fields, "length", length, MaxDimension)
+	if len(fields) > 0 {
+		return nil, NewValidationError(fields...)
+	}
+
 	return &Estate{
 		UUID:      uuid.NewString(),
-		Width:     width,
-		Length:    length,
+		Width:     uint16(width),
+		Length:    uint16(length),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
-	}
+	}, nil
 }
 
-func NewTree(estate *Estate, x uint16, y uint16, height uint8) (*Tree, error) {
+// PlotCount is the number of plots of the estate, also the maximum number of
+// active trees. The largest estate has 2.5 billion plots, more than an uint32
+// dimension product can hold.
+func (e *Estate) PlotCount() uint64 {
+	return uint64(e.Width) * uint64(e.Length)
+}
+
+func NewTree(estate *Estate, x int, y int, height int) (*Tree, error) {
+	var fields []FieldError
+	fields = checkRange(fields, "x", x, MaxDimension)
+	fields = checkRange(fields, "y", y, MaxDimension)
+	fields = checkRange(fields, "height", height, MaxHeight)
+	if len(fields) > 0 {
+		return nil, NewValidationError(fields...)
+	}
+
 	tree := Tree{
 		UUID:      uuid.NewString(),
 		EstateID:  estate.ID,
 		Estate:    estate,
-		X:         x,
-		Y:         y,
-		Height:    height,
+		X:         uint16(x),
+		Y:         uint16(y),
+		Height:    uint8(height),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -75,7 +121,6 @@ func (e *Estate) CalculateEstateTreeMedian(trees *[]Tree) (err error) {
 		values = append(values, float64(tree.Height))
 	}
 
-	fmt.Println(values)
 	l := len(values)
 	if l == 0 {
 		return
@@ -90,12 +135,18 @@ func (e *Estate) CalculateEstateTreeMedian(trees *[]Tree) (err error) {
 
 func (t *Tree) CalculateEstateTreeStats() (err error) {
 	if t.X > t.Estate.Length {
-		err = errors.New("outside of boundaries")
+		err = ErrOutOfBounds
 		return
 	}
 
 	if t.Y > t.Estate.Width {
-		err = errors.New("outside of boundaries")
+		err = ErrOutOfBounds
+		return
+	}
+
+	// Checked before any change, the estate is left as it was on error
+	if uint64(t.Estate.TreeCount) >= t.Estate.PlotCount() {
+		err = ErrEstateFull
 		return
 	}
 
@@ -128,3 +179,50 @@ func (t *Tree) CalculateEstateTreeStats() (err error) {
 
 	return
 }
+
+func (t *Tree) IsRetired() bool {
+	return t.RetiredAt != nil
+}
+
+// Retire marks the tree as no longer standing. The record is kept so the
+// plot history can still be viewed, and a new tree can be planted in its place.
+func (t *Tree) Retire() (err error) {
+	if t.IsRetired() {
+		err = ErrTreeRetired
+		return
+	}
+
+	now := time.Now()
+	t.RetiredAt = &now
+	t.UpdatedAt = now
+	return
+}
+
+// RecalculateEstateTreeStats rebuilds the estate stats from scratch using only
+// the given active trees, used when a tree is retired and the incremental
+// stats can no longer be trusted.
+func (e *Estate) RecalculateEstateTreeStats(trees *[]Tree) (err error) {
+	treeValues := *trees
+	if uint64(len(treeValues)) > e.PlotCount() {
+		err = errors.New("more trees than plots in the estate")
+		return
+	}
+
+	sort.Slice(treeValues, func(i, j int) bool {
+		return treeValues[i].Height < treeValues[j].Height
+	})
+
+	e.TreeCount = uint32(len(treeValues))
+	e.UpdatedAt = time.Now()
+
+	if len(treeValues) == 0 {
+		e.MinTreeHeight = 0
+		e.MaxTreeHeight = 0
+		e.MedianTreeHeight = 0
+		return
+	}
+
+	e.MinTreeHeight = treeValues[0].Height
+	e.MaxTreeHeight = treeValues[len(treeValues)-1].Height
+	return e.CalculateEstateTreeMedian(&treeValues)
+}
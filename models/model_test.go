@@ -8,17 +8,29 @@ import (
 )
 
 func TestNewEstate(t *testing.T) {
-	width := uint16(10)
-	length := uint16(20)
-
-	estate := NewEstate(width, length)
+	estate, err := NewEstate(10, 20)
+	assert.NoError(t, err)
 
 	assert.NotNil(t, estate)
 
 	assert.NotEmpty(t, estate.UUID)
 
-	assert.Equal(t, width, estate.Width)
-	assert.Equal(t, length, estate.Length)
+	assert.Equal(t, uint16(10), estate.Width)
+	assert.Equal(t, uint16(20), estate.Length)
+}
+
+func TestNewEstate_OutOfRange(t *testing.T) {
+	// 65537 would wrap to an uint16 of 1
+	_, err := NewEstate(65537, 0)
+
+	var domainErr *Error
+	if assert.ErrorAs(t, err, &domainErr) {
+		assert.Equal(t, CodeValidation, domainErr.Code)
+		assert.Equal(t, []FieldError{
+			{Field: "width", Message: "must be between 1 and 50000"},
+			{Field: "length", Message: "must be between 1 and 50000"},
+		}, domainErr.Fields)
+	}
 }
 
 func TestNewTree(t *testing.T) {
@@ -48,7 +60,24 @@ func TestNewTree(t *testing.T) {
 	assert.Equal(t, uint8(20), newTree.Estate.MinTreeHeight)
 	assert.Equal(t, uint8(20), newTree.Estate.MaxTreeHeight)
 	assert.Equal(t, uint8(20), newTree.Estate.MedianTreeHeight)
-	assert.Equal(t, uint8(1), newTree.Estate.TreeCount)
+	assert.Equal(t, uint32(1), newTree.Estate.TreeCount)
+}
+
+func TestNewTree_OutOfRange(t *testing.T) {
+	estate := &Estate{Width: 100, Length: 100}
+
+	// 65537 and 257 would wrap to 1
+	_, err := NewTree(estate, 65537, 1, 257)
+
+	var domainErr *Error
+	if assert.ErrorAs(t, err, &domainErr) {
+		assert.Equal(t, CodeValidation, domainErr.Code)
+		assert.Equal(t, []FieldError{
+			{Field: "x", Message: "must be between 1 and 50000"},
+			{Field: "height", Message: "must be between 1 and 30"},
+		}, domainErr.Fields)
+	}
+	assert.Equal(t, uint32(0), estate.TreeCount)
 }
 
 func TestCalculateEstateTreeStats(t *testing.T) {
@@ -74,7 +103,7 @@ func TestCalculateEstateTreeStats(t *testing.T) {
 	assert.Equal(t, uint8(20), tree.Estate.MinTreeHeight)
 	assert.Equal(t, uint8(20), tree.Estate.MaxTreeHeight)
 	assert.Equal(t, uint8(20), tree.Estate.MedianTreeHeight)
-	assert.Equal(t, uint8(1), tree.Estate.TreeCount)
+	assert.Equal(t, uint32(1), tree.Estate.TreeCount)
 }
 
 func TestCalculateEstateTreeStats_OutsideBoundaries(t *testing.T) {
@@ -101,3 +130,130 @@ func TestCalculateEstateTreeStats_OutsideBoundaries(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, "outside of boundaries", err.Error())
 }
+
+func TestCalculateEstateTreeStats_EstateFull(t *testing.T) {
+	mockEstate := &Estate{
+		ID:               1,
+		Width:            1,
+		Length:           1,
+		MinTreeHeight:    10,
+		MaxTreeHeight:    10,
+		MedianTreeHeight: 10,
+		TreeCount:        1,
+	}
+
+	tree := &Tree{
+		UUID:   "mockUUID",
+		Estate: mockEstate,
+		X:      1,
+		Y:      1,
+		Height: 20,
+	}
+
+	err := tree.CalculateEstateTreeStats()
+	assert.ErrorIs(t, err, ErrEstateFull)
+
+	// The stats are left as they were
+	assert.Equal(t, uint8(10), mockEstate.MinTreeHeight)
+	assert.Equal(t, uint8(10), mockEstate.MaxTreeHeight)
+	assert.Equal(t, uint8(10), mockEstate.MedianTreeHeight)
+	assert.Equal(t, uint32(1), mockEstate.TreeCount)
+}
+
+func TestTreeRetire(t *testing.T) {
+	tree := &Tree{
+		UUID:   "mockUUID",
+		Height: 20,
+	}
+
+	err := tree.Retire()
+	assert.NoError(t, err)
+	assert.True(t, tree.IsRetired())
+	assert.NotNil(t, tree.RetiredAt)
+
+	err = tree.Retire()
+	assert.Error(t, err)
+	assert.Equal(t, "tree already retired", err.Error())
+}
+
+func TestRecalculateEstateTreeStats(t *testing.T) {
+	mockEstate := &Estate{
+		ID:               1,
+		Width:            100,
+		Length:           100,
+		MinTreeHeight:    5,
+		MaxTreeHeight:    30,
+		MedianTreeHeight: 15,
+		TreeCount:        4,
+	}
+
+	trees := []Tree{
+		{Height: 30},
+		{Height: 10},
+		{Height: 20},
+	}
+
+	assert.NoError(t, mockEstate.RecalculateEstateTreeStats(&trees))
+	assert.Equal(t, uint32(3), mockEstate.TreeCount)
+	assert.Equal(t, uint8(10), mockEstate.MinTreeHeight)
+	assert.Equal(t, uint8(30), mockEstate.MaxTreeHeight)
+	assert.Equal(t, uint8(20), mockEstate.MedianTreeHeight)
+
+	assert.NoError(t, mockEstate.RecalculateEstateTreeStats(&[]Tree{}))
+	assert.Equal(t, uint32(0), mockEstate.TreeCount)
+	assert.Equal(t, uint8(0), mockEstate.MinTreeHeight)
+	assert.Equal(t, uint8(0), mockEstate.MaxTreeHeight)
+	assert.Equal(t, uint8(0), mockEstate.MedianTreeHeight)
+}
+
+func TestEstate_PlotCount(t *testing.T) {
+	estate := &Estate{Width: 50000, Length: 50000}
+
+	assert.Equal(t, uint64(2500000000), estate.PlotCount())
+}
+
+func TestNewTree_MoreThan255Trees(t *testing.T) {
+	estate := &Estate{Width: 20, Length: 20}
+
+	for y := 1; y <= 20; y++ {
+		for x := 1; x <= 20; x++ {
+			_, err := NewTree(estate, x, y, 10)
+			assert.NoError(t, err)
+		}
+	}
+
+	assert.Equal(t, uint32(400), estate.TreeCount)
+
+	// Every plot already has a tree
+	_, err := NewTree(estate, 1, 1, 10)
+	assert.Error(t, err)
+	assert.Equal(t, uint32(400), estate.TreeCount)
+}
+
+func TestRecalculateEstateTreeStats_MillionsOfTrees(t *testing.T) {
+	estate := &Estate{Width: 50000, Length: 50000}
+
+	const treeCount = 2000000
+	trees := make([]Tree, 0, treeCount)
+	for i := 0; i < treeCount; i++ {
+		trees = append(trees, Tree{
+			X:      uint16(i%50000 + 1),
+			Y:      uint16(i/50000 + 1),
+			Height: uint8(i%30 + 1),
+		})
+	}
+
+	assert.NoError(t, estate.RecalculateEstateTreeStats(&trees))
+	assert.Equal(t, uint32(treeCount), estate.TreeCount)
+	assert.Equal(t, uint8(1), estate.MinTreeHeight)
+	assert.Equal(t, uint8(30), estate.MaxTreeHeight)
+}
+
+func TestRecalculateEstateTreeStats_MoreTreesThanPlots(t *testing.T) {
+	estate := &Estate{Width: 1, Length: 2}
+
+	trees := []Tree{{Height: 1}, {Height: 2}, {Height: 3}}
+
+	assert.Error(t, estate.RecalculateEstateTreeStats(&trees))
+	assert.Equal(t, uint32(0), estate.TreeCount)
+}
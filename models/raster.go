@@ -0,0 +1,211 @@
+package models
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+)
+
+type RasterMetric string
+
+const (
+	RasterMetricHeight  RasterMetric = "height"
+	RasterMetricDensity RasterMetric = "density"
+)
+
+const (
+	DefaultRasterSize = 512
+	MaxRasterSize     = 2048
+
+	// RasterNoData is written in the ASCII grid for tiles without any tree
+	RasterNoData = -9999
+)
+
+// Raster is a downsampled view of the estate where each cell aggregates a
+// square tile of plots. It uses the same orientation as the drone grid, x runs
+// along the estate length and y along the estate width, so a 50000x50000
+// estate only needs a few hundred thousand cells instead of one per plot.
+type Raster struct {
+	Metric   RasterMetric
+	TileSize int
+	Columns  int
+	Rows     int
+
+	// Values is stored row by row starting from y = 1, a cell is NaN when
+	// the metric is height and the tile has no tree.
+	Values []float64
+	Counts []int
+}
+
+// NewRaster aggregates the trees of the estate into at most size x size tiles.
+// For the height metric each cell holds the average tree height of the tile,
+// for the density metric it holds the ratio of plots in the tile with a tree.
+func NewRaster(estate *Estate, estateTrees *[]Tree, metric RasterMetric, size int) (*Raster, error) {
+	if metric != RasterMetricHeight && metric != RasterMetricDensity {
+		return nil, NewValidationError(FieldError{Field: "metric", Message: "is unknown"})
+	}
+
+	if size < 1 || size > MaxRasterSize {
+		return nil, NewValidationError(FieldError{Field: "size", Message: fmt.Sprintf("must be between 1 and %d", MaxRasterSize)})
+	}
+
+	longestSide := int(estate.Length)
+	if int(estate.Width) > longestSide {
+		longestSide = int(estate.Width)
+	}
+
+	tileSize := (longestSide + size - 1) / size
+	if tileSize < 1 {
+		tileSize = 1
+	}
+
+	raster := Raster{
+		Metric:   metric,
+		TileSize: tileSize,
+		Columns:  (int(estate.Length) + tileSize - 1) / tileSize,
+		Rows:     (int(estate.Width) + tileSize - 1) / tileSize,
+	}
+	raster.Values = make([]float64, raster.Columns*raster.Rows)
+	raster.Counts = make([]int, raster.Columns*raster.Rows)
+
+	for _, tree := range *estateTrees {
+		if tree.X < 1 || tree.X > estate.Length || tree.Y < 1 || tree.Y > estate.Width {
+			continue
+		}
+
+		i := raster.index(int(tree.X-1)/tileSize, int(tree.Y-1)/tileSize)
+		raster.Counts[i]++
+		raster.Values[i] += float64(tree.Height)
+	}
+
+	for row := 0; row < raster.Rows; row++ {
+		for column := 0; column < raster.Columns; column++ {
+			i := raster.index(column, row)
+
+			switch metric {
+			case RasterMetricHeight:
+				if raster.Counts[i] == 0 {
+					raster.Values[i] = math.NaN()
+				} else {
+					raster.Values[i] = raster.Values[i] / float64(raster.Counts[i])
+				}
+			case RasterMetricDensity:
+				plots := raster.tilePlots(estate, column, row)
+				raster.Values[i] = float64(raster.Counts[i]) / float64(plots)
+			}
+		}
+	}
+
+	return &raster, nil
+}
+
+func (r *Raster) index(column int, row int) int {
+	return row*r.Columns + column
+}
+
+// tilePlots returns the number of plots covered by a tile, the tiles on the
+// far edges can be smaller when the estate is not a multiple of the tile size.
+func (r *Raster) tilePlots(estate *Estate, column int, row int) int {
+	width := r.TileSize
+	if remaining := int(estate.Length) - column*r.TileSize; remaining < width {
+		width = remaining
+	}
+
+	height := r.TileSize
+	if remaining := int(estate.Width) - row*r.TileSize; remaining < height {
+		height = remaining
+	}
+
+	return width * height
+}
+
+// maxValue is the value drawn with the hottest color in the heatmap
+func (r *Raster) maxValue() float64 {
+	if r.Metric == RasterMetricDensity {
+		return 1
+	}
+
+	return 30
+}
+
+// EncodePNG writes the raster as a heatmap image, y = 1 is the bottom row and
+// tiles without any tree are left transparent.
+func (r *Raster) EncodePNG(w io.Writer) error {
+	img := image.NewNRGBA(image.Rect(0, 0, r.Columns, r.Rows))
+
+	for row := 0; row < r.Rows; row++ {
+		for column := 0; column < r.Columns; column++ {
+			i := r.index(column, row)
+			if r.Counts[i] == 0 {
+				continue
+			}
+
+			img.SetNRGBA(column, r.Rows-1-row, heatmapColor(r.Values[i]/r.maxValue()))
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// EncodeASCIIGrid writes the raster in the ESRI ASCII grid format, which can be
+// opened by most GIS tools. The cell size is expressed in plots.
+func (r *Raster) EncodeASCIIGrid(w io.Writer) error {
+	buf := bufio.NewWriter(w)
+
+	fmt.Fprintf(buf, "ncols %d\n", r.Columns)
+	fmt.Fprintf(buf, "nrows %d\n", r.Rows)
+	fmt.Fprintf(buf, "xllcorner 0\n")
+	fmt.Fprintf(buf, "yllcorner 0\n")
+	fmt.Fprintf(buf, "cellsize %d\n", r.TileSize)
+	fmt.Fprintf(buf, "NODATA_value %d\n", RasterNoData)
+
+	for row := r.Rows - 1; row >= 0; row-- {
+		for column := 0; column < r.Columns; column++ {
+			if column > 0 {
+				buf.WriteByte(' ')
+			}
+
+			value := r.Values[r.index(column, row)]
+			if math.IsNaN(value) {
+				fmt.Fprintf(buf, "%d", RasterNoData)
+			} else {
+				fmt.Fprintf(buf, "%.4g", value)
+			}
+		}
+		buf.WriteByte('\n')
+	}
+
+	return buf.Flush()
+}
+
+// heatmapColor maps a value between 0 and 1 to a blue, green, yellow, red ramp
+func heatmapColor(value float64) color.NRGBA {
+	value = math.Max(0, math.Min(1, value))
+
+	stops := []color.NRGBA{
+		{R: 0, G: 0, B: 255, A: 255},
+		{R: 0, G: 200, B: 0, A: 255},
+		{R: 255, G: 230, B: 0, A: 255},
+		{R: 220, G: 0, B: 0, A: 255},
+	}
+
+	position := value * float64(len(stops)-1)
+	low := int(math.Floor(position))
+	if low >= len(stops)-1 {
+		return stops[len(stops)-1]
+	}
+
+	ratio := position - float64(low)
+	from, to := stops[low], stops[low+1]
+
+	return color.NRGBA{
+		R: uint8(float64(from.R) + (float64(to.R)-float64(from.R))*ratio),
+		G: uint8(float64(from.G) + (float64(to.G)-float64(from.G))*ratio),
+		B: uint8(float64(from.B) + (float64(to.B)-float64(from.B))*ratio),
+		A: 255,
+	}
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"bytes"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRaster_Height(t *testing.T) {
+	estate := &Estate{Width: 4, Length: 4}
+	trees := []Tree{
+		{X: 1, Y: 1, Height: 10},
+		{X: 2, Y: 2, Height: 20},
+		{X: 4, Y: 4, Height: 5},
+	}
+
+	raster, err := NewRaster(estate, &trees, RasterMetricHeight, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, raster.TileSize)
+	assert.Equal(t, 2, raster.Columns)
+	assert.Equal(t, 2, raster.Rows)
+	assert.Equal(t, float64(15), raster.Values[0])
+	assert.True(t, math.IsNaN(raster.Values[1]))
+	assert.True(t, math.IsNaN(raster.Values[2]))
+	assert.Equal(t, float64(5), raster.Values[3])
+}
+
+func TestNewRaster_Density(t *testing.T) {
+	estate := &Estate{Width: 3, Length: 3}
+	trees := []Tree{
+		{X: 1, Y: 1, Height: 10},
+		{X: 2, Y: 1, Height: 10},
+		{X: 3, Y: 3, Height: 10},
+	}
+
+	raster, err := NewRaster(estate, &trees, RasterMetricDensity, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, raster.TileSize)
+	assert.Equal(t, 0.5, raster.Values[0])
+	assert.Equal(t, float64(0), raster.Values[1])
+	assert.Equal(t, float64(0), raster.Values[2])
+	assert.Equal(t, float64(1), raster.Values[3])
+}
+
+func TestNewRaster_LargeEstate(t *testing.T) {
+	estate := &Estate{Width: 50000, Length: 50000}
+	trees := []Tree{
+		{X: 1, Y: 1, Height: 10},
+		{X: 50000, Y: 50000, Height: 30},
+	}
+
+	raster, err := NewRaster(estate, &trees, RasterMetricHeight, DefaultRasterSize)
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, raster.Columns, DefaultRasterSize)
+	assert.LessOrEqual(t, raster.Rows, DefaultRasterSize)
+	assert.Equal(t, float64(10), raster.Values[0])
+	assert.Equal(t, float64(30), raster.Values[len(raster.Values)-1])
+}
+
+func TestNewRaster_InvalidOptions(t *testing.T) {
+	estate := &Estate{Width: 3, Length: 3}
+
+	_, err := NewRaster(estate, &[]Tree{}, "unknown", 2)
+	assert.Error(t, err)
+
+	_, err = NewRaster(estate, &[]Tree{}, RasterMetricHeight, MaxRasterSize+1)
+	assert.Error(t, err)
+}
+
+func TestRasterEncode(t *testing.T) {
+	estate := &Estate{Width: 2, Length: 3}
+	trees := []Tree{
+		{X: 1, Y: 1, Height: 10},
+		{X: 3, Y: 2, Height: 30},
+	}
+
+	raster, err := NewRaster(estate, &trees, RasterMetricHeight, 3)
+	assert.NoError(t, err)
+
+	var grid bytes.Buffer
+	assert.NoError(t, raster.EncodeASCIIGrid(&grid))
+	lines := strings.Split(strings.TrimSpace(grid.String()), "\n")
+	assert.Equal(t, "ncols 3", lines[0])
+	assert.Equal(t, "nrows 2", lines[1])
+	assert.Equal(t, "-9999 -9999 30", lines[6])
+	assert.Equal(t, "10 -9999 -9999", lines[7])
+
+	var img bytes.Buffer
+	assert.NoError(t, raster.EncodePNG(&img))
+	decoded, err := png.Decode(&img)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, decoded.Bounds().Dx())
+	assert.Equal(t, 2, decoded.Bounds().Dy())
+}
@@ -0,0 +1,296 @@
+package models
+
+import (
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// EventType names a change notified to the webhooks, the values are part of
+// the API and must not change.
+type EventType string
+
+const (
+	EventEstateCreated      EventType = "estate.created"
+	EventTreeAdded          EventType = "tree.added"
+	EventTreeRetired        EventType = "tree.retired"
+	EventEstateStatsChanged EventType = "estate.stats_changed"
+	EventDronePlanComputed  EventType = "drone_plan.computed"
+)
+
+// IsKnown tells if t is one of the event types above
+func (t EventType) IsKnown() bool {
+	switch t {
+	case EventEstateCreated, EventTreeAdded, EventTreeRetired, EventEstateStatsChanged, EventDronePlanComputed:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsChange tells if t notifies a change of the estates, the outbox only
+// keeps those. A computed drone plan changes nothing.
+func (t EventType) IsChange() bool {
+	return t.IsKnown() && t != EventDronePlanComputed
+}
+
+// EventTypes is a set of event types, stored as a comma separated list
+type EventTypes []EventType
+
+func (t EventTypes) Contains(eventType EventType) bool {
+	for _, value := range t {
+		if value == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t EventTypes) Value() (driver.Value, error) {
+	values := make([]string, 0, len(t))
+	for _, value := range t {
+		values = append(values, string(value))
+	}
+
+	return strings.Join(values, ","), nil
+}
+
+func (t *EventTypes) Scan(src any) error {
+	var value string
+	switch src := src.(type) {
+	case string:
+		value = src
+	case []byte:
+		value = string(src)
+	case nil:
+	default:
+		return fmt.Errorf("cannot scan %T into event types", src)
+	}
+
+	*t = nil
+	for _, eventType := range strings.Split(value, ",") {
+		if eventType != "" {
+			*t = append(*t, EventType(eventType))
+		}
+	}
+
+	return nil
+}
+
+// Event is a change of an estate to notify, Payload is the JSON body sent to
+// the webhooks. The changes are also kept in the outbox until the relay
+// publishes them.
+type Event struct {
+	bun.BaseModel `bun:"table:outbox_events"`
+
+	// ID orders the events of the outbox, it is set once saved there
+	ID             uint64    `bun:"id,pk,autoincrement"`
+	UUID           string    `bun:"uuid,notnull"`
+	Type           EventType `bun:"type,notnull"`
+	OrganisationID string    `bun:"organisation_id,notnull"`
+	EstateUUID     string    `bun:"estate_uuid,notnull"`
+	Payload        string    `bun:"payload,notnull"`
+	CreatedAt      time.Time `bun:"created_at,notnull"`
+}
+
+type Webhook struct {
+	bun.BaseModel `bun:"table:webhooks"`
+
+	ID             uint64 `bun:"id,pk"`
+	UUID           string `bun:"uuid,notnull"`
+	OrganisationID string `bun:"organisation_id,notnull"`
+	// EstateUUID limits the webhook to the events of one estate, it receives
+	// those of every estate of its organisation when nil
+	EstateUUID *string    `bun:"estate_uuid"`
+	URL        string     `bun:"url,notnull"`
+	Secret     string     `bun:"secret,notnull"`
+	Events     EventTypes `bun:"events,notnull"`
+	CreatedAt  time.Time  `bun:"created_at"`
+}
+
+// WebhookPolicy is what the operator lets the webhooks reach. By default
+// they must be https URLs of public addresses, so that a tenant cannot make
+// the server POST to the services of its own network.
+type WebhookPolicy struct {
+	// AllowHTTP accepts the http URLs as well
+	AllowHTTP bool
+	// AllowPrivateNetworks accepts the loopback, private and link-local
+	// addresses, e.g. for the receivers of a local setup
+	AllowPrivateNetworks bool
+}
+
+// CheckURL tells if webhookURL may be registered. Its host is only checked
+// when it is an address, the addresses it resolves to are checked by
+// AllowsAddress when connecting.
+func (p WebhookPolicy) CheckURL(webhookURL string) error {
+	parsed, err := url.Parse(webhookURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || parsed.Hostname() == "" {
+		return NewValidationError(FieldError{Field: "url", Message: "must be an absolute http or https URL"})
+	}
+
+	if parsed.Scheme == "http" && !p.AllowHTTP {
+		return NewValidationError(FieldError{Field: "url", Message: "must be an https URL"})
+	}
+
+	host := parsed.Hostname()
+	if addr, err := netip.ParseAddr(host); (err == nil && !p.AllowsAddress(addr)) ||
+		(!p.AllowPrivateNetworks && strings.EqualFold(strings.TrimSuffix(host, "."), "localhost")) {
+		return NewValidationError(FieldError{Field: "url", Message: "must not be a loopback, private or link-local address"})
+	}
+
+	return nil
+}
+
+// AllowsAddress tells if a webhook may connect to addr
+func (p WebhookPolicy) AllowsAddress(addr netip.Addr) bool {
+	if p.AllowPrivateNetworks {
+		return true
+	}
+
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// nonPublicPrefixes are the ranges not routed on the internet which are left
+// out of IsPrivate: "this network" and the carrier-grade NAT of RFC 6598
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// NewWebhook subscribes url to events with a new signing secret, url must be
+// allowed by policy
+func NewWebhook(policy WebhookPolicy, organisationID string, estateUUID *string, webhookURL string, events EventTypes) (*Webhook, error) {
+	if err := policy.CheckURL(webhookURL); err != nil {
+		return nil, err
+	}
+
+	if len(events) == 0 {
+		return nil, NewValidationError(FieldError{Field: "events", Message: "must not be empty"})
+	}
+
+	for _, eventType := range events {
+		if !eventType.IsKnown() {
+			return nil, NewValidationError(FieldError{Field: "events", Message: fmt.Sprintf("has the unknown event type %q", eventType)})
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	return &Webhook{
+		UUID:           uuid.NewString(),
+		OrganisationID: organisationID,
+		EstateUUID:     estateUUID,
+		URL:            webhookURL,
+		Secret:         hex.EncodeToString(secret),
+		Events:         events,
+		CreatedAt:      time.Now(),
+	}, nil
+}
+
+// Receives tells if event must be delivered to the webhook
+func (w *Webhook) Receives(event *Event) bool {
+	if w.OrganisationID != event.OrganisationID || !w.Events.Contains(event.Type) {
+		return false
+	}
+
+	return w.EstateUUID == nil || *w.EstateUUID == event.EstateUUID
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryAbandoned DeliveryStatus = "abandoned"
+)
+
+// WebhookDelivery is an event to send to a webhook, the pending deliveries
+// are the outbox of the webhooks. The last attempt is kept for the deliveries
+// log.
+type WebhookDelivery struct {
+	bun.BaseModel `bun:"table:webhook_deliveries"`
+
+	ID             uint64         `bun:"id,pk"`
+	UUID           string         `bun:"uuid,notnull"`
+	WebhookID      uint64         `bun:"webhook_id,notnull"`
+	EventUUID      string         `bun:"event_uuid,notnull"`
+	EventType      EventType      `bun:"event_type,notnull"`
+	Payload        string         `bun:"payload,notnull"`
+	Status         DeliveryStatus `bun:"status,notnull"`
+	Attempts       int            `bun:"attempts,notnull"`
+	NextAttemptAt  time.Time      `bun:"next_attempt_at,notnull"`
+	LastStatusCode int            `bun:"last_status_code,nullzero"`
+	LastError      string         `bun:"last_error,nullzero"`
+	DeliveredAt    *time.Time     `bun:"delivered_at,nullzero"`
+	CreatedAt      time.Time      `bun:"created_at"`
+	UpdatedAt      time.Time      `bun:"updated_at"`
+
+	Webhook *Webhook `bun:"rel:belongs-to,join:webhook_id=id"`
+}
+
+// NewWebhookDelivery is the delivery of event to webhook, due right away
+func NewWebhookDelivery(webhook *Webhook, event *Event) *WebhookDelivery {
+	now := time.Now()
+
+	return &WebhookDelivery{
+		UUID:          uuid.NewString(),
+		WebhookID:     webhook.ID,
+		EventUUID:     event.UUID,
+		EventType:     event.Type,
+		Payload:       event.Payload,
+		Status:        DeliveryPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Webhook:       webhook,
+	}
+}
+
+// Succeed records an attempt answered with the 2xx statusCode
+func (d *WebhookDelivery) Succeed(statusCode int, at time.Time) {
+	d.Attempts++
+	d.Status = DeliveryDelivered
+	d.LastStatusCode = statusCode
+	d.LastError = ""
+	d.DeliveredAt = &at
+	d.UpdatedAt = at
+}
+
+// Fail records a failed attempt, statusCode is zero when the webhook did not
+// answer. The delivery is attempted again at retryAt, or given up when nil.
+func (d *WebhookDelivery) Fail(statusCode int, reason string, at time.Time, retryAt *time.Time) {
+	d.Attempts++
+	d.LastStatusCode = statusCode
+	d.LastError = reason
+	d.UpdatedAt = at
+
+	if retryAt == nil {
+		d.Status = DeliveryAbandoned
+		return
+	}
+
+	d.NextAttemptAt = *retryAt
+}
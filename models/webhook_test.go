@@ -0,0 +1,136 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWebhook(t *testing.T) {
+	estateUUID := "6f8b1c52-8d4b-4c8e-9a51-0c3c3f1bb0a1"
+	webhook, err := NewWebhook(WebhookPolicy{}, "estate-co-a", &estateUUID, "https://example.com/hooks", EventTypes{EventTreeAdded})
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, webhook.UUID)
+	assert.Len(t, webhook.Secret, 64)
+
+	other, err := NewWebhook(WebhookPolicy{AllowHTTP: true}, "estate-co-a", nil, "http://example.com/hooks", EventTypes{EventTreeAdded})
+	require.NoError(t, err)
+	assert.NotEqual(t, webhook.Secret, other.Secret)
+
+	for _, invalid := range []string{"", "example.com/hooks", "ftp://example.com", "https://"} {
+		_, err := NewWebhook(WebhookPolicy{}, "estate-co-a", nil, invalid, EventTypes{EventTreeAdded})
+		assert.True(t, errors.Is(err, &Error{Code: CodeValidation}), invalid)
+	}
+
+	_, err = NewWebhook(WebhookPolicy{}, "estate-co-a", nil, "https://example.com/hooks", nil)
+	assert.ErrorContains(t, err, "events must not be empty")
+
+	_, err = NewWebhook(WebhookPolicy{}, "estate-co-a", nil, "https://example.com/hooks", EventTypes{EventTreeAdded, "tree.planted"})
+	assert.ErrorContains(t, err, `events has the unknown event type "tree.planted"`)
+}
+
+func TestWebhookPolicy_CheckURL(t *testing.T) {
+	testcases := map[string]struct {
+		url     string
+		policy  WebhookPolicy
+		allowed bool
+	}{
+		"Https":                  {url: "https://example.com/hooks", allowed: true},
+		"PublicAddress":          {url: "https://203.0.113.7/hooks", allowed: true},
+		"Http":                   {url: "http://example.com/hooks"},
+		"HttpAllowed":            {url: "http://example.com/hooks", policy: WebhookPolicy{AllowHTTP: true}, allowed: true},
+		"Localhost":              {url: "https://localhost:8080/hooks"},
+		"Loopback":               {url: "https://127.0.0.1/hooks"},
+		"LoopbackV6":             {url: "https://[::1]/hooks"},
+		"MappedLoopback":         {url: "https://[::ffff:127.0.0.1]/hooks"},
+		"Private":                {url: "https://10.1.2.3/hooks"},
+		"PrivateV6":              {url: "https://[fd00::1]/hooks"},
+		"Metadata":               {url: "https://169.254.169.254/latest/meta-data"},
+		"SharedAddressSpace":     {url: "https://100.64.1.1/hooks"},
+		"Unspecified":            {url: "https://0.0.0.0/hooks"},
+		"PrivateNetworksAllowed": {url: "https://10.1.2.3/hooks", policy: WebhookPolicy{AllowPrivateNetworks: true}, allowed: true},
+	}
+
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			err := tc.policy.CheckURL(tc.url)
+			if tc.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, &Error{Code: CodeValidation}), err)
+			}
+		})
+	}
+}
+
+func TestWebhook_Receives(t *testing.T) {
+	estateUUID := "6f8b1c52-8d4b-4c8e-9a51-0c3c3f1bb0a1"
+	event := &Event{Type: EventTreeAdded, OrganisationID: "estate-co-a", EstateUUID: estateUUID}
+
+	organisationWide := &Webhook{OrganisationID: "estate-co-a", Events: EventTypes{EventTreeAdded, EventTreeRetired}}
+	assert.True(t, organisationWide.Receives(event))
+
+	oneEstate := &Webhook{OrganisationID: "estate-co-a", EstateUUID: &estateUUID, Events: EventTypes{EventTreeAdded}}
+	assert.True(t, oneEstate.Receives(event))
+
+	otherUUID := "00000000-0000-0000-0000-000000000000"
+	otherEstate := &Webhook{OrganisationID: "estate-co-a", EstateUUID: &otherUUID, Events: EventTypes{EventTreeAdded}}
+	assert.False(t, otherEstate.Receives(event))
+
+	otherOrganisation := &Webhook{OrganisationID: "estate-co-b", Events: EventTypes{EventTreeAdded}}
+	assert.False(t, otherOrganisation.Receives(event))
+
+	otherEvents := &Webhook{OrganisationID: "estate-co-a", Events: EventTypes{EventEstateStatsChanged}}
+	assert.False(t, otherEvents.Receives(event))
+}
+
+func TestEventType_IsChange(t *testing.T) {
+	assert.True(t, EventEstateCreated.IsChange())
+	assert.True(t, EventTreeRetired.IsChange())
+	assert.False(t, EventDronePlanComputed.IsChange())
+	assert.False(t, EventType("tree.planted").IsChange())
+}
+
+func TestEventTypes_ValueScan(t *testing.T) {
+	value, err := EventTypes{EventTreeAdded, EventTreeRetired}.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "tree.added,tree.retired", value)
+
+	var scanned EventTypes
+	require.NoError(t, scanned.Scan([]byte("tree.added,tree.retired")))
+	assert.Equal(t, EventTypes{EventTreeAdded, EventTreeRetired}, scanned)
+
+	require.NoError(t, scanned.Scan(""))
+	assert.Empty(t, scanned)
+
+	assert.Error(t, scanned.Scan(42))
+}
+
+func TestWebhookDelivery_Attempts(t *testing.T) {
+	webhook := &Webhook{ID: 3}
+	delivery := NewWebhookDelivery(webhook, &Event{UUID: "event", Type: EventTreeAdded, Payload: "{}"})
+	assert.Equal(t, DeliveryPending, delivery.Status)
+	assert.Equal(t, uint64(3), delivery.WebhookID)
+
+	now := time.Now()
+	retryAt := now.Add(time.Minute)
+	delivery.Fail(500, "unexpected status 500", now, &retryAt)
+	assert.Equal(t, DeliveryPending, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, retryAt, delivery.NextAttemptAt)
+
+	delivery.Succeed(204, retryAt)
+	assert.Equal(t, DeliveryDelivered, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Empty(t, delivery.LastError)
+	assert.Equal(t, &retryAt, delivery.DeliveredAt)
+
+	failing := NewWebhookDelivery(webhook, &Event{})
+	failing.Fail(0, "connection refused", now, nil)
+	assert.Equal(t, DeliveryAbandoned, failing.Status)
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"errors"
+	"sort"
+)
+
+// YieldBand maps a range of tree heights to the expected yield of a single
+// tree. Oil palm trunks grow steadily with age, so the height of a tree is
+// used as a proxy for its age and therefore its productivity.
+type YieldBand struct {
+	Name          string  `yaml:"name"`
+	MinHeight     uint8   `yaml:"min_height"`
+	MaxHeight     uint8   `yaml:"max_height"`
+	TonnesPerTree float64 `yaml:"tonnes_per_tree"`
+}
+
+// YieldCurve is a set of non overlapping height bands, expressed in tonnes
+// of fresh fruit bunches (FFB) per tree per year.
+type YieldCurve []YieldBand
+
+type YieldBandEstimate struct {
+	YieldBand
+	TreeCount     int
+	TonnesPerYear float64
+}
+
+type YieldEstimate struct {
+	TreeCount     int
+	TonnesPerYear float64
+	Bands         []YieldBandEstimate
+}
+
+// DefaultYieldCurve returns a typical oil palm yield curve, peaking at around
+// 0.2 tonnes FFB per tree per year during the prime producing years.
+func DefaultYieldCurve() YieldCurve {
+	return YieldCurve{
+		{Name: "immature", MinHeight: 1, MaxHeight: 2, TonnesPerTree: 0},
+		{Name: "young", MinHeight: 3, MaxHeight: 5, TonnesPerTree: 0.08},
+		{Name: "prime", MinHeight: 6, MaxHeight: 12, TonnesPerTree: 0.2},
+		{Name: "mature", MinHeight: 13, MaxHeight: 20, TonnesPerTree: 0.16},
+		{Name: "old", MinHeight: 21, MaxHeight: 30, TonnesPerTree: 0.1},
+	}
+}
+
+func (c YieldCurve) Validate() (err error) {
+	if len(c) == 0 {
+		err = errors.New("yield curve must have at least one band")
+		return
+	}
+
+	bands := make(YieldCurve, len(c))
+	copy(bands, c)
+	sort.Slice(bands, func(i, j int) bool {
+		return bands[i].MinHeight < bands[j].MinHeight
+	})
+
+	for i, band := range bands {
+		if band.MinHeight > band.MaxHeight {
+			err = errors.New("yield band minimum height is higher than maximum height")
+			return
+		}
+
+		if band.TonnesPerTree < 0 {
+			err = errors.New("yield band tonnes per tree cannot be negative")
+			return
+		}
+
+		if i > 0 && band.MinHeight <= bands[i-1].MaxHeight {
+			err = errors.New("yield bands are overlapping")
+			return
+		}
+	}
+
+	return
+}
+
+// EstimateYield distributes the trees into the curve bands and sums up the
+// expected yield. Trees with a height outside of every band produce nothing.
+func (c YieldCurve) EstimateYield(trees *[]Tree) YieldEstimate {
+	estimate := YieldEstimate{
+		TreeCount: len(*trees),
+		Bands:     make([]YieldBandEstimate, len(c)),
+	}
+
+	for i, band := range c {
+		estimate.Bands[i].YieldBand = band
+	}
+
+	for _, tree := range *trees {
+		for i, band := range c {
+			if tree.Height >= band.MinHeight && tree.Height <= band.MaxHeight {
+				estimate.Bands[i].TreeCount++
+				break
+			}
+		}
+	}
+
+	for i := range estimate.Bands {
+		band := &estimate.Bands[i]
+		band.TonnesPerYear = float64(band.TreeCount) * band.TonnesPerTree
+		estimate.TonnesPerYear += band.TonnesPerYear
+	}
+
+	return estimate
+}
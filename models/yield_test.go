@@ -0,0 +1,60 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultYieldCurve(t *testing.T) {
+	assert.NoError(t, DefaultYieldCurve().Validate())
+}
+
+func TestYieldCurveValidate(t *testing.T) {
+	assert.Error(t, YieldCurve{}.Validate())
+
+	assert.Error(t, YieldCurve{
+		{Name: "invalid", MinHeight: 10, MaxHeight: 5, TonnesPerTree: 0.1},
+	}.Validate())
+
+	assert.Error(t, YieldCurve{
+		{Name: "negative", MinHeight: 1, MaxHeight: 5, TonnesPerTree: -0.1},
+	}.Validate())
+
+	err := YieldCurve{
+		{Name: "young", MinHeight: 1, MaxHeight: 10, TonnesPerTree: 0.1},
+		{Name: "old", MinHeight: 10, MaxHeight: 30, TonnesPerTree: 0.2},
+	}.Validate()
+	assert.Error(t, err)
+	assert.Equal(t, "yield bands are overlapping", err.Error())
+}
+
+func TestEstimateYield(t *testing.T) {
+	curve := YieldCurve{
+		{Name: "young", MinHeight: 1, MaxHeight: 5, TonnesPerTree: 0.1},
+		{Name: "prime", MinHeight: 6, MaxHeight: 20, TonnesPerTree: 0.25},
+	}
+
+	trees := []Tree{
+		{Height: 2},
+		{Height: 5},
+		{Height: 6},
+		{Height: 20},
+		{Height: 25},
+	}
+
+	estimate := curve.EstimateYield(&trees)
+	assert.Equal(t, 5, estimate.TreeCount)
+	assert.Len(t, estimate.Bands, 2)
+	assert.Equal(t, 2, estimate.Bands[0].TreeCount)
+	assert.InDelta(t, 0.2, estimate.Bands[0].TonnesPerYear, 0.0001)
+	assert.Equal(t, 2, estimate.Bands[1].TreeCount)
+	assert.InDelta(t, 0.5, estimate.Bands[1].TonnesPerYear, 0.0001)
+	assert.InDelta(t, 0.7, estimate.TonnesPerYear, 0.0001)
+}
+
+func TestEstimateYield_NoTrees(t *testing.T) {
+	estimate := DefaultYieldCurve().EstimateYield(&[]Tree{})
+	assert.Equal(t, 0, estimate.TreeCount)
+	assert.Equal(t, float64(0), estimate.TonnesPerYear)
+}
@@ -0,0 +1,176 @@
+// This file contains the publishers of the outbox, the sinks the relay
+// streams the changes of the estates to, such as a data warehouse. A record
+// is published at least once, and the records of an estate in the order of
+// the changes.
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/models"
+)
+
+// Record is a line of the stream. EventID is the id of the event in the
+// outbox, a record published again keeps it. It is not a sequence: it has
+// gaps, and the sink must not order the records on it.
+type Record struct {
+	EventID        uint64 `json:"event_id"`
+	OrganisationID string `json:"organisation_id"`
+	// Event is the WebhookEvent of api.yml, the body sent to the webhooks
+	Event json.RawMessage `json:"event"`
+}
+
+func NewRecord(event *models.Event) Record {
+	return Record{
+		EventID:        event.ID,
+		OrganisationID: event.OrganisationID,
+		Event:          json.RawMessage(event.Payload),
+	}
+}
+
+// Publisher sends the records to a sink
+type Publisher interface {
+	// Publish sends the records in their order. It fails unless every
+	// record was sent, they are then all published again.
+	Publish(ctx context.Context, records []Record) error
+	Close() error
+}
+
+// encode writes the records as newline delimited JSON
+func encode(records []Record) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// FilePublisher appends the records to a file, one JSON per line
+type FilePublisher struct {
+	file *os.File
+}
+
+// NewFilePublisher appends to the file at path, created when missing
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FilePublisher{file: file}, nil
+}
+
+// Publish writes the records and syncs the file, the lines written by a
+// failed attempt are truncated so the file only holds whole records
+func (p *FilePublisher) Publish(ctx context.Context, records []Record) error {
+	lines, err := encode(records)
+	if err != nil {
+		return err
+	}
+
+	info, err := p.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.file.Write(lines); err != nil {
+		return errors.Join(err, p.file.Truncate(info.Size()))
+	}
+
+	if err := p.file.Sync(); err != nil {
+		return errors.Join(err, p.file.Truncate(info.Size()))
+	}
+
+	return nil
+}
+
+func (p *FilePublisher) Close() error {
+	return p.file.Close()
+}
+
+// HTTPPublisher POSTs the records to a URL as newline delimited JSON, a batch
+// per request
+type HTTPPublisher struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+type NewHTTPPublisherOptions struct {
+	URL string
+	// Token is sent as a bearer token when set
+	Token string
+	// Client sends the batches, when nil one with Timeout
+	Client *http.Client
+	// Timeout bounds a request, 10 seconds by default
+	Timeout time.Duration
+}
+
+func NewHTTPPublisher(opts NewHTTPPublisherOptions) *HTTPPublisher {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: opts.Timeout}
+	}
+
+	return &HTTPPublisher{
+		url:    opts.URL,
+		token:  opts.Token,
+		client: client,
+	}
+}
+
+// Publish succeeds once the sink answers a 2xx, the sink must then have
+// stored every record of the batch
+func (p *HTTPPublisher) Publish(ctx context.Context, records []Record) error {
+	body, err := encode(records)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("User-Agent", "sawit-outbox/1")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// The connection is reused once the body is read
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
+
+func (p *HTTPPublisher) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,82 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRecord(t *testing.T) {
+	record := NewRecord(&models.Event{
+		ID:             7,
+		OrganisationID: "estate-co-a",
+		Payload:        `{"id":"event","type":"tree.added"}`,
+	})
+
+	line, err := json.Marshal(record)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"event_id":7,"organisation_id":"estate-co-a","event":{"id":"event","type":"tree.added"}}`, string(line))
+}
+
+func TestFilePublisher(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+
+	publisher, err := NewFilePublisher(path)
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(ctx, []Record{{EventID: 1, Event: json.RawMessage(`{}`)}, {EventID: 2, Event: json.RawMessage(`{}`)}}))
+	require.NoError(t, publisher.Close())
+
+	// Appended to, after a restart
+	publisher, err = NewFilePublisher(path)
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(ctx, []Record{{EventID: 3, Event: json.RawMessage(`{}`)}}))
+	require.NoError(t, publisher.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"event_id":1,"organisation_id":"","event":{}}
+{"event_id":2,"organisation_id":"","event":{}}
+{"event_id":3,"organisation_id":"","event":{}}
+`, string(content))
+
+	_, err = NewFilePublisher(filepath.Join(t.TempDir(), "missing", "events.ndjson"))
+	assert.Error(t, err)
+}
+
+func TestHTTPPublisher(t *testing.T) {
+	ctx := context.Background()
+
+	status := http.StatusAccepted
+	var req *http.Request
+	var body []byte
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer sink.Close()
+
+	publisher := NewHTTPPublisher(NewHTTPPublisherOptions{URL: sink.URL + "/events", Token: "secret"})
+	defer publisher.Close()
+
+	records := []Record{{EventID: 1, Event: json.RawMessage(`{}`)}, {EventID: 2, Event: json.RawMessage(`{}`)}}
+	require.NoError(t, publisher.Publish(ctx, records))
+	assert.Equal(t, http.MethodPost, req.Method)
+	assert.Equal(t, "/events", req.URL.Path)
+	assert.Equal(t, "application/x-ndjson", req.Header.Get("Content-Type"))
+	assert.Equal(t, "Bearer secret", req.Header.Get("Authorization"))
+	assert.Equal(t, "{\"event_id\":1,\"organisation_id\":\"\",\"event\":{}}\n{\"event_id\":2,\"organisation_id\":\"\",\"event\":{}}\n", string(body))
+
+	status = http.StatusInternalServerError
+	assert.EqualError(t, publisher.Publish(ctx, records), "unexpected status 500 Internal Server Error")
+}
@@ -0,0 +1,215 @@
+// This file contains the relay of the outbox. The changes are written to the
+// outbox in their transaction, the relay publishes the oldest ones in order
+// and deletes them once published.
+//
+// A single relay publishes at a time, the one holding the lease of the
+// outbox, so the records of an estate are not reordered by the relays of two
+// servers. A batch failing to be published is retried until it succeeds and
+// holds the next ones back. The records published before a crash, but not yet
+// deleted, are published again.
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/metrics"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/google/uuid"
+)
+
+type Relay struct {
+	repo       repository.RepositoryInterface
+	publisher  Publisher
+	logger     *slog.Logger
+	metrics    *metrics.Metrics
+	interval   time.Duration
+	batchSize  int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	leaseTTL   time.Duration
+	// holder names the relay in the lease of the outbox
+	holder string
+
+	// The state below is only used by PublishBatch, which is not called
+	// concurrently
+	hasLease bool
+	failures int
+	retryAt  time.Time
+
+	// now is replaced by the tests to run the retries without waiting
+	now func() time.Time
+}
+
+type NewRelayOptions struct {
+	Repository repository.RepositoryInterface
+	Publisher  Publisher
+	// Logger is slog.Default() when nil
+	Logger *slog.Logger
+	// Metrics counts the events published, they are not counted when nil
+	Metrics *metrics.Metrics
+	// PollInterval is how often the outbox is read, 1 second by default
+	PollInterval time.Duration
+	// BatchSize is the number of events published together, 100 by default
+	BatchSize int
+	// MinBackoff is the wait after a failed batch, doubled after every
+	// failure up to MaxBackoff. 1 second and 1 minute by default.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// LeaseTTL is how long the relay keeps the outbox without renewing its
+	// lease, 30 seconds by default. A batch is given half of it.
+	LeaseTTL time.Duration
+}
+
+func NewRelay(opts NewRelayOptions) *Relay {
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+
+	if opts.BatchSize < 1 {
+		opts.BatchSize = 100
+	}
+
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = time.Second
+	}
+
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = time.Minute
+	}
+
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = opts.MinBackoff
+	}
+
+	if opts.LeaseTTL <= 0 {
+		opts.LeaseTTL = 30 * time.Second
+	}
+
+	return &Relay{
+		repo:       opts.Repository,
+		publisher:  opts.Publisher,
+		logger:     logger,
+		metrics:    opts.Metrics,
+		interval:   opts.PollInterval,
+		batchSize:  opts.BatchSize,
+		minBackoff: opts.MinBackoff,
+		maxBackoff: opts.MaxBackoff,
+		leaseTTL:   opts.LeaseTTL,
+		holder:     uuid.NewString(),
+		now:        time.Now,
+	}
+}
+
+// Run publishes the outbox every poll interval until ctx is done
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		// A full batch may leave more events behind
+		for ctx.Err() == nil {
+			published, err := r.PublishBatch(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					r.logger.Error("publishing the outbox", "error", err)
+				}
+				break
+			}
+
+			if published < r.batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PublishBatch publishes the oldest events of the outbox and deletes them, it
+// answers how many were published. Nothing is published without the lease,
+// nor before the backoff of the last failure ends.
+func (r *Relay) PublishBatch(ctx context.Context) (int, error) {
+	now := r.now()
+	if now.Before(r.retryAt) {
+		return 0, nil
+	}
+
+	acquired, err := r.repo.AcquireOutboxLease(ctx, r.holder, now, r.leaseTTL)
+	if err != nil {
+		return 0, err
+	}
+
+	if acquired != r.hasLease {
+		r.hasLease = acquired
+		r.logger.Info("outbox lease changed", "holder", r.holder, "acquired", acquired)
+	}
+
+	if !acquired {
+		return 0, nil
+	}
+
+	events, err := r.repo.GetOutboxEvents(ctx, r.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(*events) == 0 {
+		return 0, nil
+	}
+
+	records := make([]Record, 0, len(*events))
+	ids := make([]uint64, 0, len(*events))
+	for i := range *events {
+		records = append(records, NewRecord(&(*events)[i]))
+		ids = append(ids, (*events)[i].ID)
+	}
+
+	// The batch ends before the lease, another relay then takes over from
+	// the same events at the earliest
+	publishCtx, cancel := context.WithTimeout(ctx, r.leaseTTL/2)
+	err = r.publisher.Publish(publishCtx, records)
+	cancel()
+	if err != nil {
+		r.metrics.ObserveOutboxEvents("failed", len(records))
+		r.failures++
+		r.retryAt = r.now().Add(r.backoff(r.failures))
+		return 0, err
+	}
+
+	r.metrics.ObserveOutboxEvents("published", len(records))
+	r.failures = 0
+	r.retryAt = time.Time{}
+
+	// Published again by the next batch when not deleted
+	if err := r.repo.DeleteOutboxEvents(context.WithoutCancel(ctx), ids); err != nil {
+		return 0, err
+	}
+
+	return len(records), nil
+}
+
+// backoff is the wait after the failures in a row, doubled after every
+// failure
+func (r *Relay) backoff(failures int) time.Duration {
+	backoff := r.minBackoff
+	for i := 1; i < failures && backoff < r.maxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > r.maxBackoff {
+		backoff = r.maxBackoff
+	}
+
+	return backoff
+}
@@ -0,0 +1,166 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/logging"
+	"github.com/SawitProRecruitment/UserService/models"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a publisher keeping the records, failing while err is set
+type recorder struct {
+	records []Record
+	err     error
+}
+
+func (p *recorder) Publish(ctx context.Context, records []Record) error {
+	if p.err != nil {
+		return p.err
+	}
+
+	p.records = append(p.records, records...)
+	return nil
+}
+
+func (p *recorder) Close() error {
+	return nil
+}
+
+// saveChanges saves a tree.added event per estate, in that order
+func saveChanges(t *testing.T, repo repository.RepositoryInterface, estateUUIDs ...string) {
+	for _, estateUUID := range estateUUIDs {
+		require.NoError(t, repo.SaveEvents(context.Background(), []models.Event{{
+			UUID:           uuid.NewString(),
+			Type:           models.EventTreeAdded,
+			OrganisationID: "estate-co-a",
+			EstateUUID:     estateUUID,
+			Payload:        `{"estate_id":"` + estateUUID + `"}`,
+		}}))
+	}
+}
+
+func TestRelay_Publish(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	saveChanges(t, repo, "estate-a", "estate-b", "estate-a")
+
+	publisher := &recorder{}
+	relay := NewRelay(NewRelayOptions{Repository: repo, Publisher: publisher, Logger: logging.Discard(), BatchSize: 2})
+
+	published, err := relay.PublishBatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, published)
+
+	published, err = relay.PublishBatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+
+	require.Len(t, publisher.records, 3)
+	for i, estateUUID := range []string{"estate-a", "estate-b", "estate-a"} {
+		assert.Equal(t, uint64(i+1), publisher.records[i].EventID)
+		assert.JSONEq(t, `{"estate_id":"`+estateUUID+`"}`, string(publisher.records[i].Event))
+	}
+
+	// The outbox is empty once published
+	events, err := repo.GetOutboxEvents(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, *events)
+}
+
+func TestRelay_Retry(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	saveChanges(t, repo, "estate-a", "estate-a")
+
+	now := time.Now()
+	publisher := &recorder{err: errors.New("sink unavailable")}
+	relay := NewRelay(NewRelayOptions{Repository: repo, Publisher: publisher, Logger: logging.Discard(), MinBackoff: time.Minute})
+	relay.now = func() time.Time { return now }
+
+	_, err := relay.PublishBatch(ctx)
+	assert.EqualError(t, err, "sink unavailable")
+
+	// Nothing is lost, nor sent again before the backoff
+	publisher.err = nil
+	published, err := relay.PublishBatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, published)
+
+	now = now.Add(time.Minute)
+	published, err = relay.PublishBatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, published)
+	assert.Equal(t, uint64(1), publisher.records[0].EventID)
+	assert.Equal(t, uint64(2), publisher.records[1].EventID)
+}
+
+func TestRelay_Backoff(t *testing.T) {
+	relay := NewRelay(NewRelayOptions{MinBackoff: time.Second, MaxBackoff: 5 * time.Second})
+
+	assert.Equal(t, time.Second, relay.backoff(1))
+	assert.Equal(t, 2*time.Second, relay.backoff(2))
+	assert.Equal(t, 4*time.Second, relay.backoff(3))
+	assert.Equal(t, 5*time.Second, relay.backoff(4))
+}
+
+func TestRelay_Lease(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	saveChanges(t, repo, "estate-a")
+
+	now := time.Now()
+	first := &recorder{err: errors.New("sink unavailable")}
+	second := &recorder{}
+	relays := []*Relay{
+		NewRelay(NewRelayOptions{Repository: repo, Publisher: first, Logger: logging.Discard(), LeaseTTL: time.Minute}),
+		NewRelay(NewRelayOptions{Repository: repo, Publisher: second, Logger: logging.Discard(), LeaseTTL: time.Minute}),
+	}
+	for _, relay := range relays {
+		relay.now = func() time.Time { return now }
+	}
+
+	_, err := relays[0].PublishBatch(ctx)
+	assert.Error(t, err)
+
+	// The first relay keeps the outbox while its lease lasts
+	published, err := relays[1].PublishBatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, published)
+
+	now = now.Add(2 * time.Minute)
+	published, err = relays[1].PublishBatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+	assert.Len(t, second.records, 1)
+}
+
+func TestRelay_Run(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	saveChanges(t, repo, "estate-a", "estate-b")
+
+	publisher := &recorder{}
+	relay := NewRelay(NewRelayOptions{Repository: repo, Publisher: publisher, Logger: logging.Discard(), PollInterval: time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		relay.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		events, err := repo.GetOutboxEvents(context.Background(), 10)
+		return err == nil && len(*events) == 0
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	<-done
+	assert.Len(t, publisher.records, 2)
+}
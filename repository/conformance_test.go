@@ -0,0 +1,608 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testConformance checks the behaviour every RepositoryInterface
+// implementation must share, newRepo is called once per case.
+func testConformance(t *testing.T, newRepo func(t *testing.T) RepositoryInterface) {
+	t.Run("GetMissing", func(t *testing.T) {
+		testConformanceGetMissing(t, newRepo(t))
+	})
+	t.Run("LargeEstate", func(t *testing.T) {
+		testConformanceLargeEstate(t, newRepo(t))
+	})
+	t.Run("Organisation", func(t *testing.T) {
+		testConformanceOrganisation(t, newRepo(t))
+	})
+	t.Run("SaveTree", func(t *testing.T) {
+		testConformanceSaveTree(t, newRepo(t))
+	})
+	t.Run("VersionConflict", func(t *testing.T) {
+		testConformanceVersionConflict(t, newRepo(t))
+	})
+	t.Run("Commit", func(t *testing.T) {
+		testConformanceCommit(t, newRepo(t))
+	})
+	t.Run("Rollback", func(t *testing.T) {
+		testConformanceRollback(t, newRepo(t))
+	})
+	t.Run("RetireAndHistory", func(t *testing.T) {
+		testConformanceRetireAndHistory(t, newRepo(t))
+	})
+	t.Run("Totals", func(t *testing.T) {
+		testConformanceTotals(t, newRepo(t))
+	})
+	t.Run("Ping", func(t *testing.T) {
+		testConformancePing(t, newRepo(t))
+	})
+	t.Run("Concurrent", func(t *testing.T) {
+		testConformanceConcurrent(t, newRepo(t))
+	})
+	t.Run("Webhooks", func(t *testing.T) {
+		testConformanceWebhooks(t, newRepo(t))
+	})
+	t.Run("WebhookDeliveries", func(t *testing.T) {
+		testConformanceWebhookDeliveries(t, newRepo(t))
+	})
+	t.Run("Outbox", func(t *testing.T) {
+		testConformanceOutbox(t, newRepo(t))
+	})
+	t.Run("OutboxLease", func(t *testing.T) {
+		testConformanceOutboxLease(t, newRepo(t))
+	})
+}
+
+func newConformanceEstate(t *testing.T, repo RepositoryInterface) *models.Estate {
+	ctx := context.Background()
+
+	estate, err := models.NewEstate(10, 10)
+	require.NoError(t, err)
+	require.NoError(t, repo.SaveEstate(ctx, estate))
+
+	estate, err = repo.GetEstate(ctx, estate.UUID)
+	require.NoError(t, err)
+	require.NotNil(t, estate)
+
+	return estate
+}
+
+func testConformanceGetMissing(t *testing.T, repo RepositoryInterface) {
+	ctx := context.Background()
+	estate := newConformanceEstate(t, repo)
+
+	missing, err := repo.GetEstate(ctx, "00000000-0000-0000-0000-000000000000")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	tree, err := repo.GetTree(ctx, estate.ID, "00000000-0000-0000-0000-000000000000")
+	assert.NoError(t, err)
+	assert.Nil(t, tree)
+
+	tree, err = repo.GetTreeByCoordinate(ctx, estate.ID, 1, 1)
+	assert.NoError(t, err)
+	assert.Nil(t, tree)
+
+	trees, err := repo.GetTreesByEstate(ctx, estate.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, trees) {
+		assert.Empty(t, *trees)
+	}
+
+	history, err := repo.GetTreeHistoryByCoordinate(ctx, estate.ID, 1, 1)
+	assert.NoError(t, err)
+	if assert.NotNil(t, history) {
+		assert.Empty(t, *history)
+	}
+}
+
+func testConformanceLargeEstate(t *testing.T, repo RepositoryInterface) {
+	ctx := context.Background()
+
+	estate, err := models.NewEstate(50000, 50000)
+	require.NoError(t, err)
+	estate.TreeCount = 2500000000
+	require.NoError(t, repo.SaveEstate(ctx, estate))
+
+	saved, err := repo.GetEstate(ctx, estate.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(50000), saved.Width)
+	assert.Equal(t, uint16(50000), saved.Length)
+	assert.Equal(t, uint32(2500000000), saved.TreeCount)
+}
+
+func testConformanceOrganisation(t *testing.T, repo RepositoryInterface) {
+	ctx := context.Background()
+
+	estate, err := models.NewEstate(10, 10)
+	require.NoError(t, err)
+	estate.OrganisationID = "estate-co-a"
+	require.NoError(t, repo.SaveEstate(ctx, estate))
+
+	saved, err := repo.GetEstate(ctx, estate.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, "estate-co-a", saved.OrganisationID)
+
+	// Saving a tree updates the estate, it must stay in its organisation
+	tree, err := models.NewTree(saved, 1, 1, 10)
+	require.NoError(t, err)
+	require.NoError(t, repo.SaveTree(ctx, tree))
+
+	saved, err = repo.GetEstate(ctx, estate.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, "estate-co-a", saved.OrganisationID)
+}
+
+func testConformanceSaveTree(t *testing.T, repo RepositoryInterface) {
+	ctx := context.Background()
+	estate := newConformanceEstate(t, repo)
+
+	tree, err := models.NewTree(estate, 1, 1, 10)
+	require.NoError(t, err)
+	require.NoError(t, repo.SaveTree(ctx, tree))
+
+	saved, err := repo.GetTreeByCoordinate(ctx, estate.ID, 1, 1)
+	assert.NoError(t, err)
+	if assert.NotNil(t, saved) {
+		assert.Equal(t, tree.UUID, saved.UUID)
+		assert.Equal(t, uint8(10), saved.Height)
+	}
+
+	trees, err := repo.GetTreesByEstate(ctx, estate.ID)
+	assert.NoError(t, err)
+	assert.Len(t, *trees, 1)
+
+	savedEstate, err := repo.GetEstate(ctx, estate.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), savedEstate.TreeCount)
+	assert.Equal(t, uint64(1), savedEstate.Version)
+
+	// Same plot with an up to date estate
+	duplicate, err := models.NewTree(savedEstate, 1, 1, 20)
+	require.NoError(t, err)
+	assert.ErrorIs(t, repo.SaveTree(ctx, duplicate), ErrDuplicateTreeLocation)
+
+	savedEstate, err = repo.GetEstate(ctx, estate.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), savedEstate.TreeCount)
+	assert.Equal(t, uint64(1), savedEstate.Version)
+}
+
+func testConformanceVersionConflict(t *testing.T, repo RepositoryInterface) {
+	ctx := context.Background()
+	estate := newConformanceEstate(t, repo)
+	stale := *estate
+
+	tree, err := models.NewTree(estate, 1, 1, 10)
+	require.NoError(t, err)
+	require.NoError(t, repo.SaveTree(ctx, tree))
+
+	staleTree, err := models.NewTree(&stale, 2, 1, 20)
+	require.NoError(t, err)
+	assert.ErrorIs(t, repo.SaveTree(ctx, staleTree), ErrVersionConflict)
+	assert.Equal(t, uint64(0), stale.Version)
+
+	saved, err := repo.GetTreeByCoordinate(ctx, estate.ID, 2, 1)
+	assert.NoError(t, err)
+	assert.Nil(t, saved)
+}
+
+func testConformanceCommit(t *testing.T, repo RepositoryInterface) {
+	ctx := context.Background()
+	estate := newConformanceEstate(t, repo)
+
+	err := repo.RunInTx(ctx, func(tx RepositoryInterface) error {
+		tree, err := models.NewTree(estate, 1, 1, 10)
+		if err != nil {
+			return err
+		}
+
+		return tx.SaveTree(ctx, tree)
+	})
+	require.NoError(t, err)
+
+	tree, err := repo.GetTreeByCoordinate(ctx, estate.ID, 1, 1)
+	assert.NoError(t, err)
+	assert.NotNil(t, tree)
+
+	saved, err := repo.GetEstate(ctx, estate.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1), saved.TreeCount)
+	assert.Equal(t, uint64(1), saved.Version)
+}
+
+func testConformanceRollback(t *testing.T, repo RepositoryInterface) {
+	ctx := context.Background()
+	estate := newConformanceEstate(t, repo)
+
+	errRollback := errors.New("rollback")
+	err := repo.RunInTx(ctx, func(tx RepositoryInterface) error {
+		tree, err := models.NewTree(estate, 1, 1, 10)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.SaveTree(ctx, tree); err != nil {
+			return err
+		}
+
+		return errRollback
+	})
+	assert.ErrorIs(t, err, errRollback)
+
+	tree, err := repo.GetTreeByCoordinate(ctx, estate.ID, 1, 1)
+	assert.NoError(t, err)
+	assert.Nil(t, tree)
+
+	saved, err := repo.GetEstate(ctx, estate.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(0), saved.TreeCount)
+	assert.Equal(t, uint64(0), saved.Version)
+}
+
+func testConformanceRetireAndHistory(t *testing.T, repo RepositoryInterface) {
+	ctx := context.Background()
+	estate := newConformanceEstate(t, repo)
+
+	tree, err := models.NewTree(estate, 1, 1, 10)
+	require.NoError(t, err)
+	require.NoError(t, repo.SaveTree(ctx, tree))
+
+	retired, err := repo.GetTree(ctx, estate.ID, tree.UUID)
+	require.NoError(t, err)
+	require.NotNil(t, retired)
+	require.NoError(t, retired.Retire())
+	retired.Estate = estate
+	require.NoError(t, repo.SaveTree(ctx, retired))
+
+	replanted, err := models.NewTree(estate, 1, 1, 2)
+	require.NoError(t, err)
+	require.NoError(t, repo.SaveTree(ctx, replanted))
+
+	history, err := repo.GetTreeHistoryByCoordinate(ctx, estate.ID, 1, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, *history, 2) {
+		assert.Equal(t, tree.UUID, (*history)[0].UUID)
+		assert.True(t, (*history)[0].IsRetired())
+		assert.Equal(t, replanted.UUID, (*history)[1].UUID)
+		assert.False(t, (*history)[1].IsRetired())
+	}
+
+	trees, err := repo.GetTreesByEstate(ctx, estate.ID)
+	assert.NoError(t, err)
+	assert.Len(t, *trees, 1)
+}
+
+func testConformanceTotals(t *testing.T, repo RepositoryInterface) {
+	ctx := context.Background()
+
+	totals, err := repo.GetTotals(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Totals{}, *totals)
+
+	estate := newConformanceEstate(t, repo)
+	newConformanceEstate(t, repo)
+
+	tree, err := models.NewTree(estate, 1, 1, 10)
+	require.NoError(t, err)
+	require.NoError(t, repo.SaveTree(ctx, tree))
+
+	totals, err = repo.GetTotals(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Totals{Estates: 2, Trees: 1}, *totals)
+}
+
+func testConformancePing(t *testing.T, repo RepositoryInterface) {
+	ctx := context.Background()
+
+	assert.NoError(t, repo.Ping(ctx))
+	assert.NoError(t, repo.RunInTx(ctx, func(repo RepositoryInterface) error {
+		return repo.Ping(ctx)
+	}))
+}
+
+// testConformanceConcurrent saves trees from many goroutines the way the
+// handlers do, retrying on ErrVersionConflict, none of them must be lost.
+func testConformanceConcurrent(t *testing.T, repo RepositoryInterface) {
+	ctx := context.Background()
+	estate := newConformanceEstate(t, repo)
+
+	const treeCount = 50
+
+	var wg sync.WaitGroup
+	for i := 0; i < treeCount; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			var err error
+			for attempt := 0; attempt < treeCount; attempt++ {
+				err = repo.RunInTx(ctx, func(tx RepositoryInterface) error {
+					current, err := tx.GetEstate(ctx, estate.UUID)
+					if err != nil {
+						return err
+					}
+
+					tree, err := models.NewTree(current, i%10+1, i/10+1, i%30+1)
+					if err != nil {
+						return err
+					}
+
+					return tx.SaveTree(ctx, tree)
+				})
+				if !errors.Is(err, ErrVersionConflict) {
+					break
+				}
+			}
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	saved, err := repo.GetEstate(ctx, estate.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(treeCount), saved.TreeCount)
+	assert.Equal(t, uint64(treeCount), saved.Version)
+}
+
+func newConformanceWebhook(t *testing.T, repo RepositoryInterface, organisationID string, estateUUID *string, events ...models.EventType) *models.Webhook {
+	webhook, err := models.NewWebhook(models.WebhookPolicy{}, organisationID, estateUUID, "https://example.com/hooks", events)
+	require.NoError(t, err)
+	require.NoError(t, repo.SaveWebhook(context.Background(), webhook))
+
+	return webhook
+}
+
+func newConformanceEvent(estate *models.Estate, eventType models.EventType) models.Event {
+	return models.Event{
+		UUID:           uuid.NewString(),
+		Type:           eventType,
+		OrganisationID: estate.OrganisationID,
+		EstateUUID:     estate.UUID,
+		Payload:        `{"type":"` + string(eventType) + `"}`,
+		CreatedAt:      time.Now(),
+	}
+}
+
+func testConformanceWebhooks(t *testing.T, repo RepositoryInterface) {
+	ctx := context.Background()
+	estate := newConformanceEstate(t, repo)
+
+	organisationWide := newConformanceWebhook(t, repo, "", nil, models.EventEstateCreated, models.EventTreeAdded)
+	oneEstate := newConformanceWebhook(t, repo, "", &estate.UUID, models.EventTreeRetired)
+	newConformanceWebhook(t, repo, "estate-co-b", nil, models.EventTreeAdded)
+
+	saved, err := repo.GetWebhook(ctx, oneEstate.UUID)
+	require.NoError(t, err)
+	if assert.NotNil(t, saved) {
+		assert.Equal(t, oneEstate.ID, saved.ID)
+		assert.Equal(t, &estate.UUID, saved.EstateUUID)
+		assert.Equal(t, models.EventTypes{models.EventTreeRetired}, saved.Events)
+		assert.Equal(t, oneEstate.Secret, saved.Secret)
+	}
+
+	webhooks, err := repo.GetWebhooksByOrganisation(ctx, "")
+	require.NoError(t, err)
+	if assert.Len(t, *webhooks, 2) {
+		assert.Equal(t, organisationWide.UUID, (*webhooks)[0].UUID)
+		assert.Nil(t, (*webhooks)[0].EstateUUID)
+		assert.Equal(t, oneEstate.UUID, (*webhooks)[1].UUID)
+	}
+
+	missing, err := repo.GetWebhook(ctx, "00000000-0000-0000-0000-000000000000")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	// The deliveries go with their webhook
+	require.NoError(t, repo.SaveEvents(ctx, []models.Event{newConformanceEvent(estate, models.EventTreeRetired)}))
+	require.NoError(t, repo.DeleteWebhook(ctx, oneEstate.ID))
+
+	missing, err = repo.GetWebhook(ctx, oneEstate.UUID)
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	deliveries, err := repo.GetWebhookDeliveries(ctx, oneEstate.ID, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, *deliveries)
+}
+
+func testConformanceWebhookDeliveries(t *testing.T, repo RepositoryInterface) {
+	ctx := context.Background()
+	estate := newConformanceEstate(t, repo)
+	otherEstate := newConformanceEstate(t, repo)
+
+	organisationWide := newConformanceWebhook(t, repo, "", nil, models.EventTreeAdded, models.EventEstateStatsChanged)
+	oneEstate := newConformanceWebhook(t, repo, "", &otherEstate.UUID, models.EventTreeAdded)
+
+	// Saved with the tree, or not at all
+	errRollback := errors.New("rollback")
+	err := repo.RunInTx(ctx, func(tx RepositoryInterface) error {
+		if err := tx.SaveEvents(ctx, []models.Event{newConformanceEvent(estate, models.EventTreeAdded)}); err != nil {
+			return err
+		}
+
+		return errRollback
+	})
+	assert.ErrorIs(t, err, errRollback)
+
+	deliveries, err := repo.GetWebhookDeliveries(ctx, organisationWide.ID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, *deliveries)
+
+	err = repo.RunInTx(ctx, func(tx RepositoryInterface) error {
+		tree, err := models.NewTree(estate, 1, 1, 10)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.SaveTree(ctx, tree); err != nil {
+			return err
+		}
+
+		return tx.SaveEvents(ctx, []models.Event{
+			newConformanceEvent(estate, models.EventTreeAdded),
+			newConformanceEvent(estate, models.EventEstateStatsChanged),
+			newConformanceEvent(estate, models.EventTreeRetired),
+		})
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.SaveEvents(ctx, []models.Event{newConformanceEvent(otherEstate, models.EventTreeAdded)}))
+
+	deliveries, err = repo.GetWebhookDeliveries(ctx, organisationWide.ID, 10)
+	require.NoError(t, err)
+	if assert.Len(t, *deliveries, 3) {
+		// Newest first
+		assert.Equal(t, models.EventTreeAdded, (*deliveries)[0].EventType)
+		assert.Equal(t, models.EventEstateStatsChanged, (*deliveries)[1].EventType)
+		assert.Equal(t, models.EventTreeAdded, (*deliveries)[2].EventType)
+		assert.Equal(t, models.DeliveryPending, (*deliveries)[2].Status)
+		assert.Equal(t, `{"type":"tree.added"}`, (*deliveries)[2].Payload)
+	}
+
+	deliveries, err = repo.GetWebhookDeliveries(ctx, organisationWide.ID, 1)
+	require.NoError(t, err)
+	assert.Len(t, *deliveries, 1)
+
+	now := time.Now().Add(time.Second)
+	claimed, err := repo.ClaimWebhookDeliveries(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, *claimed, 4)
+	for _, delivery := range *claimed {
+		if assert.NotNil(t, delivery.Webhook) {
+			assert.Equal(t, delivery.WebhookID, delivery.Webhook.ID)
+			assert.NotEmpty(t, delivery.Webhook.Secret)
+		}
+	}
+
+	// Leased to the first claim
+	again, err := repo.ClaimWebhookDeliveries(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, *again)
+
+	delivered := (*claimed)[0]
+	delivered.Succeed(204, now)
+	require.NoError(t, repo.SaveWebhookDelivery(ctx, &delivered))
+
+	failed := (*claimed)[1]
+	failed.Fail(0, "connection refused", now, nil)
+	require.NoError(t, repo.SaveWebhookDelivery(ctx, &failed))
+
+	retried := (*claimed)[2]
+	retryAt := now.Add(2 * time.Minute)
+	retried.Fail(500, "unexpected status 500", now, &retryAt)
+	require.NoError(t, repo.SaveWebhookDelivery(ctx, &retried))
+
+	// The lease of the last one ended, the retry is not due yet
+	again, err = repo.ClaimWebhookDeliveries(ctx, now.Add(time.Minute), time.Minute, 10)
+	require.NoError(t, err)
+	if assert.Len(t, *again, 1) {
+		assert.Equal(t, (*claimed)[3].UUID, (*again)[0].UUID)
+	}
+
+	again, err = repo.ClaimWebhookDeliveries(ctx, now.Add(3*time.Minute), time.Minute, 10)
+	require.NoError(t, err)
+	if assert.Len(t, *again, 2) {
+		assert.Equal(t, retried.UUID, (*again)[0].UUID)
+		assert.Equal(t, 1, (*again)[0].Attempts)
+		assert.Equal(t, 500, (*again)[0].LastStatusCode)
+		assert.Equal(t, "unexpected status 500", (*again)[0].LastError)
+	}
+
+	var logged []models.WebhookDelivery
+	for _, webhookID := range []uint64{organisationWide.ID, oneEstate.ID} {
+		deliveries, err := repo.GetWebhookDeliveries(ctx, webhookID, 10)
+		require.NoError(t, err)
+		logged = append(logged, *deliveries...)
+	}
+
+	statuses := make(map[string]models.DeliveryStatus)
+	for _, delivery := range logged {
+		statuses[delivery.UUID] = delivery.Status
+		if delivery.UUID == delivered.UUID {
+			assert.Equal(t, 1, delivery.Attempts)
+			assert.Equal(t, 204, delivery.LastStatusCode)
+			assert.NotNil(t, delivery.DeliveredAt)
+		}
+	}
+	assert.Equal(t, models.DeliveryDelivered, statuses[delivered.UUID])
+	assert.Equal(t, models.DeliveryAbandoned, statuses[failed.UUID])
+	assert.Equal(t, models.DeliveryPending, statuses[retried.UUID])
+}
+
+func testConformanceOutbox(t *testing.T, repo RepositoryInterface) {
+	ctx := context.Background()
+	estate := newConformanceEstate(t, repo)
+
+	// The changes only, in the order they were saved
+	require.NoError(t, repo.SaveEvents(ctx, []models.Event{
+		newConformanceEvent(estate, models.EventTreeAdded),
+		newConformanceEvent(estate, models.EventEstateStatsChanged),
+	}))
+	require.NoError(t, repo.SaveEvents(ctx, []models.Event{newConformanceEvent(estate, models.EventDronePlanComputed)}))
+	require.NoError(t, repo.SaveEvents(ctx, []models.Event{newConformanceEvent(estate, models.EventTreeRetired)}))
+
+	events, err := repo.GetOutboxEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, *events, 3)
+	assert.Equal(t, models.EventTreeAdded, (*events)[0].Type)
+	assert.Equal(t, models.EventEstateStatsChanged, (*events)[1].Type)
+	assert.Equal(t, models.EventTreeRetired, (*events)[2].Type)
+	assert.Less(t, (*events)[0].ID, (*events)[1].ID)
+	assert.Less(t, (*events)[1].ID, (*events)[2].ID)
+	assert.Equal(t, estate.UUID, (*events)[0].EstateUUID)
+	assert.Equal(t, `{"type":"tree.added"}`, (*events)[0].Payload)
+
+	first, err := repo.GetOutboxEvents(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, (*events)[:1], *first)
+
+	// A rolled back change leaves no event behind
+	err = repo.RunInTx(ctx, func(tx RepositoryInterface) error {
+		require.NoError(t, tx.SaveEvents(ctx, []models.Event{newConformanceEvent(estate, models.EventTreeAdded)}))
+		return errors.New("rollback")
+	})
+	assert.Error(t, err)
+
+	require.NoError(t, repo.DeleteOutboxEvents(ctx, []uint64{(*events)[0].ID, (*events)[1].ID}))
+	left, err := repo.GetOutboxEvents(ctx, 10)
+	require.NoError(t, err)
+	if assert.Len(t, *left, 1) {
+		assert.Equal(t, (*events)[2].UUID, (*left)[0].UUID)
+	}
+}
+
+func testConformanceOutboxLease(t *testing.T, repo RepositoryInterface) {
+	ctx := context.Background()
+	now := time.Now()
+
+	acquired, err := repo.AcquireOutboxLease(ctx, "relay-a", now, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// Renewed by its holder, refused to the others until it expires
+	acquired, err = repo.AcquireOutboxLease(ctx, "relay-a", now.Add(time.Second), time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	acquired, err = repo.AcquireOutboxLease(ctx, "relay-b", now.Add(time.Minute), time.Minute)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	acquired, err = repo.AcquireOutboxLease(ctx, "relay-b", now.Add(2*time.Minute), time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	acquired, err = repo.AcquireOutboxLease(ctx, "relay-a", now.Add(2*time.Minute), time.Minute)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+}
@@ -8,7 +8,7 @@ import (
 )
 
 func (r *Repository) SaveEstate(ctx context.Context, estate *models.Estate) error {
-	_, err := r.Db.NewInsert().
+	_, err := r.conn().NewInsert().
 		Model(estate).
 		ExcludeColumn("id").
 		Returning("uuid").
@@ -25,7 +25,7 @@ func (r *Repository) SaveEstate(ctx context.Context, estate *models.Estate) erro
 func (r *Repository) GetEstate(ctx context.Context, uuid string) (*models.Estate, error) {
 	var estate models.Estate
 
-	err := r.Db.NewSelect().Model(&estate).Where("uuid = ?", uuid).Scan(ctx)
+	err := r.conn().NewSelect().Model(&estate).Where("uuid = ?", uuid).Scan(ctx)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -37,40 +37,61 @@ func (r *Repository) GetEstate(ctx context.Context, uuid string) (*models.Estate
 	return &estate, nil
 }
 
+// SaveTree saves the tree and the new stats of its estate in one transaction.
+// The estate is only updated when its version is still the one that was read,
+// otherwise ErrVersionConflict is returned and nothing is saved.
 func (r *Repository) SaveTree(ctx context.Context, tree *models.Tree) error {
-	tx, err := r.Db.Begin()
-	if err != nil {
-		return err
-	}
+	expectedVersion := tree.Estate.Version
+
+	err := r.RunInTx(ctx, func(repo RepositoryInterface) error {
+		tx := repo.(*Repository).conn()
+
+		tree.Estate.Version = expectedVersion + 1
+		result, err := tx.NewUpdate().
+			Model(tree.Estate).
+			Where("id = ?", tree.Estate.ID).
+			Where("version = ?", expectedVersion).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
 
-	_, err = r.Db.NewInsert().
-		Model(tree).
-		ExcludeColumn("id").
-		Returning("uuid").
-		On("CONFLICT (uuid) DO UPDATE").
-		Exec(ctx)
-	if err != nil {
-		tx.Rollback()
-		return err
-	}
+		updated, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if updated == 0 {
+			return ErrVersionConflict
+		}
+
+		_, err = tx.NewInsert().
+			Model(tree).
+			ExcludeColumn("id").
+			Returning("uuid").
+			On("CONFLICT (uuid) DO UPDATE").
+			Exec(ctx)
+		if isUniqueViolation(err) {
+			return ErrDuplicateTreeLocation
+		}
 
-	_, err = r.Db.NewUpdate().Model(tree.Estate).Where("id = ?", tree.Estate.ID).Exec(ctx)
+		return err
+	})
 	if err != nil {
-		tx.Rollback()
+		tree.Estate.Version = expectedVersion
 		return err
 	}
 
-	tx.Commit()
-
 	return nil
 }
 
 func (r *Repository) GetTreeByCoordinate(ctx context.Context, estateId uint64, x uint16, y uint16) (*models.Tree, error) {
 	var tree models.Tree
-	err := r.Db.NewSelect().Model(&tree).
+	err := r.conn().NewSelect().Model(&tree).
 		Where("estate_id = ?", estateId).
 		Where("x = ?", x).
 		Where("y = ?", y).
+		Where("retired_at IS NULL").
 		Scan(ctx)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -86,9 +107,10 @@ func (r *Repository) GetTreeByCoordinate(ctx context.Context, estateId uint64, x
 func (r *Repository) GetTreesByEstate(ctx context.Context, estateId uint64) (*[]models.Tree, error) {
 	var trees []models.Tree
 
-	err := r.Db.NewSelect().Model(&trees).
+	err := r.conn().NewSelect().Model(&trees).
 		Column("uuid", "x", "y", "height").
 		Where("estate_id = ?", estateId).
+		Where("retired_at IS NULL").
 		Order("height asc").
 		Scan(ctx)
 	if err != nil {
@@ -101,3 +123,67 @@ func (r *Repository) GetTreesByEstate(ctx context.Context, estateId uint64) (*[]
 
 	return &trees, nil
 }
+
+func (r *Repository) GetTree(ctx context.Context, estateId uint64, uuid string) (*models.Tree, error) {
+	var tree models.Tree
+
+	err := r.conn().NewSelect().Model(&tree).
+		Where("estate_id = ?", estateId).
+		Where("uuid = ?", uuid).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &tree, nil
+}
+
+func (r *Repository) GetTreeHistoryByCoordinate(ctx context.Context, estateId uint64, x uint16, y uint16) (*[]models.Tree, error) {
+	var trees []models.Tree
+
+	err := r.conn().NewSelect().Model(&trees).
+		Where("estate_id = ?", estateId).
+		Where("x = ?", x).
+		Where("y = ?", y).
+		Order("created_at asc", "id asc").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(trees) == 0 {
+		return &[]models.Tree{}, nil
+	}
+
+	return &trees, nil
+}
+
+// GetTotals sums the tree counts of the estates rather than counting the
+// trees, the estates are far fewer rows
+func (r *Repository) GetTotals(ctx context.Context) (*Totals, error) {
+	var totals Totals
+
+	err := r.conn().NewSelect().
+		Model((*models.Estate)(nil)).
+		ColumnExpr("COUNT(*) AS estates").
+		ColumnExpr("COALESCE(SUM(tree_count), 0) AS trees").
+		Scan(ctx, &totals.Estates, &totals.Trees)
+	if err != nil {
+		return nil, err
+	}
+
+	return &totals, nil
+}
+
+func (r *Repository) Ping(ctx context.Context) error {
+	// A transaction holds its connection, SQLite has no other one to ping
+	if r.tx != nil {
+		_, err := r.tx.ExecContext(ctx, "SELECT 1")
+		return err
+	}
+
+	return r.Db.PingContext(ctx)
+}
@@ -6,11 +6,24 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/SawitProRecruitment/UserService/models"
 )
 
+// ErrVersionConflict is returned when saving a tree whose estate was modified
+// since it was read, the caller should read the estate again and retry.
+var ErrVersionConflict = errors.New("estate version conflict")
+
+// ErrDuplicateTreeLocation is returned when saving a tree on a plot which
+// already has an active tree.
+var ErrDuplicateTreeLocation = models.ErrDuplicateCoordinate
+
 type RepositoryInterface interface {
+	// RunInTx groups the calls made on repo in one transaction, it is rolled
+	// back when fn returns an error
+	RunInTx(ctx context.Context, fn func(repo RepositoryInterface) error) error
 	SaveEstate(ctx context.Context, estate *models.Estate) error
 
 	GetEstate(ctx context.Context, uuid string) (*models.Estate, error)
@@ -20,4 +33,41 @@ type RepositoryInterface interface {
 	GetTreeByCoordinate(ctx context.Context, estateId uint64, x uint16, y uint16) (*models.Tree, error)
 
 	GetTreesByEstate(ctx context.Context, estateId uint64) (*[]models.Tree, error)
+	GetTree(ctx context.Context, estateId uint64, uuid string) (*models.Tree, error)
+	GetTreeHistoryByCoordinate(ctx context.Context, estateId uint64, x uint16, y uint16) (*[]models.Tree, error)
+
+	GetTotals(ctx context.Context) (*Totals, error)
+
+	// SaveEvents queues a delivery of every event to each webhook receiving
+	// it, and adds the changes to the outbox, in the transaction of the change
+	// the events notify
+	SaveEvents(ctx context.Context, events []models.Event) error
+
+	SaveWebhook(ctx context.Context, webhook *models.Webhook) error
+	GetWebhook(ctx context.Context, uuid string) (*models.Webhook, error)
+	GetWebhooksByOrganisation(ctx context.Context, organisationId string) (*[]models.Webhook, error)
+	// DeleteWebhook deletes the webhook with its deliveries
+	DeleteWebhook(ctx context.Context, webhookId uint64) error
+
+	// ClaimWebhookDeliveries returns up to limit pending deliveries due at now,
+	// with their webhook, and postpones them to now + lease so no other
+	// dispatcher sends them meanwhile
+	ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) (*[]models.WebhookDelivery, error)
+	// SaveWebhookDelivery saves the outcome of an attempt
+	SaveWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	// GetWebhookDeliveries lists the latest deliveries of the webhook, newest
+	// first
+	GetWebhookDeliveries(ctx context.Context, webhookId uint64, limit int) (*[]models.WebhookDelivery, error)
+
+	// AcquireOutboxLease makes holder the only relay of the outbox until
+	// now + ttl, it answers false while another holder has the lease. The
+	// holder renews it by acquiring it again.
+	AcquireOutboxLease(ctx context.Context, holder string, now time.Time, ttl time.Duration) (bool, error)
+	// GetOutboxEvents lists up to limit events of the outbox, oldest first
+	GetOutboxEvents(ctx context.Context, limit int) (*[]models.Event, error)
+	// DeleteOutboxEvents removes the published events from the outbox
+	DeleteOutboxEvents(ctx context.Context, eventIds []uint64) error
+
+	// Ping checks the storage answers, for the readiness probe
+	Ping(ctx context.Context) error
 }
@@ -12,6 +12,7 @@ package repository
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/SawitProRecruitment/UserService/models"
 	gomock "go.uber.org/mock/gomock"
@@ -40,6 +41,64 @@ func (m *MockRepositoryInterface) EXPECT() *MockRepositoryInterfaceMockRecorder
 	return m.recorder
 }
 
+// AcquireOutboxLease mocks base method.
+func (m *MockRepositoryInterface) AcquireOutboxLease(ctx context.Context, holder string, now time.Time, ttl time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireOutboxLease", ctx, holder, now, ttl)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireOutboxLease indicates an expected call of AcquireOutboxLease.
+func (mr *MockRepositoryInterfaceMockRecorder) AcquireOutboxLease(ctx, holder, now, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireOutboxLease", reflect.TypeOf((*MockRepositoryInterface)(nil).AcquireOutboxLease), ctx, holder, now, ttl)
+}
+
+// ClaimWebhookDeliveries mocks base method.
+func (m *MockRepositoryInterface) ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) (*[]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimWebhookDeliveries", ctx, now, lease, limit)
+	ret0, _ := ret[0].(*[]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimWebhookDeliveries indicates an expected call of ClaimWebhookDeliveries.
+func (mr *MockRepositoryInterfaceMockRecorder) ClaimWebhookDeliveries(ctx, now, lease, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimWebhookDeliveries", reflect.TypeOf((*MockRepositoryInterface)(nil).ClaimWebhookDeliveries), ctx, now, lease, limit)
+}
+
+// DeleteOutboxEvents mocks base method.
+func (m *MockRepositoryInterface) DeleteOutboxEvents(ctx context.Context, eventIds []uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOutboxEvents", ctx, eventIds)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOutboxEvents indicates an expected call of DeleteOutboxEvents.
+func (mr *MockRepositoryInterfaceMockRecorder) DeleteOutboxEvents(ctx, eventIds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOutboxEvents", reflect.TypeOf((*MockRepositoryInterface)(nil).DeleteOutboxEvents), ctx, eventIds)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockRepositoryInterface) DeleteWebhook(ctx context.Context, webhookId uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, webhookId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockRepositoryInterfaceMockRecorder) DeleteWebhook(ctx, webhookId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockRepositoryInterface)(nil).DeleteWebhook), ctx, webhookId)
+}
+
 // GetEstate mocks base method.
 func (m *MockRepositoryInterface) GetEstate(ctx context.Context, uuid string) (*models.Estate, error) {
 	m.ctrl.T.Helper()
@@ -55,6 +114,51 @@ func (mr *MockRepositoryInterfaceMockRecorder) GetEstate(ctx, uuid any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEstate", reflect.TypeOf((*MockRepositoryInterface)(nil).GetEstate), ctx, uuid)
 }
 
+// GetOutboxEvents mocks base method.
+func (m *MockRepositoryInterface) GetOutboxEvents(ctx context.Context, limit int) (*[]models.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutboxEvents", ctx, limit)
+	ret0, _ := ret[0].(*[]models.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutboxEvents indicates an expected call of GetOutboxEvents.
+func (mr *MockRepositoryInterfaceMockRecorder) GetOutboxEvents(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutboxEvents", reflect.TypeOf((*MockRepositoryInterface)(nil).GetOutboxEvents), ctx, limit)
+}
+
+// GetTotals mocks base method.
+func (m *MockRepositoryInterface) GetTotals(ctx context.Context) (*Totals, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTotals", ctx)
+	ret0, _ := ret[0].(*Totals)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTotals indicates an expected call of GetTotals.
+func (mr *MockRepositoryInterfaceMockRecorder) GetTotals(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTotals", reflect.TypeOf((*MockRepositoryInterface)(nil).GetTotals), ctx)
+}
+
+// GetTree mocks base method.
+func (m *MockRepositoryInterface) GetTree(ctx context.Context, estateId uint64, uuid string) (*models.Tree, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTree", ctx, estateId, uuid)
+	ret0, _ := ret[0].(*models.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTree indicates an expected call of GetTree.
+func (mr *MockRepositoryInterfaceMockRecorder) GetTree(ctx, estateId, uuid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTree", reflect.TypeOf((*MockRepositoryInterface)(nil).GetTree), ctx, estateId, uuid)
+}
+
 // GetTreeByCoordinate mocks base method.
 func (m *MockRepositoryInterface) GetTreeByCoordinate(ctx context.Context, estateId uint64, x, y uint16) (*models.Tree, error) {
 	m.ctrl.T.Helper()
@@ -70,6 +174,21 @@ func (mr *MockRepositoryInterfaceMockRecorder) GetTreeByCoordinate(ctx, estateId
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTreeByCoordinate", reflect.TypeOf((*MockRepositoryInterface)(nil).GetTreeByCoordinate), ctx, estateId, x, y)
 }
 
+// GetTreeHistoryByCoordinate mocks base method.
+func (m *MockRepositoryInterface) GetTreeHistoryByCoordinate(ctx context.Context, estateId uint64, x, y uint16) (*[]models.Tree, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTreeHistoryByCoordinate", ctx, estateId, x, y)
+	ret0, _ := ret[0].(*[]models.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTreeHistoryByCoordinate indicates an expected call of GetTreeHistoryByCoordinate.
+func (mr *MockRepositoryInterfaceMockRecorder) GetTreeHistoryByCoordinate(ctx, estateId, x, y any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTreeHistoryByCoordinate", reflect.TypeOf((*MockRepositoryInterface)(nil).GetTreeHistoryByCoordinate), ctx, estateId, x, y)
+}
+
 // GetTreesByEstate mocks base method.
 func (m *MockRepositoryInterface) GetTreesByEstate(ctx context.Context, estateId uint64) (*[]models.Tree, error) {
 	m.ctrl.T.Helper()
@@ -85,6 +204,79 @@ func (mr *MockRepositoryInterfaceMockRecorder) GetTreesByEstate(ctx, estateId an
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTreesByEstate", reflect.TypeOf((*MockRepositoryInterface)(nil).GetTreesByEstate), ctx, estateId)
 }
 
+// GetWebhook mocks base method.
+func (m *MockRepositoryInterface) GetWebhook(ctx context.Context, uuid string) (*models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhook", ctx, uuid)
+	ret0, _ := ret[0].(*models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhook indicates an expected call of GetWebhook.
+func (mr *MockRepositoryInterfaceMockRecorder) GetWebhook(ctx, uuid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhook", reflect.TypeOf((*MockRepositoryInterface)(nil).GetWebhook), ctx, uuid)
+}
+
+// GetWebhookDeliveries mocks base method.
+func (m *MockRepositoryInterface) GetWebhookDeliveries(ctx context.Context, webhookId uint64, limit int) (*[]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", ctx, webhookId, limit)
+	ret0, _ := ret[0].(*[]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries.
+func (mr *MockRepositoryInterfaceMockRecorder) GetWebhookDeliveries(ctx, webhookId, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockRepositoryInterface)(nil).GetWebhookDeliveries), ctx, webhookId, limit)
+}
+
+// GetWebhooksByOrganisation mocks base method.
+func (m *MockRepositoryInterface) GetWebhooksByOrganisation(ctx context.Context, organisationId string) (*[]models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooksByOrganisation", ctx, organisationId)
+	ret0, _ := ret[0].(*[]models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooksByOrganisation indicates an expected call of GetWebhooksByOrganisation.
+func (mr *MockRepositoryInterfaceMockRecorder) GetWebhooksByOrganisation(ctx, organisationId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooksByOrganisation", reflect.TypeOf((*MockRepositoryInterface)(nil).GetWebhooksByOrganisation), ctx, organisationId)
+}
+
+// Ping mocks base method.
+func (m *MockRepositoryInterface) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockRepositoryInterfaceMockRecorder) Ping(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockRepositoryInterface)(nil).Ping), ctx)
+}
+
+// RunInTx mocks base method.
+func (m *MockRepositoryInterface) RunInTx(ctx context.Context, fn func(RepositoryInterface) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunInTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunInTx indicates an expected call of RunInTx.
+func (mr *MockRepositoryInterfaceMockRecorder) RunInTx(ctx, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunInTx", reflect.TypeOf((*MockRepositoryInterface)(nil).RunInTx), ctx, fn)
+}
+
 // SaveEstate mocks base method.
 func (m *MockRepositoryInterface) SaveEstate(ctx context.Context, estate *models.Estate) error {
 	m.ctrl.T.Helper()
@@ -99,6 +291,20 @@ func (mr *MockRepositoryInterfaceMockRecorder) SaveEstate(ctx, estate any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveEstate", reflect.TypeOf((*MockRepositoryInterface)(nil).SaveEstate), ctx, estate)
 }
 
+// SaveEvents mocks base method.
+func (m *MockRepositoryInterface) SaveEvents(ctx context.Context, events []models.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveEvents", ctx, events)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveEvents indicates an expected call of SaveEvents.
+func (mr *MockRepositoryInterfaceMockRecorder) SaveEvents(ctx, events any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveEvents", reflect.TypeOf((*MockRepositoryInterface)(nil).SaveEvents), ctx, events)
+}
+
 // SaveTree mocks base method.
 func (m *MockRepositoryInterface) SaveTree(ctx context.Context, tree *models.Tree) error {
 	m.ctrl.T.Helper()
@@ -112,3 +318,31 @@ func (mr *MockRepositoryInterfaceMockRecorder) SaveTree(ctx, tree any) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTree", reflect.TypeOf((*MockRepositoryInterface)(nil).SaveTree), ctx, tree)
 }
+
+// SaveWebhook mocks base method.
+func (m *MockRepositoryInterface) SaveWebhook(ctx context.Context, webhook *models.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWebhook", ctx, webhook)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveWebhook indicates an expected call of SaveWebhook.
+func (mr *MockRepositoryInterfaceMockRecorder) SaveWebhook(ctx, webhook any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebhook", reflect.TypeOf((*MockRepositoryInterface)(nil).SaveWebhook), ctx, webhook)
+}
+
+// SaveWebhookDelivery mocks base method.
+func (m *MockRepositoryInterface) SaveWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWebhookDelivery", ctx, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveWebhookDelivery indicates an expected call of SaveWebhookDelivery.
+func (mr *MockRepositoryInterfaceMockRecorder) SaveWebhookDelivery(ctx, delivery any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebhookDelivery", reflect.TypeOf((*MockRepositoryInterface)(nil).SaveWebhookDelivery), ctx, delivery)
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// slowQuery is the duration above which a query is logged as a warning
+const slowQuery = 500 * time.Millisecond
+
+// queryLogHook logs the queries at debug level with their duration, the
+// slow ones at warn level. The request ID comes with the query context.
+type queryLogHook struct {
+	logger *slog.Logger
+}
+
+var _ bun.QueryHook = (*queryLogHook)(nil)
+
+func (h *queryLogHook) BeforeQuery(ctx context.Context, event *bun.QueryEvent) context.Context {
+	return ctx
+}
+
+func (h *queryLogHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
+	duration := time.Since(event.StartTime)
+
+	level := slog.LevelDebug
+	if duration >= slowQuery {
+		level = slog.LevelWarn
+	}
+
+	if !h.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("operation", event.Operation()),
+		slog.String("query", event.Query),
+		slog.Duration("duration", duration),
+	}
+
+	if event.Err != nil && !errors.Is(event.Err, sql.ErrNoRows) {
+		attrs = append(attrs, slog.String("error", event.Err.Error()))
+	}
+
+	h.logger.LogAttrs(ctx, level, "query", attrs...)
+}
@@ -0,0 +1,325 @@
+// This file contains an in-memory implementation of the repository layer,
+// used to run the API without a database for tests and local demos.
+//
+// The memory repository gives no isolation: the transactions are serialized,
+// but the reads outside of a transaction see its writes before it commits,
+// and the writes it rolls back. It must not back a production server.
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/models"
+)
+
+type MemoryRepository struct {
+	// writeMu serializes the writes, a transaction holds it until it ends.
+	// The reads only take mu, so they are not isolated from the transaction.
+	writeMu sync.Mutex
+	// mu protects the data below
+	mu sync.RWMutex
+
+	lastEstateID  uint64
+	lastTreeID    uint64
+	estates       map[string]*models.Estate
+	estateUUIDs   map[uint64]string
+	trees         map[string]*models.Tree
+	treesByEstate map[uint64][]string
+
+	lastWebhookID  uint64
+	lastDeliveryID uint64
+	webhooks       map[string]*models.Webhook
+	webhookUUIDs   map[uint64]string
+	// deliveries are ordered by ID
+	deliveries []*models.WebhookDelivery
+
+	lastEventID uint64
+	// outbox is ordered by ID
+	outbox         []*models.Event
+	outboxHolder   string
+	outboxLeaseEnd time.Time
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		estates:       make(map[string]*models.Estate),
+		estateUUIDs:   make(map[uint64]string),
+		trees:         make(map[string]*models.Tree),
+		treesByEstate: make(map[uint64][]string),
+		webhooks:      make(map[string]*models.Webhook),
+		webhookUUIDs:  make(map[uint64]string),
+	}
+}
+
+// memoryTx is the repository given to RunInTx, its writes are recorded so
+// they can be undone when the transaction is rolled back.
+type memoryTx struct {
+	repo *MemoryRepository
+	undo []func()
+}
+
+func (r *MemoryRepository) RunInTx(ctx context.Context, fn func(repo RepositoryInterface) error) error {
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+
+	tx := &memoryTx{repo: r}
+	if err := fn(tx); err != nil {
+		tx.rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (r *MemoryRepository) SaveEstate(ctx context.Context, estate *models.Estate) error {
+	return r.RunInTx(ctx, func(repo RepositoryInterface) error {
+		return repo.SaveEstate(ctx, estate)
+	})
+}
+
+func (r *MemoryRepository) SaveTree(ctx context.Context, tree *models.Tree) error {
+	return r.RunInTx(ctx, func(repo RepositoryInterface) error {
+		return repo.SaveTree(ctx, tree)
+	})
+}
+
+func (r *MemoryRepository) GetEstate(ctx context.Context, uuid string) (*models.Estate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	estate, ok := r.estates[uuid]
+	if !ok {
+		return nil, nil
+	}
+
+	estateCopy := *estate
+	return &estateCopy, nil
+}
+
+func (r *MemoryRepository) GetTreeByCoordinate(ctx context.Context, estateId uint64, x uint16, y uint16) (*models.Tree, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tree := r.activeTreeAt(estateId, x, y)
+	if tree == nil {
+		return nil, nil
+	}
+
+	return copyTree(tree), nil
+}
+
+func (r *MemoryRepository) GetTreesByEstate(ctx context.Context, estateId uint64) (*[]models.Tree, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	trees := []models.Tree{}
+	for _, uuid := range r.treesByEstate[estateId] {
+		tree := r.trees[uuid]
+		if !tree.IsRetired() {
+			trees = append(trees, *copyTree(tree))
+		}
+	}
+
+	sort.SliceStable(trees, func(i, j int) bool {
+		return trees[i].Height < trees[j].Height
+	})
+
+	return &trees, nil
+}
+
+func (r *MemoryRepository) GetTree(ctx context.Context, estateId uint64, uuid string) (*models.Tree, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tree, ok := r.trees[uuid]
+	if !ok || tree.EstateID != estateId {
+		return nil, nil
+	}
+
+	return copyTree(tree), nil
+}
+
+func (r *MemoryRepository) GetTreeHistoryByCoordinate(ctx context.Context, estateId uint64, x uint16, y uint16) (*[]models.Tree, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	trees := []models.Tree{}
+	for _, uuid := range r.treesByEstate[estateId] {
+		tree := r.trees[uuid]
+		if tree.X == x && tree.Y == y {
+			trees = append(trees, *copyTree(tree))
+		}
+	}
+
+	sort.SliceStable(trees, func(i, j int) bool {
+		if trees[i].CreatedAt.Equal(trees[j].CreatedAt) {
+			return trees[i].ID < trees[j].ID
+		}
+		return trees[i].CreatedAt.Before(trees[j].CreatedAt)
+	})
+
+	return &trees, nil
+}
+
+func (r *MemoryRepository) GetTotals(ctx context.Context) (*Totals, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	totals := Totals{Estates: uint64(len(r.estates))}
+	for _, estate := range r.estates {
+		totals.Trees += uint64(estate.TreeCount)
+	}
+
+	return &totals, nil
+}
+
+// Ping always succeeds, the memory is always there
+func (r *MemoryRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (r *MemoryRepository) activeTreeAt(estateId uint64, x uint16, y uint16) *models.Tree {
+	for _, uuid := range r.treesByEstate[estateId] {
+		tree := r.trees[uuid]
+		if tree.X == x && tree.Y == y && !tree.IsRetired() {
+			return tree
+		}
+	}
+
+	return nil
+}
+
+// copyTree returns a copy of the stored tree without its estate relation
+func copyTree(tree *models.Tree) *models.Tree {
+	treeCopy := *tree
+	treeCopy.Estate = nil
+	if tree.RetiredAt != nil {
+		retiredAt := *tree.RetiredAt
+		treeCopy.RetiredAt = &retiredAt
+	}
+
+	return &treeCopy
+}
+
+func (tx *memoryTx) rollback() {
+	tx.repo.mu.Lock()
+	defer tx.repo.mu.Unlock()
+
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		tx.undo[i]()
+	}
+	tx.undo = nil
+}
+
+func (tx *memoryTx) RunInTx(ctx context.Context, fn func(repo RepositoryInterface) error) error {
+	return fn(tx)
+}
+
+func (tx *memoryTx) SaveEstate(ctx context.Context, estate *models.Estate) error {
+	r := tx.repo
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *estate
+	if previous, ok := r.estates[estate.UUID]; ok {
+		previousCopy := *previous
+		stored.ID = previous.ID
+		tx.undo = append(tx.undo, func() {
+			r.estates[estate.UUID] = &previousCopy
+		})
+	} else {
+		r.lastEstateID++
+		stored.ID = r.lastEstateID
+		r.estateUUIDs[stored.ID] = stored.UUID
+		tx.undo = append(tx.undo, func() {
+			delete(r.estates, stored.UUID)
+			delete(r.estateUUIDs, stored.ID)
+		})
+	}
+
+	r.estates[estate.UUID] = &stored
+	return nil
+}
+
+// SaveTree follows the rules of the database implementation, the estate must
+// not have been modified since it was read and only one active tree per plot.
+func (tx *memoryTx) SaveTree(ctx context.Context, tree *models.Tree) error {
+	r := tx.repo
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	storedEstate := r.estates[r.estateUUIDs[tree.Estate.ID]]
+	if storedEstate == nil || storedEstate.Version != tree.Estate.Version {
+		return ErrVersionConflict
+	}
+
+	if !tree.IsRetired() {
+		if existing := r.activeTreeAt(tree.Estate.ID, tree.X, tree.Y); existing != nil && existing.UUID != tree.UUID {
+			return ErrDuplicateTreeLocation
+		}
+	}
+
+	// Update the estate stats
+	previousEstate := *storedEstate
+	tree.Estate.Version++
+	estate := *tree.Estate
+	r.estates[estate.UUID] = &estate
+	tx.undo = append(tx.undo, func() {
+		r.estates[previousEstate.UUID] = &previousEstate
+		tree.Estate.Version = previousEstate.Version
+	})
+
+	// Upsert the tree
+	stored := copyTree(tree)
+	stored.EstateID = tree.Estate.ID
+	if previous, ok := r.trees[tree.UUID]; ok {
+		previousCopy := copyTree(previous)
+		stored.ID = previous.ID
+		tx.undo = append(tx.undo, func() {
+			r.trees[previousCopy.UUID] = previousCopy
+		})
+	} else {
+		r.lastTreeID++
+		stored.ID = r.lastTreeID
+		r.treesByEstate[stored.EstateID] = append(r.treesByEstate[stored.EstateID], stored.UUID)
+		tx.undo = append(tx.undo, func() {
+			delete(r.trees, stored.UUID)
+			uuids := r.treesByEstate[stored.EstateID]
+			r.treesByEstate[stored.EstateID] = uuids[:len(uuids)-1]
+		})
+	}
+
+	r.trees[tree.UUID] = stored
+	return nil
+}
+
+func (tx *memoryTx) GetEstate(ctx context.Context, uuid string) (*models.Estate, error) {
+	return tx.repo.GetEstate(ctx, uuid)
+}
+
+func (tx *memoryTx) GetTreeByCoordinate(ctx context.Context, estateId uint64, x uint16, y uint16) (*models.Tree, error) {
+	return tx.repo.GetTreeByCoordinate(ctx, estateId, x, y)
+}
+
+func (tx *memoryTx) GetTreesByEstate(ctx context.Context, estateId uint64) (*[]models.Tree, error) {
+	return tx.repo.GetTreesByEstate(ctx, estateId)
+}
+
+func (tx *memoryTx) GetTree(ctx context.Context, estateId uint64, uuid string) (*models.Tree, error) {
+	return tx.repo.GetTree(ctx, estateId, uuid)
+}
+
+func (tx *memoryTx) GetTreeHistoryByCoordinate(ctx context.Context, estateId uint64, x uint16, y uint16) (*[]models.Tree, error) {
+	return tx.repo.GetTreeHistoryByCoordinate(ctx, estateId, x, y)
+}
+
+func (tx *memoryTx) GetTotals(ctx context.Context) (*Totals, error) {
+	return tx.repo.GetTotals(ctx)
+}
+
+func (tx *memoryTx) Ping(ctx context.Context) error {
+	return tx.repo.Ping(ctx)
+}
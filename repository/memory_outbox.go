@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/models"
+)
+
+func (r *MemoryRepository) AcquireOutboxLease(ctx context.Context, holder string, now time.Time, ttl time.Duration) (bool, error) {
+	var acquired bool
+	err := r.RunInTx(ctx, func(repo RepositoryInterface) error {
+		var err error
+		acquired, err = repo.AcquireOutboxLease(ctx, holder, now, ttl)
+		return err
+	})
+
+	return acquired, err
+}
+
+func (r *MemoryRepository) DeleteOutboxEvents(ctx context.Context, eventIds []uint64) error {
+	return r.RunInTx(ctx, func(repo RepositoryInterface) error {
+		return repo.DeleteOutboxEvents(ctx, eventIds)
+	})
+}
+
+func (r *MemoryRepository) GetOutboxEvents(ctx context.Context, limit int) (*[]models.Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := []models.Event{}
+	for i := 0; i < len(r.outbox) && len(events) < limit; i++ {
+		events = append(events, *r.outbox[i])
+	}
+
+	return &events, nil
+}
+
+// saveOutboxEvents adds the changes among events to the outbox, tx.repo.mu
+// must be held
+func (tx *memoryTx) saveOutboxEvents(events []models.Event) {
+	r := tx.repo
+
+	added := 0
+	for i := range events {
+		if !events[i].Type.IsChange() {
+			continue
+		}
+
+		event := events[i]
+		r.lastEventID++
+		event.ID = r.lastEventID
+		r.outbox = append(r.outbox, &event)
+		added++
+	}
+
+	if added > 0 {
+		tx.undo = append(tx.undo, func() {
+			r.outbox = r.outbox[:len(r.outbox)-added]
+		})
+	}
+}
+
+func (tx *memoryTx) AcquireOutboxLease(ctx context.Context, holder string, now time.Time, ttl time.Duration) (bool, error) {
+	r := tx.repo
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.outboxHolder != holder && !r.outboxLeaseEnd.Before(now) {
+		return false, nil
+	}
+
+	previousHolder, previousLeaseEnd := r.outboxHolder, r.outboxLeaseEnd
+	r.outboxHolder, r.outboxLeaseEnd = holder, now.Add(ttl)
+	tx.undo = append(tx.undo, func() {
+		r.outboxHolder, r.outboxLeaseEnd = previousHolder, previousLeaseEnd
+	})
+
+	return true, nil
+}
+
+func (tx *memoryTx) DeleteOutboxEvents(ctx context.Context, eventIds []uint64) error {
+	r := tx.repo
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := make(map[uint64]bool, len(eventIds))
+	for _, id := range eventIds {
+		deleted[id] = true
+	}
+
+	previousOutbox := r.outbox
+	outbox := make([]*models.Event, 0, len(r.outbox))
+	for _, event := range r.outbox {
+		if !deleted[event.ID] {
+			outbox = append(outbox, event)
+		}
+	}
+
+	r.outbox = outbox
+	tx.undo = append(tx.undo, func() {
+		r.outbox = previousOutbox
+	})
+
+	return nil
+}
+
+func (tx *memoryTx) GetOutboxEvents(ctx context.Context, limit int) (*[]models.Event, error) {
+	return tx.repo.GetOutboxEvents(ctx, limit)
+}
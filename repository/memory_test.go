@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"testing"
+)
+
+func TestMemoryRepository_Conformance(t *testing.T) {
+	testConformance(t, func(t *testing.T) RepositoryInterface {
+		return NewMemoryRepository()
+	})
+}
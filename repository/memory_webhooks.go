@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/models"
+)
+
+func (r *MemoryRepository) SaveEvents(ctx context.Context, events []models.Event) error {
+	return r.RunInTx(ctx, func(repo RepositoryInterface) error {
+		return repo.SaveEvents(ctx, events)
+	})
+}
+
+func (r *MemoryRepository) SaveWebhook(ctx context.Context, webhook *models.Webhook) error {
+	return r.RunInTx(ctx, func(repo RepositoryInterface) error {
+		return repo.SaveWebhook(ctx, webhook)
+	})
+}
+
+func (r *MemoryRepository) DeleteWebhook(ctx context.Context, webhookId uint64) error {
+	return r.RunInTx(ctx, func(repo RepositoryInterface) error {
+		return repo.DeleteWebhook(ctx, webhookId)
+	})
+}
+
+func (r *MemoryRepository) ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) (*[]models.WebhookDelivery, error) {
+	var deliveries *[]models.WebhookDelivery
+	err := r.RunInTx(ctx, func(repo RepositoryInterface) error {
+		var err error
+		deliveries, err = repo.ClaimWebhookDeliveries(ctx, now, lease, limit)
+		return err
+	})
+
+	return deliveries, err
+}
+
+func (r *MemoryRepository) SaveWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return r.RunInTx(ctx, func(repo RepositoryInterface) error {
+		return repo.SaveWebhookDelivery(ctx, delivery)
+	})
+}
+
+func (r *MemoryRepository) GetWebhook(ctx context.Context, uuid string) (*models.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhook, ok := r.webhooks[uuid]
+	if !ok {
+		return nil, nil
+	}
+
+	return copyWebhook(webhook), nil
+}
+
+func (r *MemoryRepository) GetWebhooksByOrganisation(ctx context.Context, organisationId string) (*[]models.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhooks := []models.Webhook{}
+	for _, webhook := range r.webhooks {
+		if webhook.OrganisationID == organisationId {
+			webhooks = append(webhooks, *copyWebhook(webhook))
+		}
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].ID < webhooks[j].ID
+	})
+
+	return &webhooks, nil
+}
+
+func (r *MemoryRepository) GetWebhookDeliveries(ctx context.Context, webhookId uint64, limit int) (*[]models.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	deliveries := []models.WebhookDelivery{}
+	for i := len(r.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		if r.deliveries[i].WebhookID == webhookId {
+			deliveries = append(deliveries, *copyDelivery(r.deliveries[i]))
+		}
+	}
+
+	return &deliveries, nil
+}
+
+// copyWebhook returns a copy of the stored webhook, sharing nothing with it
+func copyWebhook(webhook *models.Webhook) *models.Webhook {
+	webhookCopy := *webhook
+	webhookCopy.Events = append(models.EventTypes(nil), webhook.Events...)
+	if webhook.EstateUUID != nil {
+		estateUUID := *webhook.EstateUUID
+		webhookCopy.EstateUUID = &estateUUID
+	}
+
+	return &webhookCopy
+}
+
+// copyDelivery returns a copy of the stored delivery without its webhook
+func copyDelivery(delivery *models.WebhookDelivery) *models.WebhookDelivery {
+	deliveryCopy := *delivery
+	deliveryCopy.Webhook = nil
+	if delivery.DeliveredAt != nil {
+		deliveredAt := *delivery.DeliveredAt
+		deliveryCopy.DeliveredAt = &deliveredAt
+	}
+
+	return &deliveryCopy
+}
+
+func (tx *memoryTx) SaveEvents(ctx context.Context, events []models.Event) error {
+	r := tx.repo
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tx.saveOutboxEvents(events)
+
+	// Delivered in the order of the webhooks, as the database does
+	webhooks := make([]*models.Webhook, 0, len(r.webhooks))
+	for _, webhook := range r.webhooks {
+		webhooks = append(webhooks, webhook)
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].ID < webhooks[j].ID
+	})
+
+	added := 0
+	for i := range events {
+		for _, webhook := range webhooks {
+			if !webhook.Receives(&events[i]) {
+				continue
+			}
+
+			r.lastDeliveryID++
+			delivery := copyDelivery(models.NewWebhookDelivery(webhook, &events[i]))
+			delivery.ID = r.lastDeliveryID
+			r.deliveries = append(r.deliveries, delivery)
+			added++
+		}
+	}
+
+	if added > 0 {
+		tx.undo = append(tx.undo, func() {
+			r.deliveries = r.deliveries[:len(r.deliveries)-added]
+		})
+	}
+
+	return nil
+}
+
+func (tx *memoryTx) SaveWebhook(ctx context.Context, webhook *models.Webhook) error {
+	r := tx.repo
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastWebhookID++
+	webhook.ID = r.lastWebhookID
+	r.webhooks[webhook.UUID] = copyWebhook(webhook)
+	r.webhookUUIDs[webhook.ID] = webhook.UUID
+	tx.undo = append(tx.undo, func() {
+		delete(r.webhooks, webhook.UUID)
+		delete(r.webhookUUIDs, webhook.ID)
+	})
+
+	return nil
+}
+
+func (tx *memoryTx) DeleteWebhook(ctx context.Context, webhookId uint64) error {
+	r := tx.repo
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	uuid, ok := r.webhookUUIDs[webhookId]
+	if !ok {
+		return nil
+	}
+
+	webhook := r.webhooks[uuid]
+	previousDeliveries := r.deliveries
+	deliveries := make([]*models.WebhookDelivery, 0, len(r.deliveries))
+	for _, delivery := range r.deliveries {
+		if delivery.WebhookID != webhookId {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	delete(r.webhooks, uuid)
+	delete(r.webhookUUIDs, webhookId)
+	r.deliveries = deliveries
+	tx.undo = append(tx.undo, func() {
+		r.webhooks[uuid] = webhook
+		r.webhookUUIDs[webhookId] = uuid
+		r.deliveries = previousDeliveries
+	})
+
+	return nil
+}
+
+func (tx *memoryTx) ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) (*[]models.WebhookDelivery, error) {
+	r := tx.repo
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []*models.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.Status == models.DeliveryPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	leaseEnd := now.Add(lease)
+	deliveries := make([]models.WebhookDelivery, 0, len(due))
+	for _, delivery := range due {
+		previousNextAttemptAt := delivery.NextAttemptAt
+		delivery.NextAttemptAt = leaseEnd
+		tx.undo = append(tx.undo, func() {
+			delivery.NextAttemptAt = previousNextAttemptAt
+		})
+
+		claimed := copyDelivery(delivery)
+		claimed.Webhook = copyWebhook(r.webhooks[r.webhookUUIDs[delivery.WebhookID]])
+		deliveries = append(deliveries, *claimed)
+	}
+
+	return &deliveries, nil
+}
+
+func (tx *memoryTx) SaveWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	r := tx.repo
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, stored := range r.deliveries {
+		if stored.ID != delivery.ID {
+			continue
+		}
+
+		updated := copyDelivery(delivery)
+		r.deliveries[i] = updated
+		tx.undo = append(tx.undo, func() {
+			// The deliveries may have been deleted since
+			for j := range r.deliveries {
+				if r.deliveries[j] == updated {
+					r.deliveries[j] = stored
+				}
+			}
+		})
+		return nil
+	}
+
+	// Its webhook was deleted meanwhile
+	return nil
+}
+
+func (tx *memoryTx) GetWebhook(ctx context.Context, uuid string) (*models.Webhook, error) {
+	return tx.repo.GetWebhook(ctx, uuid)
+}
+
+func (tx *memoryTx) GetWebhooksByOrganisation(ctx context.Context, organisationId string) (*[]models.Webhook, error) {
+	return tx.repo.GetWebhooksByOrganisation(ctx, organisationId)
+}
+
+func (tx *memoryTx) GetWebhookDeliveries(ctx context.Context, webhookId uint64, limit int) (*[]models.WebhookDelivery, error) {
+	return tx.repo.GetWebhookDeliveries(ctx, webhookId, limit)
+}
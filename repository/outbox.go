@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/models"
+	"github.com/uptrace/bun"
+)
+
+// outboxRelay is the name of the lease of the relay
+const outboxRelay = "relay"
+
+type outboxLease struct {
+	bun.BaseModel `bun:"table:outbox_lease"`
+
+	Name      string    `bun:"name,pk"`
+	Holder    string    `bun:"holder,notnull"`
+	ExpiresAt time.Time `bun:"expires_at,notnull"`
+}
+
+// saveOutboxEvents adds the changes among events to the outbox. The events of
+// an estate are saved after the estate is written in the same transaction,
+// which locks it until the commit, so their ids follow the order of the
+// commits even when the ids of different estates do not.
+func (r *Repository) saveOutboxEvents(ctx context.Context, events []models.Event) error {
+	changes := make([]models.Event, 0, len(events))
+	for _, event := range events {
+		if event.Type.IsChange() {
+			changes = append(changes, event)
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	_, err := r.conn().NewInsert().
+		Model(&changes).
+		ExcludeColumn("id").
+		Exec(ctx)
+
+	return err
+}
+
+func (r *Repository) AcquireOutboxLease(ctx context.Context, holder string, now time.Time, ttl time.Duration) (bool, error) {
+	lease := &outboxLease{
+		Name:      outboxRelay,
+		Holder:    holder,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	// The lease is only taken over once expired, no row changes otherwise
+	result, err := r.conn().NewInsert().
+		Model(lease).
+		On("CONFLICT (name) DO UPDATE").
+		Set("holder = EXCLUDED.holder").
+		Set("expires_at = EXCLUDED.expires_at").
+		Where("outbox_lease.holder = EXCLUDED.holder OR outbox_lease.expires_at < ?", now).
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+func (r *Repository) GetOutboxEvents(ctx context.Context, limit int) (*[]models.Event, error) {
+	events := []models.Event{}
+
+	err := r.conn().NewSelect().Model(&events).
+		Order("id asc").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &events, nil
+}
+
+func (r *Repository) DeleteOutboxEvents(ctx context.Context, eventIds []uint64) error {
+	if len(eventIds) == 0 {
+		return nil
+	}
+
+	_, err := r.conn().NewDelete().
+		Model((*models.Event)(nil)).
+		Where("id IN (?)", bun.In(eventIds)).
+		Exec(ctx)
+
+	return err
+}
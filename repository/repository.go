@@ -2,36 +2,176 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"runtime"
+	"time"
 
+	"github.com/SawitProRecruitment/UserService/migrations"
 	_ "github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/pgdialect"
 	"github.com/uptrace/bun/driver/pgdriver"
-	"github.com/uptrace/bun/extra/bundebug"
 )
 
 type Repository struct {
 	Db *bun.DB
+
+	// tx is set when the repository is used inside RunInTx, every query then
+	// runs in that transaction instead of on Db
+	tx *bun.Tx
+
+	// migrations of the database flavour behind Db
+	migrations fs.FS
+
+	// system is the database flavour behind Db, postgresql or sqlite
+	system string
 }
 
 type NewRepositoryOptions struct {
+	// Dsn is a Postgres URL, or a SQLite database when it starts with
+	// sqlite:// or file:
 	Dsn string
+	// Logger receives the queries at debug level, slog.Default() when nil
+	Logger *slog.Logger
+
+	// Pool configures the connections to Postgres, SQLite always uses one
+	Pool PoolOptions
+}
+
+// PoolOptions are the limits of the connection pool, the zero values keep
+// the defaults below
+type PoolOptions struct {
+	// MaxOpenConns defaults to 4 per CPU, as recommended by pgdriver
+	MaxOpenConns int
+	// MaxIdleConns defaults to MaxOpenConns
+	MaxIdleConns int
+	// ConnMaxLifetime defaults to 30 minutes
+	ConnMaxLifetime time.Duration
+	// ConnMaxIdleTime defaults to 5 minutes
+	ConnMaxIdleTime time.Duration
 }
 
-func NewRepository(opts NewRepositoryOptions) *Repository {
-	pgconn := pgdriver.NewConnector(
-		pgdriver.WithDSN(opts.Dsn),
-	)
+func (o PoolOptions) apply(db *sql.DB) {
+	maxOpenConns := o.MaxOpenConns
+	if maxOpenConns <= 0 {
+		maxOpenConns = 4 * runtime.GOMAXPROCS(0)
+	}
 
-	sqldb := sql.OpenDB(pgconn)
-	db := bun.NewDB(sqldb, pgdialect.New())
-	db.AddQueryHook(bundebug.NewQueryHook(
-		bundebug.WithVerbose(true),
-		bundebug.FromEnv("BUNDEBUG"),
-	))
+	maxIdleConns := o.MaxIdleConns
+	if maxIdleConns <= 0 {
+		maxIdleConns = maxOpenConns
+	}
+
+	connMaxLifetime := o.ConnMaxLifetime
+	if connMaxLifetime <= 0 {
+		connMaxLifetime = 30 * time.Minute
+	}
+
+	connMaxIdleTime := o.ConnMaxIdleTime
+	if connMaxIdleTime <= 0 {
+		connMaxIdleTime = 5 * time.Minute
+	}
+
+	db.SetMaxOpenConns(maxOpenConns)
+	db.SetMaxIdleConns(maxIdleConns)
+	db.SetConnMaxLifetime(connMaxLifetime)
+	db.SetConnMaxIdleTime(connMaxIdleTime)
+}
+
+func NewRepository(opts NewRepositoryOptions) (*Repository, error) {
+	var db *bun.DB
+	schema := migrations.Postgres
+	system := "postgresql"
+	if isSQLiteDsn(opts.Dsn) {
+		var err error
+		db, err = newSQLiteDB(opts.Dsn)
+		if err != nil {
+			return nil, err
+		}
+		schema = migrations.SQLite
+		system = "sqlite"
+	} else {
+		pgconn := pgdriver.NewConnector(
+			pgdriver.WithDSN(opts.Dsn),
+		)
+
+		sqldb := sql.OpenDB(pgconn)
+		opts.Pool.apply(sqldb)
+		db = bun.NewDB(sqldb, pgdialect.New())
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	db.AddQueryHook(&queryLogHook{logger: logger})
 
 	return &Repository{
-		Db: db,
+		Db:         db,
+		migrations: schema,
+		system:     system,
+	}, nil
+}
+
+// Close closes the connections of the database
+func (r *Repository) Close() error {
+	return r.Db.Close()
+}
+
+// System is the database behind Db, postgresql or sqlite as named by
+// OpenTelemetry
+func (r *Repository) System() string {
+	return r.system
+}
+
+// Migrator manages the schema of the database
+func (r *Repository) Migrator() *migrations.Migrator {
+	return migrations.NewMigrator(r.Db, r.migrations)
+}
+
+// conn returns where the queries must run, the current transaction if any
+func (r *Repository) conn() bun.IDB {
+	if r.tx != nil {
+		return r.tx
 	}
+
+	return r.Db
+}
+
+// RunInTx runs fn with a repository bound to a new transaction, which is
+// committed when fn returns nil and rolled back otherwise. When the repository
+// is already in a transaction fn simply joins it.
+func (r *Repository) RunInTx(ctx context.Context, fn func(repo RepositoryInterface) error) error {
+	if r.tx != nil {
+		return fn(r)
+	}
+
+	return r.Db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return fn(&Repository{
+			Db:         r.Db,
+			tx:         &tx,
+			migrations: r.migrations,
+			system:     r.system,
+		})
+	})
+}
+
+// isUniqueViolation tells if err comes from a unique index of the database
+func isUniqueViolation(err error) bool {
+	var pgErr pgdriver.Error
+	if errors.As(err, &pgErr) {
+		return pgErr.Field('C') == "23505"
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+	}
+
+	return false
 }
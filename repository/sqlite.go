@@ -0,0 +1,67 @@
+// This file contains the SQLite flavour of the repository, for the machines
+// where no Postgres server is available.
+package repository
+
+import (
+	"database/sql"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/feature"
+	"github.com/uptrace/bun/dialect/pgdialect"
+)
+
+// sqliteDefaultParams are added to every SQLite DSN, values already in the DSN
+// take precedence.
+const sqliteDefaultParams = "_foreign_keys=on&_busy_timeout=5000"
+
+// isSQLiteDsn tells if the DSN points to a SQLite database, either
+// sqlite://path/to/file.db or file:path/to/file.db
+func isSQLiteDsn(dsn string) bool {
+	return strings.HasPrefix(dsn, "sqlite://") || strings.HasPrefix(dsn, "file:")
+}
+
+// sqliteDsn converts the DSN to the format of the driver
+func sqliteDsn(dsn string) string {
+	if path, ok := strings.CutPrefix(dsn, "sqlite://"); ok {
+		dsn = "file:" + path
+	}
+
+	if strings.Contains(dsn, "?") {
+		return dsn + "&" + sqliteDefaultParams
+	}
+
+	return dsn + "?" + sqliteDefaultParams
+}
+
+// sqliteDialect keeps the queries of this package identical on both
+// databases: SQLite understands the SQL generated by the Postgres dialect
+// (RETURNING, ON CONFLICT, table aliases) except for the features removed here,
+// most notably DEFAULT as a placeholder in VALUES, NULL is used instead.
+type sqliteDialect struct {
+	*pgdialect.Dialect
+}
+
+func (d sqliteDialect) Features() feature.Feature {
+	return d.Dialect.Features() &^ (feature.DefaultPlaceholder |
+		feature.DoubleColonCast |
+		feature.TableCascade |
+		feature.TableIdentity |
+		feature.TableTruncate |
+		feature.GeneratedIdentity)
+}
+
+// newSQLiteDB opens the database, its schema is managed by the migrations
+func newSQLiteDB(dsn string) (*bun.DB, error) {
+	sqldb, err := sql.Open("sqlite3", sqliteDsn(dsn))
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite has a single writer, sharing one connection queues the
+	// transactions instead of failing them with SQLITE_BUSY
+	sqldb.SetMaxOpenConns(1)
+
+	return bun.NewDB(sqldb, sqliteDialect{pgdialect.New()}), nil
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSQLiteTestRepository(t *testing.T) *Repository {
+	dsn := "sqlite://" + filepath.Join(t.TempDir(), "sawit.db")
+
+	repo, err := NewRepository(NewRepositoryOptions{
+		Dsn: dsn,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		repo.Db.Close()
+	})
+
+	_, err = repo.Migrator().Up(context.Background())
+	require.NoError(t, err)
+
+	return repo
+}
+
+func TestSQLiteRepository_Conformance(t *testing.T) {
+	testConformance(t, func(t *testing.T) RepositoryInterface {
+		return newSQLiteTestRepository(t)
+	})
+}
+
+func TestIsSQLiteDsn(t *testing.T) {
+	assert.True(t, isSQLiteDsn("sqlite://sawit.db"))
+	assert.True(t, isSQLiteDsn("file:sawit.db?cache=shared"))
+	assert.False(t, isSQLiteDsn("postgres://postgres:postgres@db:5432/database?sslmode=disable"))
+	assert.False(t, isSQLiteDsn(""))
+}
+
+func TestSQLiteDsn(t *testing.T) {
+	assert.Equal(t, "file:/tmp/sawit.db?"+sqliteDefaultParams, sqliteDsn("sqlite:///tmp/sawit.db"))
+	assert.Equal(t, "file:sawit.db?mode=ro&"+sqliteDefaultParams, sqliteDsn("file:sawit.db?mode=ro"))
+}
@@ -8,3 +8,10 @@ type GetTestByIdInput struct {
 type GetTestByIdOutput struct {
 	Name string
 }
+
+// Totals counts what every organisation stores together
+type Totals struct {
+	Estates uint64
+	// Trees are the active trees, the retired ones are only history
+	Trees uint64
+}
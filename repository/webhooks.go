@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/models"
+	"github.com/uptrace/bun"
+)
+
+func (r *Repository) SaveEvents(ctx context.Context, events []models.Event) error {
+	if err := r.saveOutboxEvents(ctx, events); err != nil {
+		return err
+	}
+
+	var deliveries []models.WebhookDelivery
+	for i := range events {
+		event := &events[i]
+
+		var webhooks []models.Webhook
+		err := r.conn().NewSelect().Model(&webhooks).
+			Where("organisation_id = ?", event.OrganisationID).
+			WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+				return q.Where("estate_uuid IS NULL").WhereOr("estate_uuid = ?", event.EstateUUID)
+			}).
+			Scan(ctx)
+		if err != nil {
+			return err
+		}
+
+		for j := range webhooks {
+			if webhooks[j].Receives(event) {
+				deliveries = append(deliveries, *models.NewWebhookDelivery(&webhooks[j], event))
+			}
+		}
+	}
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	_, err := r.conn().NewInsert().
+		Model(&deliveries).
+		ExcludeColumn("id").
+		Exec(ctx)
+
+	return err
+}
+
+func (r *Repository) SaveWebhook(ctx context.Context, webhook *models.Webhook) error {
+	_, err := r.conn().NewInsert().
+		Model(webhook).
+		ExcludeColumn("id").
+		Returning("id").
+		Exec(ctx)
+
+	return err
+}
+
+func (r *Repository) GetWebhook(ctx context.Context, uuid string) (*models.Webhook, error) {
+	var webhook models.Webhook
+
+	err := r.conn().NewSelect().Model(&webhook).Where("uuid = ?", uuid).Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func (r *Repository) GetWebhooksByOrganisation(ctx context.Context, organisationId string) (*[]models.Webhook, error) {
+	webhooks := []models.Webhook{}
+
+	err := r.conn().NewSelect().Model(&webhooks).
+		Where("organisation_id = ?", organisationId).
+		Order("id asc").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhooks, nil
+}
+
+func (r *Repository) DeleteWebhook(ctx context.Context, webhookId uint64) error {
+	return r.RunInTx(ctx, func(repo RepositoryInterface) error {
+		tx := repo.(*Repository).conn()
+
+		_, err := tx.NewDelete().
+			Model((*models.WebhookDelivery)(nil)).
+			Where("webhook_id = ?", webhookId).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewDelete().
+			Model((*models.Webhook)(nil)).
+			Where("id = ?", webhookId).
+			Exec(ctx)
+
+		return err
+	})
+}
+
+func (r *Repository) ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) (*[]models.WebhookDelivery, error) {
+	deliveries := []models.WebhookDelivery{}
+
+	err := r.RunInTx(ctx, func(repo RepositoryInterface) error {
+		tx := repo.(*Repository).conn()
+
+		query := tx.NewSelect().Model(&deliveries).
+			Where("status = ?", models.DeliveryPending).
+			Where("next_attempt_at <= ?", now).
+			Order("next_attempt_at asc", "id asc").
+			Limit(limit)
+		// The dispatchers of the other servers skip the deliveries being
+		// claimed, SQLite has a single writer anyway
+		if r.system == "postgresql" {
+			query = query.For("UPDATE SKIP LOCKED")
+		}
+		if err := query.Scan(ctx); err != nil {
+			return err
+		}
+
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]uint64, 0, len(deliveries))
+		webhookIds := make([]uint64, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.ID)
+			webhookIds = append(webhookIds, delivery.WebhookID)
+		}
+
+		leaseEnd := now.Add(lease)
+		_, err := tx.NewUpdate().
+			Model((*models.WebhookDelivery)(nil)).
+			Set("next_attempt_at = ?", leaseEnd).
+			Where("id IN (?)", bun.In(ids)).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		var webhooks []models.Webhook
+		err = tx.NewSelect().Model(&webhooks).
+			Where("id IN (?)", bun.In(webhookIds)).
+			Scan(ctx)
+		if err != nil {
+			return err
+		}
+
+		byId := make(map[uint64]*models.Webhook, len(webhooks))
+		for i := range webhooks {
+			byId[webhooks[i].ID] = &webhooks[i]
+		}
+
+		for i := range deliveries {
+			deliveries[i].NextAttemptAt = leaseEnd
+			deliveries[i].Webhook = byId[deliveries[i].WebhookID]
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &deliveries, nil
+}
+
+func (r *Repository) SaveWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	_, err := r.conn().NewUpdate().
+		Model(delivery).
+		Column("status", "attempts", "next_attempt_at", "last_status_code", "last_error", "delivered_at", "updated_at").
+		WherePK().
+		Exec(ctx)
+
+	return err
+}
+
+func (r *Repository) GetWebhookDeliveries(ctx context.Context, webhookId uint64, limit int) (*[]models.WebhookDelivery, error) {
+	deliveries := []models.WebhookDelivery{}
+
+	err := r.conn().NewSelect().Model(&deliveries).
+		Where("webhook_id = ?", webhookId).
+		Order("id desc").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deliveries, nil
+}
@@ -8,13 +8,58 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"sync"
 	"testing"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
-const ApiUrl = "http://localhost:8080"
+// ApiUrl is the API under test, override it with API_URL to test a server
+// started outside of docker, e.g. with STORAGE=memory.
+var ApiUrl = apiUrl()
+
+func apiUrl() string {
+	if url := os.Getenv("API_URL"); url != "" {
+		return url
+	}
+
+	return "http://localhost:8080"
+}
+
+// ApiKey and OtherApiKey are API keys of two organisations, the defaults are
+// the ones of docker-compose.yml
+var (
+	ApiKey      = envOr("API_KEY", "local-key-a")
+	OtherApiKey = envOr("OTHER_API_KEY", "local-key-b")
+)
+
+func envOr(name string, value string) string {
+	if env := os.Getenv(name); env != "" {
+		return env
+	}
+
+	return value
+}
+
+// apiKeyTransport sends the API key with every request
+type apiKeyTransport struct {
+	key string
+}
+
+func (t apiKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if t.key != "" {
+		req.Header.Set("X-API-Key", t.key)
+	}
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newClient(key string) *http.Client {
+	return &http.Client{Transport: apiKeyTransport{key: key}}
+}
 
 func TestApi(t *testing.T) {
 	if testing.Short() {
@@ -23,7 +68,7 @@ func TestApi(t *testing.T) {
 
 	testcases := getTestCases()
 	ctx := context.Background()
-	client := &http.Client{}
+	client := newClient(ApiKey)
 
 	for _, tc := range testcases {
 		t.Run(tc.Name, func(t *testing.T) {
@@ -48,6 +93,139 @@ func TestApi(t *testing.T) {
 	}
 }
 
+// TestApi_ConcurrentTrees hammers a single estate with parallel tree inserts,
+// none of the updates to the estate stats must be lost.
+func TestApi_ConcurrentTrees(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skip API tests")
+	}
+
+	client := newClient(ApiKey)
+
+	body, err := json.Marshal(map[string]int{"length": 10, "width": 10})
+	require.NoError(t, err)
+
+	response, err := client.Post(ApiUrl+"/estate", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusCreated, response.StatusCode)
+
+	var estate map[string]any
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&estate))
+	id := estate["id"].(string)
+
+	const treeCount = 50
+
+	var wg sync.WaitGroup
+	statusCodes := make(chan int, treeCount)
+	for i := 0; i < treeCount; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			body, _ := json.Marshal(map[string]int{
+				"x":      i%10 + 1,
+				"y":      i/10 + 1,
+				"height": i%30 + 1,
+			})
+
+			response, err := client.Post(ApiUrl+"/estate/"+id+"/tree", "application/json", bytes.NewReader(body))
+			if err != nil {
+				statusCodes <- 0
+				return
+			}
+			defer response.Body.Close()
+
+			statusCodes <- response.StatusCode
+		}(i)
+	}
+	wg.Wait()
+	close(statusCodes)
+
+	for statusCode := range statusCodes {
+		require.Equal(t, http.StatusCreated, statusCode)
+	}
+
+	response, err = client.Get(ApiUrl + "/estate/" + id + "/stats")
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	var stats map[string]any
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&stats))
+	require.Equal(t, treeCount, int(stats["count"].(float64)))
+	require.Equal(t, 1, int(stats["min"].(float64)))
+	require.Equal(t, 30, int(stats["max"].(float64)))
+	require.Equal(t, 13, int(stats["median"].(float64)))
+}
+
+// TestApi_Organisations checks an organisation cannot reach the estates of
+// another one, and anonymous callers cannot reach any.
+func TestApi_Organisations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skip API tests")
+	}
+
+	body, err := json.Marshal(map[string]int{"length": 10, "width": 10})
+	require.NoError(t, err)
+
+	response, err := newClient(ApiKey).Post(ApiUrl+"/estate", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusCreated, response.StatusCode)
+
+	var estate map[string]any
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&estate))
+	id := estate["id"].(string)
+
+	response, err = newClient(ApiKey).Get(ApiUrl + "/estate/" + id + "/stats")
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	response, err = newClient(OtherApiKey).Get(ApiUrl + "/estate/" + id + "/stats")
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	tree, err := json.Marshal(map[string]int{"x": 1, "y": 1, "height": 10})
+	require.NoError(t, err)
+
+	response, err = newClient(OtherApiKey).Post(ApiUrl+"/estate/"+id+"/tree", "application/json", bytes.NewReader(tree))
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	response, err = newClient("").Get(ApiUrl + "/estate/" + id + "/stats")
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	var errorBody map[string]any
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&errorBody))
+	require.Equal(t, "unauthorized", errorBody["code"])
+}
+
+// TestApi_UnknownRoutes checks the paths and methods which are not in api.yml
+// are answered without credentials, rather than asking for them.
+func TestApi_UnknownRoutes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skip API tests")
+	}
+
+	response, err := newClient("").Get(ApiUrl + "/nope")
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	request, err := http.NewRequest(http.MethodDelete, ApiUrl+"/estate", nil)
+	require.NoError(t, err)
+
+	response, err = newClient("").Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, response.StatusCode)
+}
+
 func getTestCases() []TestCase {
 	return []TestCase{
 		{
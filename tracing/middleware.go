@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/SawitProRecruitment/UserService/logging"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const scopeName = "github.com/SawitProRecruitment/UserService/tracing"
+
+// Middleware opens the span of every request, named after the route it
+// matched. The trace continues the one of the traceparent header when the
+// caller sent one, and the trace ID is sent back in the traceparent header.
+func Middleware(tp trace.TracerProvider) echo.MiddlewareFunc {
+	tracer := Tracer(tp, scopeName)
+	propagator := propagation.TraceContext{}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			parent := propagator.Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+			route := ctx.Path()
+			if route == "" || route == "/*" {
+				route = "unmatched"
+			}
+
+			spanCtx, span := tracer.Start(parent, req.Method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(req.Method),
+					semconv.HTTPRoute(route),
+					semconv.URLPath(req.URL.Path),
+					semconv.ClientAddress(ctx.RealIP()),
+				),
+			)
+			defer span.End()
+
+			if requestID := logging.RequestID(req.Context()); requestID != "" {
+				span.SetAttributes(attribute.String("request.id", requestID))
+			}
+
+			propagator.Inject(spanCtx, propagation.HeaderCarrier(ctx.Response().Header()))
+			ctx.SetRequest(req.WithContext(spanCtx))
+
+			err := next(ctx)
+			if err != nil {
+				// Answers the error now so its status is the one recorded
+				ctx.Error(err)
+			}
+
+			status := ctx.Response().Status
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+				if err != nil {
+					span.RecordError(err)
+				}
+			}
+
+			return nil
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxStatement is the length above which the queries are cut in the spans,
+// the inserts of many trees would otherwise weigh more than the trace
+const maxStatement = 2048
+
+// QueryHook opens a span for every query of a bun database, a child of the
+// span of the request or the job running the query. system is the database
+// behind it, postgresql or sqlite.
+func QueryHook(tp trace.TracerProvider, system string) bun.QueryHook {
+	return &queryHook{
+		tracer: Tracer(tp, scopeName),
+		system: semconv.DBSystemKey.String(system),
+	}
+}
+
+type queryHook struct {
+	tracer trace.Tracer
+	system attribute.KeyValue
+}
+
+func (h *queryHook) BeforeQuery(ctx context.Context, event *bun.QueryEvent) context.Context {
+	operation := event.Operation()
+
+	ctx, _ = h.tracer.Start(ctx, "db."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(event.StartTime),
+		trace.WithAttributes(
+			h.system,
+			semconv.DBOperation(operation),
+		),
+	)
+
+	return ctx
+}
+
+func (h *queryHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	defer span.End()
+
+	statement := event.Query
+	if len(statement) > maxStatement {
+		statement = statement[:maxStatement]
+	}
+	span.SetAttributes(semconv.DBStatement(statement))
+
+	if event.Err != nil && !errors.Is(event.Err, sql.ErrNoRows) {
+		span.RecordError(event.Err)
+		span.SetStatus(codes.Error, event.Err.Error())
+	}
+}
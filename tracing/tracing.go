@@ -0,0 +1,86 @@
+// This file contains the OpenTelemetry tracing of the server: one span per
+// request, per handler method, per database query and per phase of the drone
+// simulation, exported through OTLP or written to stdout.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// ServiceName is the service.name of the spans unless OTEL_SERVICE_NAME is set
+const ServiceName = "sawit-estate-api"
+
+type Options struct {
+	// Exporter is otlp, stdout or none, none when empty
+	Exporter string
+	// Writer receives the spans of the stdout exporter, os.Stdout when nil
+	Writer io.Writer
+}
+
+// New is the tracer provider of the exporter of opts, and the function
+// flushing the spans left on shutdown. The spans are not recorded at all with
+// the none exporter.
+//
+// The OTLP exporter is configured by the OTEL_EXPORTER_OTLP_* variables and
+// the sampling by OTEL_TRACES_SAMPLER, every trace is kept by default.
+func New(ctx context.Context, opts Options) (trace.TracerProvider, func(context.Context) error, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch strings.ToLower(opts.Exporter) {
+	case "", "none":
+		return noop.NewTracerProvider(), func(context.Context) error { return nil }, nil
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx)
+	case "stdout":
+		writer := opts.Writer
+		if writer == nil {
+			writer = os.Stdout
+		}
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(writer))
+	default:
+		return nil, nil, fmt.Errorf("unknown trace exporter %q, expected otlp, stdout or none", opts.Exporter)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The variables override the service name
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(ServiceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+
+	return provider, provider.Shutdown, nil
+}
+
+// Tracer is the tracer of the instrumentation scope name from tp, a tracer
+// recording nothing when tp is nil
+func Tracer(tp trace.TracerProvider, name string) trace.Tracer {
+	if tp == nil {
+		tp = noop.NewTracerProvider()
+	}
+
+	return tp.Tracer(name)
+}
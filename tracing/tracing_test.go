@@ -0,0 +1,113 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+func newRecorder() (*tracetest.SpanRecorder, *sdktrace.TracerProvider) {
+	recorder := tracetest.NewSpanRecorder()
+	return recorder, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+}
+
+func TestNew(t *testing.T) {
+	ctx := context.Background()
+
+	tp, shutdown, err := New(ctx, Options{})
+	require.NoError(t, err)
+	_, span := tp.Tracer("test").Start(ctx, "dropped")
+	assert.False(t, span.IsRecording())
+	assert.NoError(t, shutdown(ctx))
+
+	_, _, err = New(ctx, Options{Exporter: "zipkin"})
+	assert.Error(t, err)
+
+	var buf bytes.Buffer
+	tp, shutdown, err = New(ctx, Options{Exporter: "stdout", Writer: &buf})
+	require.NoError(t, err)
+	_, span = tp.Tracer("test").Start(ctx, "exported")
+	span.End()
+
+	require.NoError(t, shutdown(ctx))
+	assert.Contains(t, buf.String(), `"Name":"exported"`)
+	assert.Contains(t, buf.String(), ServiceName)
+}
+
+func TestMiddleware(t *testing.T) {
+	recorder, tp := newRecorder()
+
+	e := echo.New()
+	e.Use(Middleware(tp))
+	e.GET("/estate/:id/stats", func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusOK)
+	})
+	e.GET("/fail", func(ctx echo.Context) error {
+		return errors.New("database down")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/estate/a/stats", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Contains(t, rec.Header().Get("traceparent"), "4bf92f3577b34da6a3ce929d0e0e4736")
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, "GET /estate/:id/stats", spans[0].Name())
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+		assert.Contains(t, spans[0].Attributes(), semconv.HTTPResponseStatusCode(http.StatusOK))
+		assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+		assert.Equal(t, "GET /fail", spans[1].Name())
+		assert.Contains(t, spans[1].Attributes(), semconv.HTTPResponseStatusCode(http.StatusInternalServerError))
+		assert.Equal(t, codes.Error, spans[1].Status().Code)
+	}
+}
+
+func TestQueryHook(t *testing.T) {
+	recorder, tp := newRecorder()
+
+	sqldb, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db := bun.NewDB(sqldb, pgdialect.New())
+	defer db.Close()
+	db.AddQueryHook(QueryHook(tp, "sqlite"))
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "request")
+
+	var one int
+	require.NoError(t, db.NewSelect().ColumnExpr("1").Scan(ctx, &one))
+	_, err = db.ExecContext(ctx, "SELECT * FROM missing")
+	assert.Error(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 3) {
+		assert.Equal(t, "db.SELECT", spans[0].Name())
+		assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+		assert.Contains(t, spans[0].Attributes(), semconv.DBSystemSqlite)
+		assert.Contains(t, spans[0].Attributes(), semconv.DBStatement("SELECT 1"))
+		assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+		assert.Equal(t, codes.Error, spans[1].Status().Code)
+	}
+}
@@ -0,0 +1,315 @@
+// This file contains the dispatcher of the webhooks. The deliveries are
+// written to the outbox in the transaction of the change they notify, the
+// dispatcher polls it, POSTs the due deliveries and retries the failed ones
+// with an exponential backoff.
+//
+// A delivery is sent at least once: when the server stops between the POST
+// and saving its outcome, it is sent again once its lease ends. The receivers
+// tell the duplicates apart by the X-Sawit-Delivery header.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/netip"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/metrics"
+	"github.com/SawitProRecruitment/UserService/models"
+	"github.com/SawitProRecruitment/UserService/repository"
+)
+
+// ErrForbiddenAddress is the failure of the deliveries to an address the
+// webhook policy does not allow, whatever the host of their URL resolved to
+var ErrForbiddenAddress = errors.New("forbidden address")
+
+type Dispatcher struct {
+	repo        repository.RepositoryInterface
+	client      *http.Client
+	policy      models.WebhookPolicy
+	logger      *slog.Logger
+	metrics     *metrics.Metrics
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	// lease is how long a claimed delivery is left to its attempt before
+	// another dispatcher may claim it again
+	lease time.Duration
+
+	// now is replaced by the tests to run the retries without waiting
+	now func() time.Time
+}
+
+type NewDispatcherOptions struct {
+	Repository repository.RepositoryInterface
+	// Client sends the deliveries, when nil one with Timeout which does not
+	// follow the redirects nor connect to the addresses Policy forbids
+	Client *http.Client
+	// Policy is what the webhooks may reach, checked again before every
+	// attempt since it may have changed since they were registered
+	Policy models.WebhookPolicy
+	// Timeout bounds an attempt, 10 seconds by default
+	Timeout time.Duration
+	// Logger is slog.Default() when nil
+	Logger *slog.Logger
+	// Metrics counts the attempts, they are not counted when nil
+	Metrics *metrics.Metrics
+	// PollInterval is how often the due deliveries are looked for, 1 second
+	// by default
+	PollInterval time.Duration
+	// BatchSize is the number of deliveries sent at the same time, 50 by
+	// default
+	BatchSize int
+	// MaxAttempts is the number of attempts before a delivery is abandoned,
+	// 10 by default
+	MaxAttempts int
+	// MinBackoff is the wait after the first failed attempt, doubled after
+	// every attempt up to MaxBackoff, with a jitter. 10 seconds and 1 hour by
+	// default.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+func NewDispatcher(opts NewDispatcherOptions) *Dispatcher {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{
+			Timeout:   opts.Timeout,
+			Transport: newTransport(opts.Policy),
+			// A redirect is answered as a failure, the webhook must be
+			// registered with its final URL
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+
+	if opts.BatchSize < 1 {
+		opts.BatchSize = 50
+	}
+
+	if opts.MaxAttempts < 1 {
+		opts.MaxAttempts = 10
+	}
+
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = 10 * time.Second
+	}
+
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = time.Hour
+	}
+
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = opts.MinBackoff
+	}
+
+	return &Dispatcher{
+		repo:        opts.Repository,
+		client:      client,
+		policy:      opts.Policy,
+		logger:      logger,
+		metrics:     opts.Metrics,
+		interval:    opts.PollInterval,
+		batchSize:   opts.BatchSize,
+		maxAttempts: opts.MaxAttempts,
+		minBackoff:  opts.MinBackoff,
+		maxBackoff:  opts.MaxBackoff,
+		lease:       opts.Timeout + time.Minute,
+		now:         time.Now,
+	}
+}
+
+// Run sends the due deliveries every poll interval until ctx is done. The
+// attempts in flight are not cancelled with ctx, Run returns once they end.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		// A full batch may leave more due deliveries behind
+		for ctx.Err() == nil {
+			sent, err := d.DeliverDue(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					d.logger.Error("reading the webhook deliveries", "error", err)
+				}
+				break
+			}
+
+			if sent < d.batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue sends a batch of the due deliveries and saves their outcome, it
+// answers how many were sent
+func (d *Dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	deliveries, err := d.repo.ClaimWebhookDeliveries(ctx, d.now(), d.lease, d.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for i := range *deliveries {
+		delivery := &(*deliveries)[i]
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.deliver(context.WithoutCancel(ctx), delivery)
+		}()
+	}
+	wg.Wait()
+
+	return len(*deliveries), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery *models.WebhookDelivery) {
+	logger := d.logger.With(
+		slog.String("webhook_id", delivery.Webhook.UUID),
+		slog.String("delivery_id", delivery.UUID),
+		slog.String("event_type", string(delivery.EventType)),
+		slog.Int("attempt", delivery.Attempts+1),
+	)
+
+	statusCode, err := d.send(ctx, delivery)
+	now := d.now()
+
+	outcome := "delivered"
+	if err == nil {
+		delivery.Succeed(statusCode, now)
+	} else {
+		var retryAt *time.Time
+		if delivery.Attempts+1 < d.maxAttempts {
+			next := now.Add(d.backoff(delivery.Attempts + 1))
+			retryAt = &next
+			outcome = "retried"
+		} else {
+			outcome = "abandoned"
+		}
+
+		delivery.Fail(statusCode, err.Error(), now, retryAt)
+		logger.WarnContext(ctx, "webhook delivery failed", "error", err, "outcome", outcome)
+	}
+	d.metrics.ObserveWebhookDelivery(outcome)
+
+	if err := d.repo.SaveWebhookDelivery(ctx, delivery); err != nil {
+		logger.ErrorContext(ctx, "saving the webhook delivery", "error", err)
+	}
+}
+
+// send POSTs the delivery, signed with the secret of its webhook. It answers
+// the status of the response, zero when there is none.
+func (d *Dispatcher) send(ctx context.Context, delivery *models.WebhookDelivery) (int, error) {
+	if delivery.Webhook == nil {
+		return 0, errors.New("the webhook of the delivery is missing")
+	}
+
+	if err := d.policy.CheckURL(delivery.Webhook.URL); err != nil {
+		return 0, err
+	}
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "sawit-webhooks/1")
+	req.Header.Set(EventHeader, string(delivery.EventType))
+	req.Header.Set(DeliveryHeader, delivery.UUID)
+	req.Header.Set(SignatureHeader, Sign(delivery.Webhook.Secret, d.now(), body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// The connection is reused once the body is read
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// newTransport connects to the addresses allowed by policy only. They are
+// checked once resolved, when dialing, so a host resolving to a public address
+// when registered and to a private one afterwards is refused too. The proxies
+// of the environment are not used, the addresses would be theirs.
+func newTransport(policy models.WebhookPolicy) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+
+			if !policy.AllowsAddress(addrPort.Addr()) {
+				return fmt.Errorf("%w %s", ErrForbiddenAddress, addrPort.Addr())
+			}
+
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return transport
+}
+
+// backoff is the wait after the failed attempt, a random duration between
+// half and all of the doubled backoff so the deliveries failing together are
+// not retried together
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	backoff := d.minBackoff
+	for i := 1; i < attempt && backoff < d.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > d.maxBackoff {
+		backoff = d.maxBackoff
+	}
+
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
@@ -0,0 +1,265 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/logging"
+	"github.com/SawitProRecruitment/UserService/models"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPolicy lets the webhooks reach the receivers of httptest
+var testPolicy = models.WebhookPolicy{AllowHTTP: true, AllowPrivateNetworks: true}
+
+// newWebhook registers url for the tree.added events of the organisation and
+// queues one of them
+func newWebhook(t *testing.T, repo repository.RepositoryInterface, url string) *models.Webhook {
+	ctx := context.Background()
+
+	webhook, err := models.NewWebhook(testPolicy, "estate-co-a", nil, url, models.EventTypes{models.EventTreeAdded})
+	require.NoError(t, err)
+	require.NoError(t, repo.SaveWebhook(ctx, webhook))
+
+	require.NoError(t, repo.SaveEvents(ctx, []models.Event{{
+		UUID:           "event",
+		Type:           models.EventTreeAdded,
+		OrganisationID: "estate-co-a",
+		EstateUUID:     "estate",
+		Payload:        `{"id":"event","type":"tree.added"}`,
+	}}))
+
+	return webhook
+}
+
+func lastDelivery(t *testing.T, repo repository.RepositoryInterface, webhook *models.Webhook) models.WebhookDelivery {
+	deliveries, err := repo.GetWebhookDeliveries(context.Background(), webhook.ID, 1)
+	require.NoError(t, err)
+	require.Len(t, *deliveries, 1)
+
+	return (*deliveries)[0]
+}
+
+func TestDispatcher_Deliver(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+
+	received := make(chan *http.Request, 1)
+	var body []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		received <- r
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	webhook := newWebhook(t, repo, receiver.URL+"/hooks")
+	dispatcher := NewDispatcher(NewDispatcherOptions{Repository: repo, Logger: logging.Discard(), Policy: testPolicy})
+
+	sent, err := dispatcher.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+
+	req := <-received
+	assert.Equal(t, "/hooks", req.URL.Path)
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, "tree.added", req.Header.Get(EventHeader))
+	assert.Equal(t, `{"id":"event","type":"tree.added"}`, string(body))
+	assert.NoError(t, Verify(webhook.Secret, req.Header.Get(SignatureHeader), body, time.Now(), time.Minute))
+
+	delivery := lastDelivery(t, repo, webhook)
+	assert.Equal(t, delivery.UUID, req.Header.Get(DeliveryHeader))
+	assert.Equal(t, models.DeliveryDelivered, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusNoContent, delivery.LastStatusCode)
+	assert.NotNil(t, delivery.DeliveredAt)
+
+	// Delivered once
+	sent, err = dispatcher.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+}
+
+func TestDispatcher_Retry(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	webhook := newWebhook(t, repo, receiver.URL)
+	dispatcher := NewDispatcher(NewDispatcherOptions{
+		Repository: repo,
+		Policy:     testPolicy,
+		Logger:     logging.Discard(),
+		MinBackoff: time.Minute,
+		MaxBackoff: time.Hour,
+	})
+	now := time.Now()
+	dispatcher.now = func() time.Time { return now }
+
+	_, err := dispatcher.DeliverDue(ctx)
+	require.NoError(t, err)
+
+	delivery := lastDelivery(t, repo, webhook)
+	assert.Equal(t, models.DeliveryPending, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, delivery.LastStatusCode)
+	assert.Equal(t, "unexpected status 503 Service Unavailable", delivery.LastError)
+	assert.WithinRange(t, delivery.NextAttemptAt, now.Add(30*time.Second), now.Add(time.Minute))
+
+	// Not due yet
+	sent, err := dispatcher.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	// The backoff doubles
+	now = delivery.NextAttemptAt
+	_, err = dispatcher.DeliverDue(ctx)
+	require.NoError(t, err)
+	delivery = lastDelivery(t, repo, webhook)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.WithinRange(t, delivery.NextAttemptAt, now.Add(time.Minute), now.Add(2*time.Minute))
+
+	now = delivery.NextAttemptAt
+	_, err = dispatcher.DeliverDue(ctx)
+	require.NoError(t, err)
+	delivery = lastDelivery(t, repo, webhook)
+	assert.Equal(t, models.DeliveryDelivered, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Empty(t, delivery.LastError)
+}
+
+func TestDispatcher_Abandon(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://example.com", http.StatusFound)
+	}))
+	defer redirect.Close()
+
+	webhook := newWebhook(t, repo, redirect.URL)
+	dispatcher := NewDispatcher(NewDispatcherOptions{
+		Repository:  repo,
+		Policy:      testPolicy,
+		Logger:      logging.Discard(),
+		MaxAttempts: 1,
+	})
+
+	_, err := dispatcher.DeliverDue(ctx)
+	require.NoError(t, err)
+
+	// The redirect is not followed
+	delivery := lastDelivery(t, repo, webhook)
+	assert.Equal(t, models.DeliveryAbandoned, delivery.Status)
+	assert.Equal(t, http.StatusFound, delivery.LastStatusCode)
+
+	// Nobody listening, nor kept alive
+	redirect.Close()
+	dispatcher.client.CloseIdleConnections()
+	unreachable := newWebhook(t, repo, redirect.URL)
+	_, err = dispatcher.DeliverDue(ctx)
+	require.NoError(t, err)
+
+	delivery = lastDelivery(t, repo, unreachable)
+	assert.Equal(t, models.DeliveryAbandoned, delivery.Status)
+	assert.Zero(t, delivery.LastStatusCode)
+	assert.Contains(t, delivery.LastError, "connection refused")
+}
+
+func TestDispatcher_ForbiddenAddress(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer receiver.Close()
+
+	// Registered before the private networks were forbidden
+	webhook := newWebhook(t, repo, receiver.URL)
+	dispatcher := NewDispatcher(NewDispatcherOptions{
+		Repository:  repo,
+		Logger:      logging.Discard(),
+		MaxAttempts: 1,
+		Policy:      models.WebhookPolicy{AllowHTTP: true},
+	})
+
+	_, err := dispatcher.DeliverDue(ctx)
+	require.NoError(t, err)
+
+	delivery := lastDelivery(t, repo, webhook)
+	assert.Equal(t, models.DeliveryAbandoned, delivery.Status)
+	assert.Contains(t, delivery.LastError, "must not be a loopback, private or link-local address")
+	assert.Zero(t, calls.Load())
+}
+
+func TestNewTransport_ForbiddenAddress(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer receiver.Close()
+
+	// The address is checked once resolved, whatever the host of the URL
+	_, port, err := net.SplitHostPort(receiver.Listener.Addr().String())
+	require.NoError(t, err)
+	url := "http://localhost:" + port
+
+	client := &http.Client{Transport: newTransport(models.WebhookPolicy{AllowHTTP: true})}
+	_, err = client.Get(url)
+	assert.ErrorIs(t, err, ErrForbiddenAddress)
+
+	client = &http.Client{Transport: newTransport(testPolicy)}
+	resp, err := client.Get(url)
+	require.NoError(t, err)
+	resp.Body.Close()
+}
+
+func TestDispatcher_Run(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+
+	received := make(chan struct{}, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+	}))
+	defer receiver.Close()
+
+	newWebhook(t, repo, receiver.URL)
+	dispatcher := NewDispatcher(NewDispatcherOptions{
+		Repository:   repo,
+		Policy:       testPolicy,
+		Logger:       logging.Discard(),
+		PollInterval: 10 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		dispatcher.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the delivery was not sent")
+	}
+
+	cancel()
+	<-done
+}
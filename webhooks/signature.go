@@ -0,0 +1,75 @@
+// This file contains the signature of the webhook deliveries, an HMAC-SHA256
+// of the timestamp and the body keyed with the secret of the webhook, so the
+// receivers can check a delivery comes from the API and is not replayed.
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The headers of a delivery
+const (
+	EventHeader     = "X-Sawit-Event"
+	DeliveryHeader  = "X-Sawit-Delivery"
+	SignatureHeader = "X-Sawit-Signature"
+)
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Sign is the X-Sawit-Signature header of body sent at timestamp:
+// t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>">
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + unix + ",v1=" + hex.EncodeToString(mac(secret, unix, body))
+}
+
+// Verify checks the X-Sawit-Signature header of body, which must have been
+// signed less than tolerance before now
+func Verify(secret string, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var unix string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			unix = value
+		case "v1":
+			if signature, err := hex.DecodeString(value); err == nil {
+				signatures = append(signatures, signature)
+			}
+		}
+	}
+
+	seconds, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	age := now.Sub(time.Unix(seconds, 0))
+	if age > tolerance || age < -tolerance {
+		return ErrInvalidSignature
+	}
+
+	expected := mac(secret, unix, body)
+	for _, signature := range signatures {
+		if hmac.Equal(signature, expected) {
+			return nil
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+func mac(secret string, unix string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(unix))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
@@ -0,0 +1,31 @@
+package webhooks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"type":"tree.added"}`)
+	sentAt := time.Unix(1700000000, 0)
+
+	header := Sign("secret", sentAt, body)
+	assert.Regexp(t, `^t=1700000000,v1=[0-9a-f]{64}$`, header)
+
+	assert.NoError(t, Verify("secret", header, body, sentAt.Add(time.Minute), 5*time.Minute))
+
+	// Tampered, signed with another secret or replayed later
+	assert.ErrorIs(t, Verify("secret", header, []byte(`{"type":"tree.retired"}`), sentAt, 5*time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("other", header, body, sentAt, 5*time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("secret", header, body, sentAt.Add(time.Hour), 5*time.Minute), ErrInvalidSignature)
+
+	// Malformed
+	for _, invalid := range []string{"", "v1=abc", "t=abc,v1=abc", "t=1700000000"} {
+		assert.ErrorIs(t, Verify("secret", invalid, body, sentAt, 5*time.Minute), ErrInvalidSignature, invalid)
+	}
+
+	// Any of the signatures may match, for the secrets being rotated
+	assert.NoError(t, Verify("secret", header+",v1=00", body, sentAt, 5*time.Minute))
+}